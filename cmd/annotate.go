@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var (
+	FlagNotesRef  string
+	FlagNotesPush string
+	FlagDryRun    bool
+	FlagRemove    bool
+)
+
+var annotateCmd = &cobra.Command{
+	Use:               "annotate",
+	Short:             locale.T("CommandAnnotateSummary"),
+	Long:              locale.T("CommandAnnotateDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := applyWindow()
+		if err != nil {
+			fail(err, cmd)
+		}
+		applyAuthorMatching()
+		err = applyTeam([]string{FlagTarget})
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+		_, err = diagnosticLevels()
+		if err != nil {
+			fail(err, cmd)
+		}
+		if reader.ReadGitDir(FlagTarget) == "" {
+			fail(locale.Tf("CommandWipNotARepository", FlagTarget), cmd)
+		}
+
+		err = annotate(notesRef(FlagNotesRef))
+		if err != nil {
+			fail(err, cmd)
+		}
+	},
+}
+
+// notesRef returns the full ref of the notes, like git notes --ref does: "git-spend" is refs/notes/git-spend
+func notesRef(ref string) string {
+	if strings.HasPrefix(ref, "refs/") {
+		return ref
+	}
+
+	return "refs/notes/" + ref
+}
+
+// annotate writes (or removes) the notes of the commits of the target that spend time, under the ref
+func annotate(ref string) error {
+	schedule, err := readSchedule(FlagTarget, gitime.CurrentSchedule())
+	if err != nil {
+		return err
+	}
+	gitime.UseSchedule(schedule)
+	corrections, warnings, err := readCorrections(FlagTarget)
+	if err != nil {
+		return err
+	}
+	err = reportDiagnostics(warnings)
+	if err != nil {
+		return err
+	}
+	gitime.CommentChar = reader.ReadCommentChar(FlagTarget)
+	commits := reader.ReadGitLogCommits(FlagAuthors, FlagNoMerges, FlagSince, FlagUntil, FlagTarget)
+	commits = excludeAuthors(commits, map[string]int{})
+	chunks, err := chunkCommits(commits)
+	if err != nil {
+		return err
+	}
+
+	if !FlagDryRun {
+		unlock, err := reader.LockNotes(FlagTarget)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	existing, err := reader.ReadNotes(ref, FlagTarget)
+	if err != nil {
+		return err
+	}
+
+	outcomes := make(map[int]int)
+	for _, chunk := range chunks {
+		for _, commit := range chunk {
+			outcome, err := annotateCommit(ref, commit, existing, corrections)
+			if err != nil {
+				return err
+			}
+			outcomes[outcome]++
+		}
+		if FlagChunk > 0 {
+			if err := pushNotes(ref); err != nil {
+				return err
+			}
+		}
+		printCursor(chunk)
+	}
+
+	changed, unchanged := outcomes[annotateChanged], outcomes[annotateUnchanged]
+	switch {
+	case FlagRemove && FlagDryRun:
+		printInfo(locale.Tf("CommandAnnotateWouldRemoveCount", changed, ref))
+	case FlagRemove:
+		printInfo(locale.Tf("CommandAnnotateRemovedCount", changed, ref))
+	case FlagDryRun:
+		printInfo(locale.Tf("CommandAnnotateWouldWriteCount", changed, ref, unchanged))
+	default:
+		printInfo(locale.Tf("CommandAnnotateWrittenCount", changed, ref, unchanged))
+	}
+	if FlagChunk == 0 {
+		return pushNotes(ref)
+	}
+
+	return nil
+}
+
+// Outcomes of annotating a commit
+const (
+	annotateSkipped = iota
+	annotateChanged
+	annotateUnchanged
+)
+
+// annotateCommit writes (or removes) the note of the commit, and tells the outcome
+func annotateCommit(ref string, commit *gitime.Commit, existing map[string]string, corrections []*gitime.Correction) (int, error) {
+	if FlagRemove {
+		if _, found := existing[commit.Hash]; !found {
+			return annotateSkipped, nil
+		}
+		if FlagDryRun {
+			fmt.Println(locale.Tf("CommandAnnotateWouldRemove", commit.ShortHash()))
+			return annotateChanged, nil
+		}
+		if err := reader.RemoveNote(ref, commit.Hash, FlagTarget); err != nil {
+			return annotateSkipped, err
+		}
+		fmt.Println(locale.Tf("CommandAnnotateRemoved", commit.ShortHash()))
+		return annotateChanged, nil
+	}
+
+	note := gitime.NewNote(commit, corrections)
+	if note == nil {
+		return annotateSkipped, nil
+	}
+	content := note.String()
+	if existing[commit.Hash] == content {
+		return annotateUnchanged, nil
+	}
+	spent := (&gitime.TimeSpent{Minutes: float64(note.Minutes)}).Normalize().String()
+	if FlagDryRun {
+		fmt.Println(locale.Tf("CommandAnnotateWouldWrite", commit.ShortHash(), spent))
+		return annotateChanged, nil
+	}
+	if err := reader.WriteNote(ref, commit.Hash, content, FlagTarget); err != nil {
+		return annotateSkipped, err
+	}
+	fmt.Println(locale.Tf("CommandAnnotateWritten", commit.ShortHash(), spent))
+
+	return annotateChanged, nil
+}
+
+// pushNotes pushes the notes of the ref to the remote of --push, if any
+func pushNotes(ref string) error {
+	if FlagNotesPush == "" || FlagDryRun {
+		return nil
+	}
+	err := reader.PushNotes(ref, FlagNotesPush, FlagTarget)
+	if err != nil {
+		return err
+	}
+	printInfo(locale.Tf("CommandAnnotatePushed", ref, FlagNotesPush))
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+	annotateCmd.Flags().SortFlags = false
+	annotateCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	annotateCmd.Flags().StringVar(
+		&FlagNotesRef,
+		"notes-ref",
+		gitime.DefaultNotesRef,
+		locale.T("CommandAnnotateFlagNotesRefHelp"),
+	)
+	annotateCmd.Flags().BoolVar(
+		&FlagDryRun,
+		"dry-run",
+		false,
+		locale.T("CommandAnnotateFlagDryRunHelp"),
+	)
+	annotateCmd.Flags().BoolVar(
+		&FlagRemove,
+		"remove",
+		false,
+		locale.T("CommandAnnotateFlagRemoveHelp"),
+	)
+	annotateCmd.Flags().StringVar(
+		&FlagNotesPush,
+		"push",
+		"",
+		locale.T("CommandAnnotateFlagPushHelp"),
+	)
+	addFilterFlags(annotateCmd)
+	addChunkFlags(annotateCmd)
+	addDiagnosticFlags(annotateCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime/badge"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path"
+	"text/tabwriter"
+)
+
+// embeddedAssets are the filesystems embedded in the binary, by the package embedding them
+var embeddedAssets = []struct {
+	Package string
+	FS      fs.FS
+}{
+	{"locale", locale.Assets()},
+	{"gitime/badge", badge.Assets()},
+}
+
+var assetsCmd = &cobra.Command{
+	Use:               "assets",
+	Short:             locale.T("CommandAssetsSummary"),
+	Long:              locale.T("CommandAssetsDescription"),
+	Hidden:            true,
+	DisableAutoGenTag: true,
+}
+
+var assetsListCmd = &cobra.Command{
+	Use:               "list",
+	Short:             locale.T("CommandAssetsListSummary"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, assets := range embeddedAssets {
+			err := fs.WalkDir(assets.FS, ".", func(name string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return err
+				}
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintf(w, "%s\t%d\n", path.Join(assets.Package, name), info.Size())
+				return err
+			})
+			if err != nil {
+				fail(err, cmd)
+			}
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(assetsCmd)
+	assetsCmd.AddCommand(assetsListCmd)
+}
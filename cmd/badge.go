@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/badge"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/gitime/statefile"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	BadgeFormatSvg  = "svg"
+	BadgeFormatJson = "json"
+)
+
+var (
+	FlagBadgeWindow          string
+	FlagBadgeOut             string
+	FlagBadgeFormat          string
+	FlagBadgeLabel           string
+	FlagBadgeYellowThreshold float64
+	FlagBadgeGreenThreshold  float64
+)
+
+var windowRegex = regexp.MustCompile("^\\s*(?P<amount>[0-9]+)\\s*(?P<unit>days?|weeks?|months?|years?)\\s*$")
+
+var badgeCmd = &cobra.Command{
+	Use:               "badge",
+	Short:             locale.T("CommandBadgeSummary"),
+	Long:              locale.T("CommandBadgeDescription"),
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		since, err := parseWindow(FlagBadgeWindow, time.Now())
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		// The commits are counted like sum counts them, so that both agree on what logs time
+		gitime.CommentChar = reader.ReadCommentChar(FlagTarget)
+		commits, skipped, err := reader.ReadGitLogCommitsCounting(
+			[]string{},
+			FlagNoMerges,
+			since.Format(time.RFC3339),
+			"",
+			FlagTarget,
+		)
+		if err != nil {
+			fail(err, cmd)
+		}
+		collection := (&gitime.Collector{}).Collect(commits)
+		collection.Counts.Filtered(skipped)
+
+		b := badge.ForCoverage(
+			FlagBadgeLabel,
+			collection.Counts.Coverage(),
+			FlagBadgeYellowThreshold,
+			FlagBadgeGreenThreshold,
+		)
+
+		var out []byte
+		switch FlagBadgeFormat {
+		case BadgeFormatSvg:
+			out, err = b.SVG()
+		case BadgeFormatJson:
+			out, err = b.JSON()
+			out = append(out, '\n')
+		default:
+			err = fmt.Errorf(locale.Tf("CommandBadgeFailureFormat", FlagBadgeFormat))
+		}
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		if FlagBadgeOut == "" {
+			fmt.Print(string(out))
+			return
+		}
+		err = statefile.WriteAtomic(FlagBadgeOut, out, 0644)
+		if err != nil {
+			fail(err, cmd)
+		}
+	},
+}
+
+// parseWindow reads windows like "90 days" or "2 weeks", and returns when they started
+func parseWindow(window string, now time.Time) (time.Time, error) {
+	matches := windowRegex.FindStringSubmatch(window)
+	if matches == nil {
+		return now, fmt.Errorf(locale.Tf("CommandBadgeFailureWindow", window))
+	}
+	amount, _ := strconv.Atoi(matches[windowRegex.SubexpIndex("amount")])
+
+	switch matches[windowRegex.SubexpIndex("unit")][0] {
+	case 'd':
+		return now.AddDate(0, 0, -amount), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*amount), nil
+	case 'm':
+		return now.AddDate(0, -amount, 0), nil
+	default:
+		return now.AddDate(-amount, 0, 0), nil
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(badgeCmd)
+	badgeCmd.Flags().SortFlags = false
+	badgeCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	badgeCmd.Flags().StringVar(
+		&FlagBadgeWindow,
+		"window",
+		"90 days",
+		locale.T("CommandBadgeFlagWindowHelp"),
+	)
+	badgeCmd.Flags().BoolVar(
+		&FlagNoMerges,
+		"no-merges",
+		false,
+		locale.T("CommandSumFlagNoMergesHelp"),
+	)
+	badgeCmd.Flags().StringVar(
+		&FlagBadgeFormat,
+		"format",
+		BadgeFormatSvg,
+		locale.T("CommandBadgeFlagFormatHelp"),
+	)
+	badgeCmd.Flags().StringVar(
+		&FlagBadgeOut,
+		"out",
+		"",
+		locale.T("CommandBadgeFlagOutHelp"),
+	)
+	badgeCmd.Flags().StringVar(
+		&FlagBadgeLabel,
+		"label",
+		"time-logged",
+		locale.T("CommandBadgeFlagLabelHelp"),
+	)
+	badgeCmd.Flags().Float64Var(
+		&FlagBadgeYellowThreshold,
+		"yellow-threshold",
+		50.0,
+		locale.T("CommandBadgeFlagYellowThresholdHelp"),
+	)
+	badgeCmd.Flags().Float64Var(
+		&FlagBadgeGreenThreshold,
+		"green-threshold",
+		80.0,
+		locale.T("CommandBadgeFlagGreenThresholdHelp"),
+	)
+}
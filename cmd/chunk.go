@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagChunk int
+	FlagAfter string
+)
+
+// addChunkFlags registers the flags of the commands that may process a long range in chunks, and resume it
+func addChunkFlags(command *cobra.Command) {
+	command.Flags().IntVar(
+		&FlagChunk,
+		"chunk",
+		0,
+		locale.T("CommandChunkFlagChunkHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagAfter,
+		"after",
+		"",
+		locale.T("CommandChunkFlagAfterHelp"),
+	)
+}
+
+// chunkCommits splits the commits (newest first, like read from git log) in chunks, oldest first,
+// after the commit of --after
+func chunkCommits(commits []*gitime.Commit) ([][]*gitime.Commit, error) {
+	if FlagChunk < 0 {
+		return nil, fmt.Errorf(locale.Tf("CommandChunkFailureSize", FlagChunk))
+	}
+
+	return gitime.ChunkCommits(commits, FlagChunk, FlagAfter)
+}
+
+// printCursor tells how to resume after the chunk, once its output is flushed
+func printCursor(chunk []*gitime.Commit) {
+	if FlagChunk == 0 || len(chunk) == 0 {
+		return
+	}
+	printInfo(locale.Tf("CommandChunkCursor", chunk[len(chunk)-1].Hash))
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/jsonrpc"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var (
+	FlagJsonRpc bool
+)
+
+type jsonParsedMessage struct {
+	Directives []*jsonShowDirective `json:"directives"`
+	Total      *jsonTimeSpent       `json:"total"`
+}
+
+type jsonLintedMessage struct {
+	Diagnostics []*gitime.Warning `json:"diagnostics"`
+}
+
+type rpcMessageParams struct {
+	Message *string `json:"message"`
+}
+
+type rpcSumRangeParams struct {
+	Since   string   `json:"since"`
+	Until   string   `json:"until"`
+	Authors []string `json:"authors"`
+}
+
+var daemonCmd = &cobra.Command{
+	Use:               "daemon",
+	Short:             locale.T("CommandDaemonSummary"),
+	Long:              locale.T("CommandDaemonDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !FlagJsonRpc {
+			fail(locale.T("CommandDaemonFailureProtocol"), cmd)
+		}
+		d, err := newDaemon(FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		err = d.server().Serve(os.Stdin, os.Stdout)
+		if err != nil {
+			printWarning(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+// daemon keeps what the requests of an editor have in common between calls :
+// the config of the repository, and the commits already read, as long as HEAD does not move.
+type daemon struct {
+	target    string
+	linter    *gitime.Linter
+	collector *gitime.Collector
+	levels    *gitime.DiagnosticLevels
+	// warnings are the ones of reading the config, like corrections of unknown commits
+	warnings []*gitime.Warning
+	// head is the commit HEAD pointed to when the commits were read
+	head    string
+	commits map[string]*daemonCommits
+}
+
+type daemonCommits struct {
+	commits []*gitime.Commit
+	skipped map[string]int
+}
+
+// newDaemon reads the config of the repository of the target once, for all the requests to come
+func newDaemon(target string) (*daemon, error) {
+	levels, err := diagnosticLevels()
+	if err != nil {
+		return nil, err
+	}
+	err = applyGrammar()
+	if err != nil {
+		return nil, err
+	}
+	linter, err := newLinter(true)
+	if err != nil {
+		return nil, err
+	}
+	collector, err := newCollector()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := readSchedule(target, gitime.CurrentSchedule())
+	if err != nil {
+		return nil, err
+	}
+	gitime.UseSchedule(schedule)
+	corrections, warnings, err := readCorrections(target)
+	if err != nil {
+		return nil, err
+	}
+	collector.Corrections = corrections
+	collector.Rates, err = readRates(target)
+	if err != nil {
+		return nil, err
+	}
+	collector.Repository = targetName(target)
+	collector.Changes = reader.NewChangesReader(target).Read
+	gitime.CommentChar = reader.ReadCommentChar(target)
+	FlagTargets = []string{target}
+
+	return &daemon{
+		target:    target,
+		linter:    linter,
+		collector: collector,
+		levels:    levels,
+		warnings:  warnings,
+		commits:   make(map[string]*daemonCommits),
+	}, nil
+}
+
+func (d *daemon) server() *jsonrpc.Server {
+	return &jsonrpc.Server{Methods: map[string]jsonrpc.Handler{
+		"parseMessage": d.parseMessage,
+		"lintMessage":  d.lintMessage,
+		"sumRange":     d.sumRange,
+	}}
+}
+
+// parseMessage answers the directives of a message, with their spans, like show --explain --format json
+func (d *daemon) parseMessage(params json.RawMessage) (any, error) {
+	message, err := decodeMessage(params)
+	if err != nil {
+		return nil, err
+	}
+	directives := gitime.CollectDirectivesWithSpans(message)
+	total := &gitime.TimeSpent{}
+	for _, directive := range directives {
+		total.Add(directive.TimeSpent)
+	}
+
+	return &jsonParsedMessage{
+		Directives: newJsonShowDirectives(directives),
+		Total:      newJsonTimeSpent(total.Normalize()),
+	}, nil
+}
+
+// lintMessage answers the violations of the policies by a message, like lint-message
+func (d *daemon) lintMessage(params json.RawMessage) (any, error) {
+	message, err := decodeMessage(params)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := violationWarnings(d.linter.LintMessage(message))
+
+	return &jsonLintedMessage{Diagnostics: d.levels.Apply(diagnostics)}, nil
+}
+
+// sumRange answers the time spent in a range of commits, like sum --format json
+func (d *daemon) sumRange(params json.RawMessage) (any, error) {
+	p := &rpcSumRangeParams{}
+	err := jsonrpc.DecodeParams(params, p)
+	if err != nil {
+		return nil, err
+	}
+	if reader.ReadGitDir(d.target) == "" {
+		return nil, fmt.Errorf(locale.Tf("CommandDaemonNotARepository", d.target))
+	}
+	// Bounds are checked first, to answer the ones git cannot read as invalid params
+	if p.Since != "" && p.Until != "" && reader.IsDate(p.Since) != reader.IsDate(p.Until) {
+		return nil, jsonrpc.InvalidParams(locale.T("CommandDaemonFailureMixedBounds"))
+	}
+	for _, bound := range []string{p.Since, p.Until} {
+		if bound == "" || reader.IsDate(bound) {
+			continue
+		}
+		if _, err := reader.ReadGitCommit(bound, d.target); err != nil {
+			return nil, err
+		}
+	}
+
+	FlagSince, FlagUntil, FlagAuthors = p.Since, p.Until, p.Authors
+	read, err := d.readCommits(p.Since, p.Until, p.Authors)
+	if err != nil {
+		return nil, err
+	}
+	collection := d.collector.Collect(read.commits)
+	collection.Counts.Filtered(read.skipped)
+	collection.Warnings = append(append([]*gitime.Warning{}, d.warnings...), collection.Warnings...)
+	applyDiagnosticLevels(collection, d.levels)
+
+	return newJsonSum(collection, nil), nil
+}
+
+// readCommits reads the commits of the range, or returns the ones already read if HEAD did not move since
+func (d *daemon) readCommits(since string, until string, authors []string) (*daemonCommits, error) {
+	head := reader.ReadHead(d.target)
+	if head != d.head {
+		d.head = head
+		d.commits = make(map[string]*daemonCommits)
+	}
+	key := strings.Join([]string{since, until, strings.Join(authors, "\n")}, "\x00")
+	if read, found := d.commits[key]; found {
+		return read, nil
+	}
+
+	commits, skipped, err := reader.ReadGitLogCommitsCounting(authors, false, since, until, d.target)
+	if err != nil {
+		return nil, err
+	}
+	read := &daemonCommits{commits: commits, skipped: skipped}
+	d.commits[key] = read
+
+	return read, nil
+}
+
+// decodeMessage returns the message of the params of a request, which is required
+func decodeMessage(params json.RawMessage) (string, error) {
+	p := &rpcMessageParams{}
+	err := jsonrpc.DecodeParams(params, p)
+	if err != nil {
+		return "", err
+	}
+	if p.Message == nil {
+		return "", jsonrpc.InvalidParams(locale.Tf("CommandDaemonParamMissing", "message"))
+	}
+
+	return *p.Message, nil
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().SortFlags = false
+	daemonCmd.Flags().BoolVar(
+		&FlagJsonRpc,
+		"json-rpc",
+		false,
+		locale.T("CommandDaemonFlagJsonRpcHelp"),
+	)
+	daemonCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	addPolicyFlags(daemonCmd)
+	addDiagnosticFlags(daemonCmd)
+}
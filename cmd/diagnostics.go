@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+)
+
+var (
+	FlagDiagnosticsFormat string
+	FlagDeny              []string
+	FlagAllow             []string
+)
+
+type jsonDiagnosticCode struct {
+	Code        string `json:"code"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+var diagnosticsCmd = &cobra.Command{
+	Use:               "diagnostics",
+	Short:             locale.T("CommandDiagnosticsSummary"),
+	Long:              locale.T("CommandDiagnosticsDescription"),
+	DisableAutoGenTag: true,
+}
+
+var diagnosticsListCmd = &cobra.Command{
+	Use:               "list",
+	Short:             locale.T("CommandDiagnosticsListSummary"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		switch FlagDiagnosticsFormat {
+		case FormatText:
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, code := range gitime.DiagnosticCodes {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", code.Code, code.Severity, locale.T(code.Description))
+			}
+			err = w.Flush()
+		case FormatJson:
+			codes := make([]*jsonDiagnosticCode, 0, len(gitime.DiagnosticCodes))
+			for _, code := range gitime.DiagnosticCodes {
+				codes = append(codes, &jsonDiagnosticCode{
+					Code:        code.Code,
+					Severity:    code.Severity,
+					Description: locale.T(code.Description),
+				})
+			}
+			err = printJson(codes)
+		default:
+			err = fmt.Errorf(locale.Tf("FormatUnsupported", FlagDiagnosticsFormat))
+		}
+		if err != nil {
+			fail(err, cmd)
+		}
+	},
+}
+
+// diagnosticLevels returns the levels of the diagnostic codes set by --deny and --allow, once validated
+func diagnosticLevels() (*gitime.DiagnosticLevels, error) {
+	levels := &gitime.DiagnosticLevels{Deny: FlagDeny, Allow: FlagAllow}
+
+	return levels, levels.Validate()
+}
+
+// reportDiagnostics prints the warnings that are not allowed, and fails when some of them are denied
+func reportDiagnostics(warnings []*gitime.Warning) error {
+	levels, err := diagnosticLevels()
+	if err != nil {
+		return err
+	}
+	warnings = levels.Apply(warnings)
+	printWarnings(warnings)
+	if gitime.HasErrors(warnings) {
+		return fmt.Errorf(locale.T("DiagnosticDenied"))
+	}
+
+	return nil
+}
+
+// addDiagnosticFlags adds --deny and --allow to a command reporting diagnostics
+func addDiagnosticFlags(command *cobra.Command) {
+	command.Flags().StringSliceVar(
+		&FlagDeny,
+		"deny",
+		[]string{},
+		locale.T("CommandSumFlagDenyHelp"),
+	)
+	command.Flags().StringSliceVar(
+		&FlagAllow,
+		"allow",
+		[]string{},
+		locale.T("CommandSumFlagAllowHelp"),
+	)
+}
+
+func init() {
+	rootCmd.AddCommand(diagnosticsCmd)
+	diagnosticsCmd.AddCommand(diagnosticsListCmd)
+
+	diagnosticsListCmd.Flags().StringVar(
+		&FlagDiagnosticsFormat,
+		"format",
+		FormatText,
+		locale.T("CommandDiagnosticsFlagFormatHelp"),
+	)
+}
@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Exit codes of the doctor command, after its worst result, so that onboarding scripts may gate on them
+const (
+	ExitCodeDoctorWarn = 1
+	ExitCodeDoctorFail = 2
+)
+
+// Results of the checks of the doctor command, from best to worst
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// MinimumGitVersion is the oldest version of git that supports everything git-spend asks of it
+const MinimumGitVersion = "2.31"
+
+// doctorScanDepth is how many of the most recent commits the doctor command scans for near misses
+const doctorScanDepth = 200
+
+// doctorScanShown is how many of the suspicious lines found by the scan are listed
+const doctorScanShown = 5
+
+// userConfigKeys are the keys recognized in the user config file, besides the schedule
+var userConfigKeys = []string{
+	"ambiguous_m",
+	"case_sensitive_emails",
+	"fold_accents",
+	"forge",
+	"issue_regex",
+	"log_runs",
+	"max_directive",
+	"min_granularity",
+	"overflow_threshold",
+	"policy",
+	"range_policy",
+	"timezone",
+	"week_start",
+	"word_numbers",
+	"working_days",
+}
+
+// repositoryConfigKeys are the keys recognized in the config file of a repository, besides the schedule
+var repositoryConfigKeys = []string{"corrections", "rates"}
+
+// doctorCheck is the result of a check of the doctor command
+type doctorCheck struct {
+	Name    string
+	Status  string
+	Message string
+	// Remedy is a one-line hint of what to do when the check does not pass
+	Remedy string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:               "doctor",
+	Short:             locale.T("CommandDoctorSummary"),
+	Long:              locale.T("CommandDoctorDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		checks := []*doctorCheck{checkGit()}
+		hasGit := reader.LookGit() == nil
+		if hasGit {
+			checks = append(checks, checkRepository(FlagTarget))
+		}
+		checks = append(checks, checkMailmap(FlagTarget))
+		checks = append(checks, checkUserConfig())
+		checks = append(checks, checkRepositoryConfig(FlagTarget))
+		checks = append(checks, checkCorrections(FlagTarget))
+		if hasGit {
+			checks = append(checks, checkHook(FlagTarget, "commit-msg"))
+		}
+		checks = append(checks, checkCloneCache())
+		checks = append(checks, checkRunLog(FlagTarget))
+		if hasGit {
+			checks = append(checks, checkRecentCommits(FlagTarget))
+		}
+
+		worst := doctorPass
+		for _, check := range checks {
+			fmt.Println(locale.Tf("CommandDoctorCheck", check.Status, check.Name, check.Message))
+			if check.Status != doctorPass && check.Remedy != "" {
+				fmt.Println(locale.Tf("CommandDoctorRemedy", check.Remedy))
+			}
+			if check.Status == doctorFail || (check.Status == doctorWarn && worst == doctorPass) {
+				worst = check.Status
+			}
+		}
+
+		switch worst {
+		case doctorWarn:
+			os.Exit(ExitCodeDoctorWarn)
+		case doctorFail:
+			os.Exit(ExitCodeDoctorFail)
+		}
+	},
+}
+
+// checkGit checks that git is in the PATH, and recent enough
+func checkGit() *doctorCheck {
+	check := &doctorCheck{Name: "git", Status: doctorPass}
+	if err := reader.LookGit(); err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Remedy = locale.T("CommandDoctorGitMissingRemedy")
+		return check
+	}
+	version, err := reader.ReadGitVersion()
+	if err != nil {
+		check.Status = doctorWarn
+		check.Message = err.Error()
+		check.Remedy = locale.Tf("CommandDoctorGitOldRemedy", MinimumGitVersion)
+		return check
+	}
+	check.Message = locale.Tf("CommandDoctorGitVersion", version)
+	if !reader.IsGitVersionAtLeast(version, MinimumGitVersion) {
+		check.Status = doctorWarn
+		check.Message = locale.Tf("CommandDoctorGitOld", version, MinimumGitVersion)
+		check.Remedy = locale.Tf("CommandDoctorGitOldRemedy", MinimumGitVersion)
+	}
+
+	return check
+}
+
+// checkRepository checks that the target is in a repository holding its whole history
+func checkRepository(target string) *doctorCheck {
+	check := &doctorCheck{Name: "repository", Status: doctorPass}
+	if reader.ReadGitDir(target) == "" {
+		check.Status = doctorFail
+		check.Message = locale.Tf("CommandDoctorRepositoryMissing", target)
+		check.Remedy = locale.T("CommandDoctorRepositoryMissingRemedy")
+		return check
+	}
+	toplevel := reader.ReadToplevel(target)
+	switch {
+	case reader.IsShallow(target):
+		check.Status = doctorWarn
+		check.Message = locale.Tf("CommandDoctorRepositoryShallow", toplevel)
+		check.Remedy = locale.T("CommandDoctorRepositoryShallowRemedy")
+	case reader.IsUnborn(target):
+		check.Message = locale.Tf("CommandDoctorRepositoryUnborn", toplevel)
+	default:
+		check.Message = toplevel
+	}
+
+	return check
+}
+
+// checkMailmap checks that git understands every line of the mailmap file of the repository, if any
+func checkMailmap(target string) *doctorCheck {
+	check := &doctorCheck{Name: "mailmap", Status: doctorPass}
+	path := filepath.Join(reader.ReadToplevel(target), reader.MailmapFileName)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Message = locale.Tf("CommandDoctorNoFile", reader.MailmapFileName)
+		return check
+	}
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Remedy = locale.Tf("CommandDoctorUnreadableRemedy", path)
+		return check
+	}
+	check.Message = path
+	if invalid := reader.InvalidMailmapLines(string(content)); len(invalid) > 0 {
+		check.Status = doctorWarn
+		check.Message = locale.Tf("CommandDoctorMailmapInvalid", path, strings.Join(invalid, " | "))
+		check.Remedy = locale.T("CommandDoctorMailmapInvalidRemedy")
+	}
+
+	return check
+}
+
+// checkUserConfig checks that the config file of the user, if any, parses and only holds known keys
+func checkUserConfig() *doctorCheck {
+	check := &doctorCheck{Name: "user config", Status: doctorPass}
+	err := viper.ReadInConfig()
+	if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		check.Message = locale.Tf("CommandDoctorNoFile", "~/.git-spend.yaml")
+		return check
+	}
+	path := viper.ConfigFileUsed()
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = locale.Tf("CommandDoctorConfigUnparsable", path, err.Error())
+		check.Remedy = locale.T("CommandDoctorConfigUnparsableRemedy")
+		return check
+	}
+	config := viper.New()
+	config.SetConfigFile(path)
+	if err := config.ReadInConfig(); err != nil {
+		check.Status = doctorFail
+		check.Message = locale.Tf("CommandDoctorConfigUnparsable", path, err.Error())
+		check.Remedy = locale.T("CommandDoctorConfigUnparsableRemedy")
+		return check
+	}
+	check.Message = path
+	known := append(gitime.ScheduleConfigKeys(), userConfigKeys...)
+	if unknown := unknownConfigKeys(config, known); len(unknown) > 0 {
+		check.Status = doctorWarn
+		check.Message = locale.Tf("CommandDoctorConfigUnknownKeys", path, strings.Join(unknown, ", "))
+		check.Remedy = locale.T("CommandDoctorConfigUnknownKeysRemedy")
+	}
+
+	return check
+}
+
+// checkRepositoryConfig checks that the config file of the repository, if any, parses and only holds known keys
+func checkRepositoryConfig(target string) *doctorCheck {
+	check := &doctorCheck{Name: "repository config", Status: doctorPass}
+	config, err := reader.ReadRepositoryConfig(target)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Remedy = locale.T("CommandDoctorConfigUnparsableRemedy")
+		return check
+	}
+	if config == nil {
+		check.Message = locale.Tf("CommandDoctorNoFile", reader.RepositoryConfigNames[0])
+		return check
+	}
+	path := config.ConfigFileUsed()
+	check.Message = path
+	known := append(gitime.ScheduleConfigKeys(), repositoryConfigKeys...)
+	if unknown := unknownConfigKeys(config, known); len(unknown) > 0 {
+		check.Status = doctorWarn
+		check.Message = locale.Tf("CommandDoctorConfigUnknownKeys", path, strings.Join(unknown, ", "))
+		check.Remedy = locale.T("CommandDoctorRepositoryConfigUnknownKeysRemedy")
+	}
+
+	return check
+}
+
+// checkCorrections checks that the corrections of the repository parse
+func checkCorrections(target string) *doctorCheck {
+	check := &doctorCheck{Name: "corrections", Status: doctorPass}
+	corrections, err := reader.ReadCorrections(target)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Remedy = locale.T("CommandDoctorConfigUnparsableRemedy")
+		return check
+	}
+	check.Message = locale.Tf("CommandDoctorCorrections", len(corrections))
+
+	return check
+}
+
+// checkHook checks that the hook of the repository, if it calls git spend, is executable
+func checkHook(target string, hook string) *doctorCheck {
+	check := &doctorCheck{Name: hook + " hook", Status: doctorPass}
+	path := reader.ReadHookPath(target, hook)
+	info, err := os.Stat(path)
+	if path == "" || err != nil {
+		check.Message = locale.Tf("CommandDoctorHookNone", hook)
+		return check
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Remedy = locale.Tf("CommandDoctorUnreadableRemedy", path)
+		return check
+	}
+	if !strings.Contains(string(content), "git spend") && !strings.Contains(string(content), "git-spend") {
+		check.Message = locale.Tf("CommandDoctorHookForeign", path)
+		return check
+	}
+	check.Message = path
+	// Windows has no executable bit, and git for Windows runs the hooks anyway
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		check.Status = doctorFail
+		check.Message = locale.Tf("CommandDoctorHookNotExecutable", path)
+		check.Remedy = locale.Tf("CommandDoctorHookNotExecutableRemedy", path)
+	}
+
+	return check
+}
+
+// checkCloneCache checks that the mirrors of --gitlab-group may be written in the clone cache
+func checkCloneCache() *doctorCheck {
+	check := &doctorCheck{Name: "clone cache", Status: doctorPass}
+	directory, err := cloneCacheDirectory()
+	if err == nil {
+		err = checkWritable(directory)
+	}
+	if err != nil {
+		check.Status = doctorWarn
+		check.Message = err.Error()
+		check.Remedy = locale.T("CommandDoctorCloneCacheRemedy")
+		return check
+	}
+	check.Message = directory
+
+	return check
+}
+
+// checkRunLog checks that the runs of the target may be logged
+func checkRunLog(target string) *doctorCheck {
+	check := &doctorCheck{Name: "run log", Status: doctorPass}
+	path, err := runLogPath(target)
+	if err == nil {
+		err = checkWritable(filepath.Dir(path))
+	}
+	if err != nil {
+		check.Status = doctorWarn
+		check.Message = err.Error()
+		check.Remedy = locale.T("CommandDoctorRunLogRemedy")
+		return check
+	}
+	check.Message = path
+
+	return check
+}
+
+// checkRecentCommits scans the most recent commits for directives that were probably not read as intended
+func checkRecentCommits(target string) *doctorCheck {
+	check := &doctorCheck{Name: "recent commits", Status: doctorPass}
+	if reader.ReadGitDir(target) == "" {
+		check.Message = locale.T("CommandDoctorScanSkipped")
+		return check
+	}
+	commits, err := reader.ReadRecentCommits(doctorScanDepth, target)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Remedy = locale.T("CommandDoctorRepositoryMissingRemedy")
+		return check
+	}
+	gitime.CommentChar = reader.ReadCommentChar(target)
+	suspicious := make([]string, 0)
+	for _, commit := range commits {
+		lines := append(gitime.CollectNearMissDirectives(commit.Message), gitime.CollectUnparsableDirectives(commit.Message)...)
+		for _, line := range lines {
+			suspicious = append(suspicious, commit.ShortHash()+" "+line)
+		}
+	}
+	check.Message = locale.Tf("CommandDoctorScan", len(commits))
+	if len(suspicious) > 0 {
+		check.Status = doctorWarn
+		shown := suspicious
+		if len(shown) > doctorScanShown {
+			shown = shown[:doctorScanShown]
+		}
+		check.Message = locale.Tf("CommandDoctorScanSuspicious", len(suspicious), len(commits), strings.Join(shown, " | "))
+		check.Remedy = locale.T("CommandDoctorScanRemedy")
+	}
+
+	return check
+}
+
+// unknownConfigKeys returns the top-level keys of the config that are not known, sorted
+func unknownConfigKeys(config *viper.Viper, known []string) []string {
+	unknown := make([]string, 0)
+	for _, key := range config.AllKeys() {
+		key, _, _ = strings.Cut(key, ".")
+		if !isSupported(key, known) && !isSupported(key, unknown) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+// checkWritable creates the directory if needed, and checks that files may be written in it
+func checkWritable(directory string) error {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(directory, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	_ = probe.Close()
+
+	return os.Remove(probe.Name())
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+}
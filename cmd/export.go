@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	FlagExportSessions bool
+	FlagExportFormat   string
+)
+
+type jsonSessions struct {
+	Meta *jsonMeta `json:"meta"`
+	// Synthetic is always true : the sessions are reconstructed from durations, and were not recorded
+	Synthetic bool           `json:"synthetic"`
+	Notice    string         `json:"notice"`
+	Sessions  []*jsonSession `json:"sessions"`
+}
+
+type jsonSession struct {
+	Author      string    `json:"author"`
+	Hash        string    `json:"hash"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Minutes     uint64    `json:"minutes"`
+	Description string    `json:"description"`
+	Unresolved  bool      `json:"unresolved"`
+}
+
+var exportCmd = &cobra.Command{
+	Use:               "export",
+	Short:             locale.T("CommandExportSummary"),
+	Long:              locale.T("CommandExportDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !FlagExportSessions {
+			fail(locale.T("CommandExportFailureWhat"), cmd)
+		}
+		_, err := applyWindow()
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = validateOrder()
+		if err != nil {
+			fail(err, cmd)
+		}
+		applyAuthorMatching()
+		err = applyTeam([]string{FlagTarget})
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+		_, err = diagnosticLevels()
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		if FlagExportFormat == FormatJson && (FlagChunk != 0 || FlagAfter != "") {
+			fail(locale.T("CommandExportFailureChunkJson"), cmd)
+		}
+		sessions, chunks, counts, err := exportSessions(FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		warnings := make([]*gitime.Warning, 0)
+		for _, session := range sessionsOf(sessions, chunks...) {
+			if session.Unresolved {
+				warnings = append(warnings, &gitime.Warning{
+					Code: gitime.CodeSessionUnresolved,
+					Hash: session.Commit.Hash,
+					Message: locale.Tf(
+						"CommandExportUnresolved",
+						session.AuthorName,
+						session.Commit.BucketDate().Format(time.DateOnly),
+						session.Commit.ShortHash(),
+					),
+				})
+			}
+		}
+		err = reportDiagnostics(warnings)
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		switch FlagExportFormat {
+		case FormatCsv:
+			if FlagCounts {
+				printInfo(formatCounts(counts))
+			}
+			err = printSessionsCsv(sessions, chunks)
+		case FormatJson:
+			FlagTargets = []string{FlagTarget}
+			err = printJson(newJsonSessions(sessionsOf(sessions, chunks...), counts))
+		default:
+			err = fmt.Errorf(locale.Tf("FormatUnsupported", FlagExportFormat))
+		}
+		if err != nil {
+			fail(err, cmd)
+		}
+	},
+}
+
+// exportSessions reconstructs the sessions of the commits of the target, under the schedule of its repository,
+// and returns them with the chunks of commits to export, and the counts of the commits.
+// Sessions and counts are of the whole range, whatever the chunks,
+// so that the sessions of a day do not depend on where a run was interrupted.
+func exportSessions(target string) ([]*gitime.Session, [][]*gitime.Commit, *gitime.Counts, error) {
+	schedule, err := readSchedule(target, gitime.CurrentSchedule())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gitime.UseSchedule(schedule)
+	corrections, warnings, err := readCorrections(target)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	err = reportDiagnostics(warnings)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gitime.CommentChar = reader.ReadCommentChar(target)
+	commits, skipped, err := reader.ReadGitLogCommitsInOrderCounting(
+		FlagOrder,
+		FlagAuthors,
+		FlagNoMerges,
+		FlagSince,
+		FlagUntil,
+		target,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	commits = excludeAuthors(commits, skipped)
+	counts := gitime.CountCommits(commits)
+	counts.Filtered(skipped)
+	chunks, err := chunkCommits(commits)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return gitime.ReconstructSessions(commits, corrections), chunks, counts, nil
+}
+
+// sessionsOf returns the sessions of the commits of the chunks, in order
+func sessionsOf(sessions []*gitime.Session, chunks ...[]*gitime.Commit) []*gitime.Session {
+	hashes := make(map[string]bool)
+	for _, chunk := range chunks {
+		for _, commit := range chunk {
+			hashes[commit.Hash] = true
+		}
+	}
+	kept := make([]*gitime.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if hashes[session.Commit.Hash] {
+			kept = append(kept, session)
+		}
+	}
+
+	return kept
+}
+
+// printSessionsCsv prints the sessions of each chunk in turn, flushed before its cursor is printed.
+// The headers are left out when resuming with --after, so that the output may be appended to the previous one.
+func printSessionsCsv(sessions []*gitime.Session, chunks [][]*gitime.Commit) error {
+	w := csv.NewWriter(os.Stdout)
+	if FlagAfter == "" {
+		if !FlagNoHeader {
+			fmt.Println("# " + locale.T("CommandExportSessionsNotice"))
+		}
+		err := w.Write([]string{"author", "date", "start", "end", "minutes", "hash", "description", "unresolved"})
+		if err != nil {
+			return err
+		}
+	}
+	for _, chunk := range chunks {
+		err := printSessionsCsvRows(w, sessionsOf(sessions, chunk))
+		if err != nil {
+			return err
+		}
+		printCursor(chunk)
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func printSessionsCsvRows(w *csv.Writer, sessions []*gitime.Session) error {
+	for _, session := range sessions {
+		err := w.Write([]string{
+			session.AuthorName,
+			session.Commit.Date.Format(time.DateOnly),
+			session.Start.Format(time.RFC3339),
+			session.End.Format(time.RFC3339),
+			strconv.FormatUint(session.Minutes, 10),
+			session.Commit.Hash,
+			session.Commit.Subject(),
+			strconv.FormatBool(session.Unresolved),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func newJsonSessions(sessions []*gitime.Session, counts *gitime.Counts) *jsonSessions {
+	out := &jsonSessions{
+		Meta:      newJsonMeta(),
+		Synthetic: true,
+		Notice:    locale.T("CommandExportSessionsNotice"),
+		Sessions:  make([]*jsonSession, 0, len(sessions)),
+	}
+	out.Meta.Counts = counts
+	for _, session := range sessions {
+		out.Sessions = append(out.Sessions, &jsonSession{
+			Author:      session.AuthorName,
+			Hash:        session.Commit.Hash,
+			Start:       session.Start,
+			End:         session.End,
+			Minutes:     session.Minutes,
+			Description: session.Commit.Subject(),
+			Unresolved:  session.Unresolved,
+		})
+	}
+
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().SortFlags = false
+	exportCmd.Flags().BoolVar(
+		&FlagExportSessions,
+		"sessions",
+		false,
+		locale.T("CommandExportFlagSessionsHelp"),
+	)
+	exportCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	addFilterFlags(exportCmd)
+	addChunkFlags(exportCmd)
+	addOrderFlag(exportCmd)
+	addCountsFlag(exportCmd)
+	exportCmd.Flags().StringVar(
+		&FlagExportFormat,
+		"format",
+		FormatCsv,
+		locale.T("CommandExportFlagFormatHelp"),
+	)
+	exportCmd.Flags().BoolVar(
+		&FlagNoHeader,
+		"no-header",
+		false,
+		locale.T("CommandExportFlagNoHeaderHelp"),
+	)
+	addDiagnosticFlags(exportCmd)
+}
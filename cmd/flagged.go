@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	FlagFlagWeekendSpend bool
+	FlagFlagDatesFrom    string
+	FlagExcludeFlagged   bool
+)
+
+type jsonFlagged struct {
+	Hash      string         `json:"hash"`
+	Author    string         `json:"author"`
+	Line      string         `json:"line"`
+	Date      string         `json:"date"`
+	Reason    string         `json:"reason"`
+	TimeSpent *jsonTimeSpent `json:"time_spent"`
+}
+
+// isFlagging tells whether the directives spending time on non-working days are flagged
+func isFlagging() bool {
+	return FlagFlagWeekendSpend || FlagFlagDatesFrom != ""
+}
+
+// newNonWorkingDays configures the days to flag from the flags, or returns nil when none are
+func newNonWorkingDays() (*gitime.NonWorkingDays, error) {
+	if FlagExcludeFlagged && !isFlagging() {
+		return nil, fmt.Errorf(locale.T("CommandSumFailureExcludeFlagged"))
+	}
+	if !isFlagging() {
+		return nil, nil
+	}
+
+	days := &gitime.NonWorkingDays{}
+	if FlagFlagWeekendSpend {
+		days.Weekend = gitime.WeekendOf(gitime.WorkingDays)
+	}
+	if FlagFlagDatesFrom != "" {
+		holidays, err := reader.ReadHolidays(FlagFlagDatesFrom)
+		if err != nil {
+			return nil, err
+		}
+		days.Holidays = holidays
+	}
+
+	return days, nil
+}
+
+func newJsonFlagged(flagged []*gitime.FlaggedSpend) []*jsonFlagged {
+	jsonFlags := make([]*jsonFlagged, 0, len(flagged))
+	for _, spend := range flagged {
+		jsonFlags = append(jsonFlags, &jsonFlagged{
+			Hash:      spend.Commit.Hash,
+			Author:    spend.Commit.AuthorName,
+			Line:      spend.Line,
+			Date:      spend.Date.Format(time.DateOnly),
+			Reason:    spend.Reason,
+			TimeSpent: newJsonTimeSpent(spend.TimeSpent.Normalize()),
+		})
+	}
+
+	return jsonFlags
+}
+
+// printFlagged lists the directives spending time on non-working days, and their total,
+// in their own section, so that the time excluded from the total does not disappear silently
+func printFlagged(out io.Writer, collection *gitime.Collection) error {
+	title := "CommandSumFlaggedTitle"
+	if FlagExcludeFlagged {
+		title = "CommandSumNonBillableTitle"
+	}
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, locale.T(title))
+	formatter := &sumFormatter{}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, spend := range collection.Flagged {
+		_, _ = fmt.Fprintln(w, strings.Join([]string{
+			spend.Date.Format(time.DateOnly),
+			spend.Reason,
+			spend.Commit.ShortHash(),
+			spend.Commit.AuthorName,
+			formatter.Format(spend.TimeSpent),
+		}, "\t"))
+	}
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+	total := formatter.Format(gitime.SumFlagged(collection.Flagged))
+	if total == "" {
+		total = "0"
+	}
+	_, _ = fmt.Fprintln(out, locale.Tf("CommandSumFlaggedTotal", len(collection.Flagged), total))
+
+	return nil
+}
+
+// flaggedOutput is where the flagged directives are listed : after the total, or on stderr not to break CSV
+func flaggedOutput() io.Writer {
+	if FlagFormat == FormatCsv {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+func addFlaggingFlags(command *cobra.Command) {
+	command.Flags().BoolVar(
+		&FlagFlagWeekendSpend,
+		"flag-weekend-spend",
+		false,
+		locale.T("CommandSumFlagFlagWeekendSpendHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagFlagDatesFrom,
+		"flag-dates-from",
+		"",
+		locale.T("CommandSumFlagFlagDatesFromHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagExcludeFlagged,
+		"exclude-flagged",
+		false,
+		locale.T("CommandSumFlagExcludeFlaggedHelp"),
+	)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"strconv"
+	"strings"
+)
+
+var (
+	FlagFmtMinutes float64
+	FlagFmtSeconds float64
+	FlagFmtParse   string
+	FlagFmtTo      string
+)
+
+var fmtCmd = &cobra.Command{
+	Use:               "fmt",
+	Short:             locale.T("CommandFmtSummary"),
+	Long:              locale.T("CommandFmtDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if FlagFmtTo != "" && !isSupported(FlagFmtTo, gitime.SupportedUnits) {
+			fail(locale.Tf("UnitUnsupported", FlagFmtTo, strings.Join(gitime.SupportedUnits, ", ")), cmd)
+		}
+		schedule, err := readSchedule(".", gitime.CurrentSchedule())
+		if err != nil {
+			fail(err, cmd)
+		}
+		gitime.UseSchedule(schedule)
+
+		ts, err := readFmtDuration(cmd)
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		switch FlagFormat {
+		case FormatText:
+			fmt.Println(formatFmtDuration(ts))
+		case FormatJson:
+			err = printJson(newJsonTimeSpent(ts.Normalize()))
+		default:
+			err = fmt.Errorf(locale.Tf("FormatUnsupported", FlagFormat))
+		}
+		if err != nil {
+			fail(err, cmd)
+		}
+	},
+}
+
+// readFmtDuration reads the duration from the flags, or else a number of minutes from stdin.
+// Durations to parse go through the same grammar as the directives.
+func readFmtDuration(cmd *cobra.Command) (*gitime.TimeSpent, error) {
+	if FlagFmtParse != "" {
+		ts, err := gitime.ParseTimeSpent(FlagFmtParse)
+		if err != nil {
+			return nil, err
+		}
+		return ts, ts.Validate()
+	}
+
+	minutes := FlagFmtMinutes
+	if cmd.Flags().Changed("seconds") {
+		minutes = FlagFmtSeconds / 60.0
+	} else if !cmd.Flags().Changed("minutes") {
+		input := strings.TrimSpace(reader.ReadStdin())
+		parsed, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf(locale.Tf("CommandFmtFailureNotANumber", input))
+		}
+		minutes = parsed
+	}
+	ts := &gitime.TimeSpent{Minutes: minutes}
+
+	return ts, ts.Validate()
+}
+
+// formatFmtDuration formats the duration the way sum would, as a number with --to, or else as a sentence
+func formatFmtDuration(ts *gitime.TimeSpent) string {
+	if FlagFmtTo != "" {
+		value, _ := ts.FormatInUnit(FlagFmtTo)
+		return value
+	}
+	ts = ts.Normalize()
+	if ts.IsZero() {
+		return "0"
+	}
+
+	return formatSentence(ts, 0)
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+	fmtCmd.Flags().SortFlags = false
+	fmtCmd.Flags().Float64Var(
+		&FlagFmtMinutes,
+		"minutes",
+		0,
+		locale.T("CommandFmtFlagMinutesHelp"),
+	)
+	fmtCmd.Flags().Float64Var(
+		&FlagFmtSeconds,
+		"seconds",
+		0,
+		locale.T("CommandFmtFlagSecondsHelp"),
+	)
+	fmtCmd.Flags().StringVar(
+		&FlagFmtParse,
+		"parse",
+		"",
+		locale.T("CommandFmtFlagParseHelp"),
+	)
+	fmtCmd.Flags().StringVar(
+		&FlagFmtTo,
+		"to",
+		"",
+		locale.Tf("CommandFmtFlagToHelp", strings.Join(gitime.SupportedUnits, "|")),
+	)
+	fmtCmd.Flags().BoolVar(
+		&FlagCompact,
+		"compact",
+		false,
+		locale.T("CommandSumFlagCompactHelp"),
+	)
+	fmtCmd.Flags().StringVar(
+		&FlagFormat,
+		"format",
+		FormatText,
+		locale.T("CommandFmtFlagFormatHelp"),
+	)
+
+	fmtCmd.MarkFlagsMutuallyExclusive("minutes", "seconds", "parse")
+	fmtCmd.MarkFlagsMutuallyExclusive("to", "compact")
+}
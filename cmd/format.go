@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+)
+
+// Output formats shared by the commands supporting a --format flag
+const (
+	FormatText = "text"
+	FormatJson = "json"
+	FormatCsv  = "csv"
+)
+
+var (
+	FlagShowRaw bool
+)
+
+// addShowRawFlag registers the flag of the commands showing durations as sentences
+func addShowRawFlag(command *cobra.Command) {
+	command.Flags().BoolVar(
+		&FlagShowRaw,
+		"show-raw",
+		false,
+		locale.Tf("CommandFlagShowRawHelp", gitime.DefaultOverflowThreshold),
+	)
+}
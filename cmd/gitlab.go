@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+const (
+	FlagTokenEnvDefault = "GITLAB_TOKEN"
+)
+
+var (
+	FlagGitlabGroup string
+	FlagGitlabURL   string
+	FlagTokenEnv    string
+	FlagCloneCache  string
+)
+
+var (
+	FlagIncludeMrDescriptions bool
+)
+
+// targetNames are the names of the targets that are not local directories, like the projects of a GitLab group
+var targetNames = make(map[string]string)
+
+// targetName returns the name of the target, as shown when grouping by repository :
+// the name of its directory, or its whole path when another target has a directory of the same name,
+// so that distinct repositories are never summed together.
+func targetName(target string) string {
+	if name, found := targetNames[target]; found {
+		return name
+	}
+	path := repositoryPath(target)
+	for _, other := range FlagTargets {
+		if _, found := targetNames[other]; found {
+			continue
+		}
+		otherPath := repositoryPath(other)
+		if otherPath != path && filepath.Base(otherPath) == filepath.Base(path) {
+			return path
+		}
+	}
+
+	return filepath.Base(path)
+}
+
+// repositoryPath returns the cleaned absolute path of the repository of the target
+func repositoryPath(target string) string {
+	path, err := filepath.Abs(reader.ReadToplevel(target))
+	if err != nil {
+		return filepath.Clean(target)
+	}
+
+	return path
+}
+
+// gitlabTargets syncs the mirrors of the projects of the GitLab group in the clone cache, and returns their paths.
+// Projects that cannot be synced, like the ones the token cannot read, are skipped with a warning.
+func gitlabTargets() ([]string, []*gitime.Warning, error) {
+	token := os.Getenv(FlagTokenEnv)
+	projects, err := reader.ReadGitlabGroupProjects(FlagGitlabURL, FlagGitlabGroup, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache, err := cloneCacheDirectory()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := make([]string, 0, len(projects))
+	warnings := make([]*gitime.Warning, 0)
+	for _, project := range projects {
+		if FlagVerbose {
+			printInfo(locale.Tf("CommandSumGitlabSyncing", project.PathWithNamespace))
+		}
+		path, err := reader.SyncMirror(project.HttpUrlToRepo, project.PathWithNamespace, cache, token)
+		if err != nil {
+			warnings = append(warnings, &gitime.Warning{
+				Code:     gitime.CodeGitlabProjectSkipped,
+				Location: project.PathWithNamespace,
+				Message:  locale.Tf("WarningGitlabProjectSkipped", project.PathWithNamespace, err.Error()),
+			})
+			continue
+		}
+		targetNames[path] = project.PathWithNamespace
+		targets = append(targets, path)
+	}
+
+	return targets, warnings, nil
+}
+
+// cloneCacheDirectory returns the directory of the mirrors, by default in the cache directory of the user
+func cloneCacheDirectory() (string, error) {
+	if FlagCloneCache != "" {
+		return FlagCloneCache, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf(locale.T("CommandSumFailureCloneCache"))
+	}
+
+	return filepath.Join(cache, "git-spend", "repos"), nil
+}
+
+// checkMergeRequestDescriptions tells whether the merge request descriptions may be read, with the forge
+func checkMergeRequestDescriptions(forge *gitime.Forge) error {
+	if FlagStdin || FlagStdinCommits {
+		return fmt.Errorf(locale.T("CommandSumFailureMrDescriptionsStdin"))
+	}
+	if forge.Name != gitime.ForgeGitlab {
+		return fmt.Errorf(locale.Tf("CommandSumFailureMrDescriptionsForge", forge.Name))
+	}
+	if os.Getenv(FlagTokenEnv) == "" {
+		return fmt.Errorf(locale.Tf("CommandSumFailureMrDescriptionsToken", FlagTokenEnv))
+	}
+
+	return nil
+}
+
+// mergeRequestCommits returns commits standing for the directives of the descriptions of the merge requests
+// that the commits merged or reference, see gitime.DescriptionCommits.
+// Failures of the API are warnings, and the sum then falls back to the commits alone.
+func mergeRequestCommits(target string, commits []*gitime.Commit) ([]*gitime.Commit, []*gitime.Warning) {
+	baseURL, project := gitlabProjectOf(target)
+	if project == "" {
+		return nil, []*gitime.Warning{{
+			Code:     gitime.CodeMergeRequestsUnavailable,
+			Location: target,
+			Message:  locale.Tf("WarningMergeRequestsNoProject", target),
+		}}
+	}
+	token := os.Getenv(FlagTokenEnv)
+	merged, err := reader.ReadGitlabMergedMergeRequests(baseURL, project, token)
+	if err != nil {
+		return nil, []*gitime.Warning{{
+			Code:     gitime.CodeMergeRequestsUnavailable,
+			Location: project,
+			Message:  locale.Tf("WarningMergeRequestsUnavailable", err.Error()),
+		}}
+	}
+
+	warnings := make([]*gitime.Warning, 0)
+	requests := make([]*gitime.MergeRequest, 0)
+	for _, mr := range merged {
+		request := &gitime.MergeRequest{
+			Iid:         mr.Iid,
+			Description: mr.Description,
+			AuthorName:  mr.Author.Name,
+			Hashes:      []string{mr.MergeCommitSha, mr.SquashCommitSha},
+			Commits:     make([]*gitime.Commit, 0),
+		}
+		if mr.MergedAt != nil {
+			request.MergedAt = *mr.MergedAt
+		}
+		if !request.HasDirectives() {
+			continue
+		}
+		for _, commit := range commits {
+			if request.IsMergedOrReferencedBy(commit, project) {
+				request.Commits = append(request.Commits, commit)
+			}
+		}
+		if len(request.Commits) == 0 {
+			continue
+		}
+		mrCommits, err := reader.ReadGitlabMergeRequestCommits(baseURL, project, mr.Iid, token)
+		if err != nil {
+			warnings = append(warnings, &gitime.Warning{
+				Code:     gitime.CodeMergeRequestsUnavailable,
+				Location: fmt.Sprintf("%s!%d", project, mr.Iid),
+				Message:  locale.Tf("WarningMergeRequestsUnavailable", err.Error()),
+			})
+			continue
+		}
+		for _, mrCommit := range mrCommits {
+			request.Commits = append(request.Commits, &gitime.Commit{Hash: mrCommit.Id, Message: mrCommit.Message})
+		}
+		requests = append(requests, request)
+	}
+	described, duplicates := gitime.DescriptionCommits(requests)
+
+	return described, append(warnings, duplicates...)
+}
+
+// gitlabProjectOf returns the URL of the GitLab instance and the path of the project of the target,
+// from the GitLab group it was mirrored from, or else from its origin remote
+func gitlabProjectOf(target string) (string, string) {
+	if name, found := targetNames[target]; found {
+		return FlagGitlabURL, name
+	}
+	baseURL, project := reader.GitlabProjectFromRemote(reader.ReadRemoteURL(target, "origin"))
+	if FlagGitlabURL != reader.DefaultGitlabURL {
+		baseURL = FlagGitlabURL
+	}
+
+	return baseURL, project
+}
+
+func addGitlabFlags(command *cobra.Command) {
+	command.Flags().StringVar(
+		&FlagGitlabGroup,
+		"gitlab-group",
+		"",
+		locale.T("CommandSumFlagGitlabGroupHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagGitlabURL,
+		"gitlab-url",
+		reader.DefaultGitlabURL,
+		locale.T("CommandSumFlagGitlabURLHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagTokenEnv,
+		"token-env",
+		FlagTokenEnvDefault,
+		locale.T("CommandSumFlagTokenEnvHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagCloneCache,
+		"clone-cache",
+		"",
+		locale.T("CommandSumFlagCloneCacheHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagIncludeMrDescriptions,
+		"include-mr-descriptions",
+		false,
+		locale.T("CommandSumFlagIncludeMrDescriptionsHelp"),
+	)
+
+	command.MarkFlagsMutuallyExclusive("target", "gitlab-group")
+	command.MarkFlagsMutuallyExclusive("stdin", "gitlab-group")
+	command.MarkFlagsMutuallyExclusive("stdin-commits", "gitlab-group")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/report"
+	"github.com/goutte/git-spend/locale"
+	"os"
+	"time"
+)
+
+type jsonGroup struct {
+	Key       string         `json:"key"`
+	TimeSpent *jsonTimeSpent `json:"time_spent"`
+	Commits   int            `json:"commits"`
+	First     string         `json:"first,omitempty"`
+	Last      string         `json:"last,omitempty"`
+	SpanDays  int            `json:"span_days,omitempty"`
+}
+
+func newJsonGroups(groups []*gitime.Group) []*jsonGroup {
+	jsonGroups := make([]*jsonGroup, 0, len(groups))
+	for _, group := range groups {
+		jg := &jsonGroup{
+			Key:       group.Key,
+			TimeSpent: newJsonTimeSpent(group.TimeSpent.Normalize()),
+			Commits:   group.Commits,
+		}
+		if FlagWithSpan {
+			jg.First = group.First.Format(time.DateOnly)
+			jg.Last = group.Last.Format(time.DateOnly)
+			jg.SpanDays = group.SpanDays()
+		}
+		jsonGroups = append(jsonGroups, jg)
+	}
+
+	return jsonGroups
+}
+
+// newReport builds the report of the groups of the collection, the way the flags tell
+func newReport(collection *gitime.Collection) *report.Report {
+	builder := report.New(collection.Results).
+		GroupBy(reportGrouper(FlagGroupBy)).
+		FormatWith(&sumFormatter{})
+	if FlagWithSpan {
+		builder.WithSpan()
+	}
+	built := builder.Build()
+	// The shares of split commits are whole minutes, but the total is not rounded
+	built.Total = collection.TimeSpent
+
+	return built
+}
+
+// reportGrouper returns the grouper of the report.  Authors are grouped anew, so that their spellings
+// merge across repositories, and the other groups are the ones the collectors attributed.
+func reportGrouper(groupBy string) report.Grouper {
+	switch groupBy {
+	case gitime.GroupByAuthor:
+		return report.Author
+	case gitime.GroupByRepo:
+		return report.Repository
+	case gitime.GroupByLabel:
+		return report.Label
+	}
+
+	return report.Collected(groupColumnName(groupBy))
+}
+
+func groupColumnName(groupBy string) string {
+	switch groupBy {
+	case gitime.GroupByAuthor:
+		return locale.T("GroupColumnAuthor")
+	case gitime.GroupByIssue:
+		return locale.T("GroupColumnIssue")
+	case gitime.GroupByRepo:
+		return locale.T("GroupColumnRepo")
+	}
+
+	return groupBy
+}
+
+// sumFormatter writes the time spent of the reports like the ungrouped sum, honoring --unit, --minutes and the like
+type sumFormatter struct{}
+
+func (f *sumFormatter) Label() string {
+	if FlagUnit != "" {
+		return gitime.UnitLabel(FlagUnit, 2.0)
+	}
+
+	return locale.T("GroupColumnTimeSpent")
+}
+
+func (f *sumFormatter) Format(ts *gitime.TimeSpent) string {
+	// The column is labelled with the unit, so the rows only hold the value
+	if FlagUnit != "" {
+		value, _ := ts.Normalize().FormatInUnit(FlagUnit)
+		return value
+	}
+
+	return formatTimeSpentValue(ts.Normalize())
+}
+
+func (f *sumFormatter) FormatTotal(ts *gitime.TimeSpent) string {
+	if FlagUnit != "" {
+		return formatTimeSpentInUnit(ts.Normalize(), FlagUnit)
+	}
+
+	return formatTimeSpentValue(ts.Normalize())
+}
+
+func printGroupsTable(collection *gitime.Collection) error {
+	return printReport(collection, report.Text)
+}
+
+func printGroupsCsv(collection *gitime.Collection) error {
+	return printReport(collection, report.CSV)
+}
+
+func printReport(collection *gitime.Collection, renderer report.Renderer) error {
+	out, err := renderer.Render(newReport(collection))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+
+	return err
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"strings"
+)
+
+// jsonMeta states what a report is about, so that it may be forwarded without context
+type jsonMeta struct {
+	Repositories []*jsonMetaRepository `json:"repositories"`
+	Since        string                `json:"since,omitempty"`
+	Until        string                `json:"until,omitempty"`
+	HoursPerDay  float64               `json:"hours_per_day"`
+	DaysPerWeek  float64               `json:"days_per_week"`
+	Version      string                `json:"version"`
+	// Counts are only known once the commits are collected
+	Counts *gitime.Counts `json:"counts,omitempty"`
+}
+
+type jsonMetaRepository struct {
+	Path  string `json:"path"`
+	Range string `json:"range"`
+}
+
+// newJsonMeta resolves the targets and the filters into absolute values, under the current schedule
+func newJsonMeta() *jsonMeta {
+	schedule := gitime.CurrentSchedule()
+	meta := &jsonMeta{
+		Repositories: make([]*jsonMetaRepository, 0, len(FlagTargets)),
+		HoursPerDay:  schedule.HoursInOneDay,
+		DaysPerWeek:  schedule.DaysInOneWeek,
+		Version:      Version,
+	}
+	if FlagStdin {
+		meta.Repositories = append(meta.Repositories, &jsonMetaRepository{
+			Path: locale.T("ReportHeaderStdin"),
+		})
+		return meta
+	}
+
+	for _, target := range FlagTargets {
+		meta.Repositories = append(meta.Repositories, &jsonMetaRepository{
+			Path:  reader.ReadToplevel(target),
+			Range: resolveRange(target),
+		})
+	}
+	// Dates are the same for every target, whereas refs are part of the range
+	if len(FlagTargets) > 0 {
+		if reader.IsDate(FlagSince) {
+			meta.Since = reader.ResolveBound(FlagSince, FlagTargets[0])
+		}
+		if reader.IsDate(FlagUntil) {
+			meta.Until = reader.ResolveBound(FlagUntil, FlagTargets[0])
+		}
+	}
+
+	return meta
+}
+
+// resolveRange returns the revision range of the target, like "v1.0 (1a2b3c4)..HEAD (5d6e7f8)"
+func resolveRange(target string) string {
+	if FlagStdinCommits {
+		return locale.T("ReportHeaderStdinCommits")
+	}
+	until := "HEAD"
+	if FlagUntil != "" && !reader.IsDate(FlagUntil) {
+		until = FlagUntil
+	}
+	out := reader.ResolveBound(until, target)
+	if FlagSince != "" && !reader.IsDate(FlagSince) {
+		out = reader.ResolveBound(FlagSince, target) + ".." + out
+	}
+
+	return out
+}
+
+// formatHeader returns the line stating the repositories, the range, the window, the schedule and the version
+func formatHeader(meta *jsonMeta) string {
+	repositories := make([]string, 0, len(meta.Repositories))
+	for _, repository := range meta.Repositories {
+		if repository.Range == "" {
+			repositories = append(repositories, repository.Path)
+			continue
+		}
+		repositories = append(repositories, locale.Tf("ReportHeaderRepository", repository.Path, repository.Range))
+	}
+	since := meta.Since
+	if since == "" {
+		since = locale.T("ReportHeaderBeginning")
+	}
+	until := meta.Until
+	if until == "" {
+		until = locale.T("ReportHeaderNow")
+	}
+
+	return locale.Tf(
+		"ReportHeader",
+		strings.Join(repositories, ", "),
+		since,
+		until,
+		meta.HoursPerDay,
+		meta.DaysPerWeek,
+		meta.Version,
+	)
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/gitime/runlog"
+	"github.com/goutte/git-spend/gitime/statefile"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+var (
+	FlagIdentitiesFrom      string
+	FlagIdentitiesTo        string
+	FlagIdentitiesStateDirs []string
+	FlagIdentitiesDryRun    bool
+)
+
+var identitiesCmd = &cobra.Command{
+	Use:               "identities",
+	Short:             locale.T("CommandIdentitiesSummary"),
+	DisableAutoGenTag: true,
+}
+
+var identitiesMigrateCmd = &cobra.Command{
+	Use:               "migrate",
+	Short:             locale.T("CommandIdentitiesMigrateSummary"),
+	Long:              locale.T("CommandIdentitiesMigrateDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if FlagIdentitiesFrom == "" || FlagIdentitiesTo == "" {
+			fail(locale.T("CommandIdentitiesMigrateFailureFromTo"), cmd)
+		}
+		mailmap, err := reader.ReadMailmap(FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		migration := &gitime.IdentityMigration{
+			From:    FlagIdentitiesFrom,
+			To:      FlagIdentitiesTo,
+			Mailmap: mailmap,
+		}
+		directories, err := stateDirectories()
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		records, files := 0, 0
+		for _, directory := range directories {
+			paths, err := stateFiles(directory)
+			if err != nil {
+				fail(err, cmd)
+			}
+			for _, path := range paths {
+				changes, err := migrateStateFile(path, migration)
+				if err != nil {
+					fail(explainLock(err, path), cmd)
+				}
+				for _, change := range changes {
+					fmt.Println(locale.Tf("CommandIdentitiesMigrateRecord", path, change.Record))
+					fmt.Println("- " + change.Before)
+					fmt.Println("+ " + change.After)
+				}
+				if len(changes) > 0 {
+					records += len(changes)
+					files++
+				}
+			}
+		}
+
+		if FlagIdentitiesDryRun {
+			printInfo(locale.Tf("CommandIdentitiesMigrateDryRun", records, files))
+		} else {
+			printInfo(locale.Tf("CommandIdentitiesMigrateDone", records, files))
+		}
+	},
+}
+
+// stateDirectories returns the directories holding the state to migrate : the ones asked for,
+// or else the one of the run log of the target
+func stateDirectories() ([]string, error) {
+	if len(FlagIdentitiesStateDirs) > 0 {
+		return FlagIdentitiesStateDirs, nil
+	}
+	path, err := runLogPath(FlagTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{filepath.Dir(path)}, nil
+}
+
+// stateFiles returns the run log and the JSON files of the directory, that may be snapshots, sorted by name
+func stateFiles(directory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if entry.Name() == runlog.FileName || filepath.Ext(entry.Name()) == ".json" {
+			paths = append(paths, filepath.Join(directory, entry.Name()))
+		}
+	}
+
+	return paths, nil
+}
+
+// migrateStateFile migrates the identities of the run log or snapshot at path, unless in a dry run,
+// and returns what changed, or would change
+func migrateStateFile(path string, migration *gitime.IdentityMigration) ([]*gitime.IdentityChange, error) {
+	if filepath.Base(path) == runlog.FileName {
+		return migrateRunLog(path, migration)
+	}
+
+	return migrateSnapshot(path, migration)
+}
+
+func migrateRunLog(path string, migration *gitime.IdentityMigration) ([]*gitime.IdentityChange, error) {
+	migrate := func(entries []*runlog.Entry) []*gitime.IdentityChange {
+		changes := make([]*gitime.IdentityChange, 0)
+		for _, entry := range entries {
+			record := locale.Tf("IdentityRecordRun", entry.Id)
+			changes = append(changes, migration.MigrateIdentities(entry.Filters.Authors, record)...)
+		}
+		return changes
+	}
+	if FlagIdentitiesDryRun {
+		entries, err := runlog.Read(path)
+		if err != nil {
+			return nil, err
+		}
+		return migrate(entries), nil
+	}
+
+	var changes []*gitime.IdentityChange
+	err := runlog.Rewrite(path, func(entries []*runlog.Entry) bool {
+		changes = migrate(entries)
+		return len(changes) > 0
+	})
+
+	return changes, err
+}
+
+// migrateSnapshot migrates the authors of the ledger of the snapshot at path.
+// JSON files that are not snapshots are left alone.
+func migrateSnapshot(path string, migration *gitime.IdentityMigration) ([]*gitime.IdentityChange, error) {
+	content, err := os.ReadFile(path)
+	if err != nil || !isSnapshot(content) {
+		return nil, err
+	}
+	migrate := func(content []byte) ([]byte, []*gitime.IdentityChange, error) {
+		snapshot, err := parseSnapshot(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		changes := migration.MigrateLedger(snapshot.Ledger)
+		migrated, err := json.MarshalIndent(snapshot, "", "  ")
+		return append(migrated, '\n'), changes, err
+	}
+	if FlagIdentitiesDryRun {
+		_, changes, err := migrate(content)
+		return changes, err
+	}
+
+	var changes []*gitime.IdentityChange
+	err = statefile.Update(path, 0644, statefile.DefaultTimeout, func(content []byte) ([]byte, error) {
+		migrated, migratedChanges, err := migrate(content)
+		if err != nil {
+			return nil, err
+		}
+		changes = migratedChanges
+		if len(changes) == 0 {
+			return content, nil
+		}
+		return migrated, nil
+	})
+
+	return changes, err
+}
+
+// isSnapshot tells whether the content is a snapshot, of any schema version, rather than some other JSON file
+func isSnapshot(content []byte) bool {
+	probe := &struct {
+		SchemaVersion int             `json:"schema_version"`
+		Ledger        json.RawMessage `json:"ledger"`
+	}{}
+
+	return json.Unmarshal(content, probe) == nil && probe.SchemaVersion > 0 && probe.Ledger != nil
+}
+
+func init() {
+	rootCmd.AddCommand(identitiesCmd)
+	identitiesCmd.AddCommand(identitiesMigrateCmd)
+
+	identitiesCmd.PersistentFlags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	identitiesMigrateCmd.Flags().SortFlags = false
+	identitiesMigrateCmd.Flags().StringVar(
+		&FlagIdentitiesFrom,
+		"from",
+		"",
+		locale.T("CommandIdentitiesMigrateFlagFromHelp"),
+	)
+	identitiesMigrateCmd.Flags().StringVar(
+		&FlagIdentitiesTo,
+		"to",
+		"",
+		locale.T("CommandIdentitiesMigrateFlagToHelp"),
+	)
+	identitiesMigrateCmd.Flags().StringArrayVar(
+		&FlagIdentitiesStateDirs,
+		"state-dir",
+		[]string{},
+		locale.T("CommandIdentitiesMigrateFlagStateDirHelp"),
+	)
+	identitiesMigrateCmd.Flags().BoolVar(
+		&FlagIdentitiesDryRun,
+		"dry-run",
+		false,
+		locale.T("CommandIdentitiesMigrateFlagDryRunHelp"),
+	)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+)
+
+// jsonTimeSpent is how a TimeSpent is written in JSON outputs:
+// always the total in minutes, plus the components and the (localized) sentence.
+type jsonTimeSpent struct {
+	Minutes    uint64            `json:"minutes"`
+	Components *gitime.TimeSpent `json:"components"`
+	String     string            `json:"string"`
+}
+
+func newJsonTimeSpent(ts *gitime.TimeSpent) *jsonTimeSpent {
+	return &jsonTimeSpent{
+		Minutes:    ts.ToMinutes(),
+		Components: ts,
+		String:     ts.String(),
+	}
+}
+
+func printJson(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+
+	return nil
+}
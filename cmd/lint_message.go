@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var lintMessageCmd = &cobra.Command{
+	Use:               "lint-message [<file>]",
+	Short:             locale.T("CommandLintMessageSummary"),
+	Long:              locale.T("CommandLintMessageDescription"),
+	Args:              cobra.MaximumNArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		message := ""
+		if len(args) == 0 || args[0] == "-" {
+			message = reader.ReadStdin()
+		} else {
+			content, err := os.ReadFile(args[0])
+			if err != nil {
+				fail(err, cmd)
+			}
+			message = string(content)
+		}
+
+		levels, err := diagnosticLevels()
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+		linter, err := newLinter(true)
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		gitime.CommentChar = reader.ReadCommentChar(".")
+		// The violations fail the message, unless they are allowed
+		violations := levels.Apply(violationWarnings(linter.LintMessage(message)))
+		for _, violation := range violations {
+			violation.Severity = gitime.SeverityError
+		}
+		printWarnings(violations)
+		if len(gitime.CollectDirectives(message)) == 0 {
+			for _, line := range gitime.CollectCommentedDirectives(message) {
+				_, _ = fmt.Fprintln(os.Stderr, locale.Tf("Hint", locale.Tf("HintDirectiveCommentedOut", line)))
+			}
+		}
+		if len(violations) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// violationWarnings returns the violations of the policies as diagnostics
+func violationWarnings(violations []*gitime.Violation) []*gitime.Warning {
+	warnings := make([]*gitime.Warning, 0, len(violations))
+	for _, violation := range violations {
+		warnings = append(warnings, &gitime.Warning{
+			Code:    violation.Code,
+			Message: violation.Message,
+		})
+	}
+
+	return warnings
+}
+
+func init() {
+	rootCmd.AddCommand(lintMessageCmd)
+	lintMessageCmd.Flags().SortFlags = false
+	addPolicyFlags(lintMessageCmd)
+	addDiagnosticFlags(lintMessageCmd)
+}
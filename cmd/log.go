@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// FormatGitlabNotes writes the system notes GitLab records for /spend quick actions
+const FormatGitlabNotes = "gitlab-notes"
+
+var (
+	FlagLogFormat string
+)
+
+var logCmd = &cobra.Command{
+	Use:               "log",
+	Short:             locale.T("CommandLogSummary"),
+	Long:              locale.T("CommandLogDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := applyWindow()
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = validateOrder()
+		if err != nil {
+			fail(err, cmd)
+		}
+		applyAuthorMatching()
+		err = applyTeam([]string{FlagTarget})
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+		if reader.ReadGitDir(FlagTarget) == "" {
+			fail(locale.Tf("CommandWipNotARepository", FlagTarget), cmd)
+		}
+
+		gitime.CommentChar = reader.ReadCommentChar(FlagTarget)
+		commits, skipped, err := reader.ReadGitLogCommitsInOrderCounting(
+			FlagOrder,
+			FlagAuthors,
+			FlagNoMerges,
+			FlagSince,
+			FlagUntil,
+			FlagTarget,
+		)
+		if err != nil {
+			fail(err, cmd)
+		}
+		commits = excludeAuthors(commits, skipped)
+		if FlagCounts {
+			counts := gitime.CountCommits(commits)
+			counts.Filtered(skipped)
+			printInfo(formatCounts(counts))
+		}
+		switch FlagLogFormat {
+		case FormatText:
+			printLog(commits)
+		case FormatGitlabNotes:
+			for _, commit := range commits {
+				for _, note := range gitime.GitlabTimeSpentNotes(commit) {
+					fmt.Println(note)
+				}
+			}
+		default:
+			fail(locale.Tf("CommandLogFormatUnsupported", FlagLogFormat), cmd)
+		}
+	},
+}
+
+// printLog prints a line per directive of the commits, with the commit and the date the time was spent
+func printLog(commits []*gitime.Commit) {
+	for _, commit := range commits {
+		for _, directive := range gitime.CollectDirectives(commit.Message) {
+			fmt.Printf(
+				"%s %s %s\n",
+				commit.ShortHash(),
+				directive.BucketDate(commit).Format(time.DateOnly),
+				directive.TimeSpent.String(),
+			)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+	logCmd.Flags().SortFlags = false
+	logCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	logCmd.Flags().StringVar(
+		&FlagLogFormat,
+		"format",
+		FormatText,
+		locale.T("CommandLogFlagFormatHelp"),
+	)
+	addFilterFlags(logCmd)
+	addOrderFlag(logCmd)
+	addCountsFlag(logCmd)
+	addShowRawFlag(logCmd)
+}
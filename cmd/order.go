@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var (
+	FlagOrder string
+)
+
+// addOrderFlag registers the flag of the commands listing commits, like ledgers and timesheets
+func addOrderFlag(command *cobra.Command) {
+	command.Flags().StringVar(
+		&FlagOrder,
+		"order",
+		gitime.DefaultOrder,
+		locale.Tf("CommandOrderFlagHelp", strings.Join(gitime.SupportedOrders, "|")),
+	)
+}
+
+// validateOrder fails when --order is not supported
+func validateOrder() error {
+	if !isSupported(FlagOrder, gitime.SupportedOrders) {
+		return fmt.Errorf(locale.Tf("CommandOrderFailure", FlagOrder, strings.Join(gitime.SupportedOrders, ", ")))
+	}
+
+	return nil
+}
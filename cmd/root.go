@@ -3,19 +3,36 @@ package cmd
 import (
 	"fmt"
 	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
 	"github.com/goutte/git-spend/locale"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// ExitCodeNoBackend is the exit code when there is no way to read the history, like when git is missing,
+// so that provisioning scripts may tell it apart from other failures
+const ExitCodeNoBackend = 69
+
+// annotationGit annotates the commands that need git to read the history
+const annotationGit = "git"
+
 var (
+	// Version of git-spend, stamped at build time with -ldflags "-X github.com/goutte/git-spend/cmd.Version=…"
+	Version = "dev"
+
 	rootCmd = &cobra.Command{
 		Use:               "git-spend",
 		Short:             locale.T("CommandRootSummary"),
 		Long:              locale.T("CommandRootDescription"),
 		DisableAutoGenTag: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			requireGit(cmd)
+			useRepositoryConfig(cmd)
+			gitime.ShowRaw = FlagShowRaw
+		},
 	}
 )
 
@@ -46,7 +63,7 @@ func initConfig() {
 	//	viper.SetConfigFile(configFile)
 	//} else {
 	home, err := os.UserHomeDir()
-	if err != nil {
+	if err == nil {
 		viper.AddConfigPath(home)
 		viper.SetConfigType("yaml")
 		viper.SetConfigName(".git-spend")
@@ -59,6 +76,87 @@ func initConfig() {
 	_ = viper.ReadInConfig()
 
 	gitime.UpdateTimeModuloConfiguration()
+	err = gitime.UpdateCalendarConfiguration()
+	if err != nil {
+		printWarning(err.Error())
+	}
+}
+
+// useRepositoryConfig reads the config of the repository targeted by the command, if any, over the config of
+// the home directory, so that each setting is resolved from the environment, the repository, the home directory,
+// and then the defaults.  When several repositories are targeted, each one only brings its own schedule.
+func useRepositoryConfig(command *cobra.Command) {
+	target := FlagTargetDefault
+	if flag := command.Flags().Lookup("target"); flag != nil {
+		// Like the --target of sum, that may be repeated
+		if targets, isArray := flag.Value.(interface{ GetSlice() []string }); isArray {
+			if len(targets.GetSlice()) != 1 {
+				return
+			}
+			target = targets.GetSlice()[0]
+		} else {
+			target = flag.Value.String()
+		}
+	}
+	config, err := reader.ReadRepositoryConfig(target)
+	if err != nil {
+		printWarning(err.Error())
+		return
+	}
+	if config == nil {
+		return
+	}
+	err = viper.MergeConfigMap(config.AllSettings())
+	if err != nil {
+		printWarning(err.Error())
+		return
+	}
+
+	gitime.UpdateTimeModuloConfiguration()
+	err = gitime.UpdateCalendarConfiguration()
+	if err != nil {
+		printWarning(err.Error())
+	}
+}
+
+// requireGit exits early with an actionable message when the command needs git, and git is missing
+func requireGit(command *cobra.Command) {
+	if command.Annotations[annotationGit] == "" || FlagStdin {
+		return
+	}
+	if err := reader.LookGit(); err != nil {
+		printInfo(strings.TrimSpace(locale.T("GitMissing")))
+		os.Exit(ExitCodeNoBackend)
+	}
+}
+
+// getFlagOrConfigString returns the value of the flag if it was set, or else the value of the config key
+func getFlagOrConfigString(flagValue string, configKey string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+
+	return viper.GetString(configKey)
+}
+
+// printInfo writes to stderr, so that the standard output stays parsable
+func printInfo(message string) {
+	_, _ = fmt.Fprintln(os.Stderr, message)
+}
+
+func printWarning(message string) {
+	_, _ = fmt.Fprintln(os.Stderr, locale.Tf("Warning", message))
+}
+
+// printWarnings prints the diagnostics to stderr, with their code and severity
+func printWarnings(warnings []*gitime.Warning) {
+	for _, warning := range warnings {
+		key := "DiagnosticWarning"
+		if warning.Severity == gitime.SeverityError {
+			key = "DiagnosticError"
+		}
+		_, _ = fmt.Fprintln(os.Stderr, locale.Tf(key, warning.Code, warning.Message))
+	}
 }
 
 func fail(anything interface{}, command *cobra.Command) {
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/gitime/runlog"
+	"github.com/goutte/git-spend/gitime/statefile"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	FlagRunsLimit int
+)
+
+var runsCmd = &cobra.Command{
+	Use:               "runs",
+	Short:             locale.T("CommandRunsSummary"),
+	Long:              locale.T("CommandRunsDescription"),
+	DisableAutoGenTag: true,
+}
+
+var runsListCmd = &cobra.Command{
+	Use:               "list",
+	Short:             locale.T("CommandRunsListSummary"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := readRunLog(FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		if FlagRunsLimit > 0 && len(entries) > FlagRunsLimit {
+			entries = entries[len(entries)-FlagRunsLimit:]
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, entry := range entries {
+			_, _ = fmt.Fprintf(
+				w,
+				"%d\t%s\t%s\t%s\n",
+				entry.Id,
+				entry.Timestamp.In(time.Local).Format(time.DateTime),
+				entry.Total,
+				strings.Join(entry.Args, " "),
+			)
+		}
+		_ = w.Flush()
+	},
+}
+
+var runsDiffCmd = &cobra.Command{
+	Use:               "diff <id1> <id2>",
+	Short:             locale.T("CommandRunsDiffSummary"),
+	Args:              cobra.ExactArgs(2),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := readRunLog(FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		before, err := findRun(entries, args[0])
+		if err != nil {
+			fail(err, cmd)
+		}
+		after, err := findRun(entries, args[1])
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		fmt.Print(formatRunsDiff(before, after))
+	},
+}
+
+// runLogPath returns where the runs of the target are logged:
+// in its git directory, or else in the XDG state directory
+func runLogPath(target string) (string, error) {
+	gitDir := reader.ReadGitDir(target)
+	if gitDir == "" {
+		return runlog.StatePath()
+	}
+
+	return filepath.Join(gitDir, runlog.FileName), nil
+}
+
+func readRunLog(target string) ([]*runlog.Entry, error) {
+	path, err := runLogPath(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return runlog.Read(path)
+}
+
+// logRun appends the run of the sum command to the run log of its (first) target
+func logRun(collection *gitime.Collection) error {
+	target := FlagTargetDefault
+	if len(FlagTargets) > 0 {
+		target = FlagTargets[0]
+	}
+	path, err := runLogPath(target)
+	if err != nil {
+		return err
+	}
+	filters := runlog.Filters{
+		Authors:  FlagAuthors,
+		NoMerges: FlagNoMerges,
+		Since:    FlagSince,
+		Until:    FlagUntil,
+	}
+	head := ""
+	if !FlagStdin {
+		filters.Targets = FlagTargets
+		head = reader.ReadHead(target)
+	}
+
+	err = runlog.Append(path, &runlog.Entry{
+		Timestamp: time.Now(),
+		Args:      runlog.Redact(os.Args[1:]),
+		Filters:   filters,
+		Head:      head,
+		Minutes:   collection.TimeSpent.ToMinutes(),
+		Total:     collection.TimeSpent.Normalize().String(),
+	})
+
+	return explainLock(err, path)
+}
+
+// explainLock translates the failure to acquire the lock of the file into a message for humans
+func explainLock(err error, path string) error {
+	if errors.Is(err, statefile.ErrLocked) {
+		return fmt.Errorf(locale.Tf("FailureLocked", path, statefile.DefaultTimeout))
+	}
+
+	return err
+}
+
+func findRun(entries []*runlog.Entry, id string) (*runlog.Entry, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(id, "#"))
+	entry := runlog.Find(entries, number)
+	if err != nil || entry == nil {
+		return nil, fmt.Errorf(locale.Tf("CommandRunsNotFound", id))
+	}
+
+	return entry, nil
+}
+
+func formatRunsDiff(before *runlog.Entry, after *runlog.Entry) string {
+	minutes := func(m uint64) string {
+		return (&gitime.TimeSpent{Minutes: float64(m)}).Normalize().String()
+	}
+	delta := "+0"
+	if after.Minutes > before.Minutes {
+		delta = "+" + minutes(after.Minutes-before.Minutes)
+	} else if after.Minutes < before.Minutes {
+		delta = "-" + minutes(before.Minutes-after.Minutes)
+	}
+
+	out := locale.Tf("CommandRunsDiffTotal", before.Total, after.Total, delta) + "\n"
+	changed := func(key string, a string, b string) {
+		if a != b {
+			out += locale.Tf(key, a, b) + "\n"
+		}
+	}
+	changed("CommandRunsDiffTargets", strings.Join(before.Filters.Targets, " "), strings.Join(after.Filters.Targets, " "))
+	changed("CommandRunsDiffAuthors", strings.Join(before.Filters.Authors, " "), strings.Join(after.Filters.Authors, " "))
+	changed("CommandRunsDiffNoMerges", strconv.FormatBool(before.Filters.NoMerges), strconv.FormatBool(after.Filters.NoMerges))
+	changed("CommandRunsDiffSince", before.Filters.Since, after.Filters.Since)
+	changed("CommandRunsDiffUntil", before.Filters.Until, after.Filters.Until)
+	changed("CommandRunsDiffHead", before.Head, after.Head)
+
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(runsCmd)
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsDiffCmd)
+
+	runsCmd.PersistentFlags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	runsListCmd.Flags().IntVarP(
+		&FlagRunsLimit,
+		"limit",
+		"n",
+		10,
+		locale.T("CommandRunsFlagLimitHelp"),
+	)
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var (
+	FlagShowFormat  string
+	FlagShowExplain bool
+)
+
+type jsonShowDirective struct {
+	Line       string         `json:"line"`
+	TimeSpent  *jsonTimeSpent `json:"time_spent"`
+	Date       *time.Time     `json:"date"`
+	Label      string         `json:"label,omitempty"`
+	Note       string         `json:"note"`
+	AmbiguousM string         `json:"ambiguous_m,omitempty"`
+	Spans      *gitime.Spans  `json:"spans,omitempty"`
+}
+
+type jsonShow struct {
+	Hash        string               `json:"hash"`
+	AuthorName  string               `json:"author_name"`
+	AuthorEmail string               `json:"author_email"`
+	Date        time.Time            `json:"date"`
+	Subject     string               `json:"subject"`
+	Directives  []*jsonShowDirective `json:"directives"`
+	Total       *jsonTimeSpent       `json:"total"`
+}
+
+var showCmd = &cobra.Command{
+	Use:               "show <ref>",
+	Short:             locale.T("CommandShowSummary"),
+	Long:              locale.T("CommandShowDescription"),
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if reader.IsUnborn(FlagTarget) {
+			fmt.Println(locale.T("CommandSumNoCommits"))
+			os.Exit(1)
+		}
+		commit, err := reader.ReadGitCommit(args[0], FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+		gitime.CommentChar = reader.ReadCommentChar(FlagTarget)
+		directives := gitime.CollectDirectives(commit.Message)
+		if FlagShowExplain {
+			directives = gitime.CollectDirectivesWithSpans(commit.Message)
+		}
+		total := &gitime.TimeSpent{}
+		for _, directive := range directives {
+			total.Add(directive.TimeSpent)
+		}
+
+		switch FlagShowFormat {
+		case FormatText:
+			if !FlagShowRaw {
+				total.Normalize()
+			}
+			fmt.Print(formatShow(commit, directives, total))
+		case FormatJson:
+			err = printJson(newJsonShow(commit, directives, total.Normalize()))
+		default:
+			err = fmt.Errorf(locale.Tf("FormatUnsupported", FlagShowFormat))
+		}
+		if err != nil {
+			fail(err, cmd)
+		}
+	},
+}
+
+func formatShow(commit *gitime.Commit, directives []*gitime.Directive, total *gitime.TimeSpent) string {
+	out := fmt.Sprintf(
+		"%s %s <%s> %s\n%s\n\n",
+		commit.ShortHash(),
+		commit.AuthorName,
+		commit.AuthorEmail,
+		commit.Date.Format(time.DateTime),
+		commit.Subject(),
+	)
+	if len(directives) == 0 {
+		return out + locale.Tf("CommandShowNothingFound", commit.ShortHash()) + "\n"
+	}
+
+	for _, directive := range directives {
+		out += directive.Line + "\n"
+		out += "\t" + directive.TimeSpent.String() + "\n"
+		if directive.Date != nil {
+			out += "\t" + locale.Tf("CommandShowDate", directive.Date.Format(time.DateTime)) + "\n"
+		}
+		if directive.Label != "" {
+			out += "\t" + locale.Tf("CommandShowLabel", directive.Label) + "\n"
+		}
+		if directive.Note != "" {
+			out += "\t" + locale.Tf("CommandShowNote", directive.Note) + "\n"
+		}
+		if directive.Spans != nil {
+			out += formatSpans(commit.Message, directive.Spans)
+			if directive.AmbiguousM != "" {
+				out += "\t" + locale.Tf(ambiguousMReadings[directive.AmbiguousM], gitime.AmbiguousM) + "\n"
+			}
+		}
+	}
+	out += "\n" + locale.Tf("CommandShowTotal", total.String()) + "\n"
+
+	return out
+}
+
+// ambiguousMReadings are the locale keys explaining how the bare "m" unit was read
+var ambiguousMReadings = map[string]string{
+	gitime.AmbiguousMinutes: "CommandShowExplainAmbiguousMinutes",
+	gitime.AmbiguousMonths:  "CommandShowExplainAmbiguousMonths",
+}
+
+// formatSpans lists where each part of a directive is in the message, as byte offsets
+func formatSpans(message string, spans *gitime.Spans) string {
+	format := func(key string, span gitime.Span) string {
+		return fmt.Sprintf("\t%s [%d:%d] %s\n", locale.T(key), span.Start, span.End, span.Of(message))
+	}
+
+	out := format("CommandShowExplainKeyword", spans.Keyword)
+	for _, token := range spans.Tokens {
+		out += format("CommandShowExplainToken", token)
+	}
+	if spans.Date != nil {
+		out += format("CommandShowExplainDate", *spans.Date)
+	}
+	if spans.Label != nil {
+		out += format("CommandShowExplainLabel", *spans.Label)
+	}
+	if spans.Note != nil {
+		out += format("CommandShowExplainNote", *spans.Note)
+	}
+
+	return out
+}
+
+func newJsonShow(commit *gitime.Commit, directives []*gitime.Directive, total *gitime.TimeSpent) *jsonShow {
+	show := &jsonShow{
+		Hash:        commit.Hash,
+		AuthorName:  commit.AuthorName,
+		AuthorEmail: commit.AuthorEmail,
+		Date:        commit.Date,
+		Subject:     commit.Subject(),
+		Directives:  newJsonShowDirectives(directives),
+		Total:       newJsonTimeSpent(total),
+	}
+
+	return show
+}
+
+func newJsonShowDirectives(directives []*gitime.Directive) []*jsonShowDirective {
+	jsonDirectives := make([]*jsonShowDirective, 0, len(directives))
+	for _, directive := range directives {
+		jsonDirectives = append(jsonDirectives, &jsonShowDirective{
+			Line:       directive.Line,
+			TimeSpent:  newJsonTimeSpent(directive.TimeSpent),
+			Date:       directive.Date,
+			Label:      directive.Label,
+			Note:       directive.Note,
+			AmbiguousM: directive.AmbiguousM,
+			Spans:      directive.Spans,
+		})
+	}
+
+	return jsonDirectives
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().SortFlags = false
+	showCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	showCmd.Flags().StringVar(
+		&FlagShowFormat,
+		"format",
+		FormatText,
+		locale.T("CommandShowFlagFormatHelp"),
+	)
+	showCmd.Flags().BoolVar(
+		&FlagShowExplain,
+		"explain",
+		false,
+		locale.T("CommandShowFlagExplainHelp"),
+	)
+	addShowRawFlag(showCmd)
+}
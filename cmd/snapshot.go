@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/gitime/statefile"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:               "snapshot",
+	Short:             locale.T("CommandSnapshotSummary"),
+	Long:              locale.T("CommandSnapshotDescription"),
+	DisableAutoGenTag: true,
+}
+
+var snapshotWriteCmd = &cobra.Command{
+	Use:               "write <file>",
+	Short:             locale.T("CommandSnapshotWriteSummary"),
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := applyWindow()
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = validateOrder()
+		if err != nil {
+			fail(err, cmd)
+		}
+		applyAuthorMatching()
+		err = applyTeam([]string{FlagTarget})
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
+		if err != nil {
+			fail(err, cmd)
+		}
+		_, err = diagnosticLevels()
+		if err != nil {
+			fail(err, cmd)
+		}
+		since, err := reader.FreezeBound(FlagSince, FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		until, err := reader.FreezeBound(FlagUntil, FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		filters := gitime.SnapshotFilters{
+			Authors:             FlagAuthors,
+			ExcludedAuthors:     excludedAuthors,
+			NoMerges:            FlagNoMerges,
+			Since:               since,
+			Until:               until,
+			FoldAccents:         gitime.FoldAccents,
+			CaseSensitiveEmails: gitime.CaseSensitiveEmails,
+			WordNumbers:         getFlagOrConfigString(FlagWordNumbers, "word_numbers"),
+			Order:               FlagOrder,
+		}
+		if gitime.RangePolicy != gitime.RangeMidpoint {
+			filters.RangePolicy = gitime.RangePolicy
+		}
+		if gitime.AmbiguousM != gitime.AmbiguousMinutes {
+			filters.AmbiguousM = gitime.AmbiguousM
+		}
+		ledger, hashes, err := readLedger(filters)
+		if err != nil {
+			fail(err, cmd)
+		}
+		snapshot := gitime.NewSnapshot(
+			reader.ReadHead(FlagTarget),
+			filters,
+			ledger,
+			hashes,
+			time.Now(),
+		)
+
+		content, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = statefile.WriteAtomic(args[0], append(content, '\n'), 0644)
+		if err != nil {
+			fail(err, cmd)
+		}
+		printInfo(locale.Tf("CommandSnapshotWritten", len(snapshot.Ledger), args[0]))
+	},
+}
+
+var snapshotVerifyCmd = &cobra.Command{
+	Use:               "verify <file>",
+	Short:             locale.T("CommandSnapshotVerifySummary"),
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := diagnosticLevels()
+		if err != nil {
+			fail(err, cmd)
+		}
+		snapshot, err := readSnapshot(args[0])
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		ledger, _, err := readLedger(snapshot.Filters)
+		if err != nil {
+			fail(err, cmd)
+		}
+		drifts := snapshot.Verify(ledger, func(hash string) bool {
+			return reader.IsAncestor(hash, snapshot.Head, FlagTarget)
+		})
+		for _, drift := range drifts {
+			fmt.Println(formatDrift(drift))
+		}
+		if len(drifts) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println(locale.Tf("CommandSnapshotNoDrift", len(snapshot.Ledger), snapshot.TakenAt.Format(time.DateTime)))
+	},
+}
+
+// readLedger reads the ledger of the commits of the target, using the filters, and the hashes of the commits read
+func readLedger(filters gitime.SnapshotFilters) ([]*gitime.LedgerEntry, []string, error) {
+	gitime.CommentChar = reader.ReadCommentChar(FlagTarget)
+	gitime.FoldAccents = filters.FoldAccents
+	gitime.CaseSensitiveEmails = filters.CaseSensitiveEmails
+	gitime.WordNumbers = gitime.NumberWordsByLanguage[filters.WordNumbers]
+	gitime.RangePolicy = filters.RangePolicy
+	if gitime.RangePolicy == "" {
+		gitime.RangePolicy = gitime.RangeMidpoint
+	}
+	gitime.AmbiguousM = filters.AmbiguousM
+	if gitime.AmbiguousM == "" {
+		gitime.AmbiguousM = gitime.AmbiguousMinutes
+	}
+	order := filters.Order
+	if order == "" {
+		order = gitime.DefaultOrder
+	}
+	commits := reader.ReadGitLogCommitsInOrder(
+		order,
+		filters.Authors,
+		filters.NoMerges,
+		filters.Since,
+		filters.Until,
+		FlagTarget,
+	)
+	excludedAuthors = filters.ExcludedAuthors
+	commits = excludeAuthors(commits, map[string]int{})
+
+	corrections, warnings, err := readCorrections(FlagTarget)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = reportDiagnostics(warnings)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		hashes = append(hashes, commit.Hash)
+	}
+
+	return gitime.NewLedger(commits, corrections), hashes, nil
+}
+
+func readSnapshot(path string) (*gitime.Snapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSnapshot(content)
+}
+
+func parseSnapshot(content []byte) (*gitime.Snapshot, error) {
+	snapshot := &gitime.Snapshot{}
+	err := json.Unmarshal(content, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.SchemaVersion != gitime.SnapshotSchemaVersion {
+		return nil, fmt.Errorf(locale.Tf(
+			"CommandSnapshotSchemaUnsupported",
+			snapshot.SchemaVersion,
+			gitime.SnapshotSchemaVersion,
+		))
+	}
+
+	return snapshot, nil
+}
+
+func formatDrift(drift *gitime.Drift) string {
+	minutes := func(entry *gitime.LedgerEntry) string {
+		return (&gitime.TimeSpent{Minutes: float64(entry.Minutes)}).Normalize().String()
+	}
+	short := func(entry *gitime.LedgerEntry) string {
+		return (&gitime.Commit{Hash: entry.Hash}).ShortHash()
+	}
+
+	switch drift.Kind {
+	case gitime.DriftChanged:
+		return locale.Tf("DriftChanged", short(drift.Before), drift.Before.Author, minutes(drift.Before), minutes(drift.After))
+	case gitime.DriftDisappeared:
+		return locale.Tf("DriftDisappeared", short(drift.Before), drift.Before.Author, minutes(drift.Before))
+	}
+
+	return locale.Tf("DriftAppeared", short(drift.After), drift.After.Author, minutes(drift.After))
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotWriteCmd)
+	snapshotCmd.AddCommand(snapshotVerifyCmd)
+
+	snapshotWriteCmd.Flags().SortFlags = false
+	snapshotWriteCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	addFilterFlags(snapshotWriteCmd)
+	addOrderFlag(snapshotWriteCmd)
+	addDiagnosticFlags(snapshotWriteCmd)
+
+	snapshotVerifyCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+	addDiagnosticFlags(snapshotVerifyCmd)
+}
@@ -6,7 +6,11 @@ import (
 	"github.com/goutte/git-spend/gitime/reader"
 	"github.com/goutte/git-spend/locale"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -16,6 +20,7 @@ const (
 var (
 	FlagAuthors  []string
 	FlagTarget   string
+	FlagTargets  []string
 	FlagStdin    bool
 	FlagSince    string
 	FlagUntil    string
@@ -25,38 +30,328 @@ var (
 	FlagWeeks    bool
 	FlagMonths   bool
 	FlagNoMerges bool
+	FlagUnit     string
+	FlagFormat   string
 )
 
+var (
+	FlagFoldAccents         bool
+	FlagCaseSensitiveEmails bool
+	FlagWordNumbers         string
+	FlagRangePolicy         string
+)
+
+var (
+	FlagMaxDirective string
+	FlagEnforceMax   bool
+	FlagDedupe       string
+)
+
+var (
+	FlagMinGranularity string
+	FlagPolicy         string
+	FlagCheckPolicy    bool
+	FlagStrict         bool
+)
+
+var (
+	FlagLast    string
+	FlagThis    string
+	FlagVerbose bool
+)
+
+var (
+	FlagGroupBy  string
+	FlagSplit    string
+	FlagWithSpan bool
+)
+
+var (
+	FlagFailIfEmpty   bool
+	FlagStdinCommits  bool
+	FlagIgnoreMissing bool
+	FlagExpect        int
+)
+
+var (
+	FlagLogRuns bool
+)
+
+var (
+	FlagCompact  bool
+	FlagMaxWidth int
+	FlagNoHeader bool
+	FlagCounts   bool
+)
+
+var (
+	FlagForge      string
+	FlagIssueRegex string
+)
+
+type jsonWindow struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+type jsonSum struct {
+	Meta        *jsonMeta         `json:"meta"`
+	Window      *jsonWindow       `json:"window,omitempty"`
+	Total       *jsonTimeSpent    `json:"total"`
+	Excluded    *jsonTimeSpent    `json:"excluded,omitempty"`
+	Flagged     []*jsonFlagged    `json:"flagged,omitempty"`
+	FlaggedTime *jsonTimeSpent    `json:"flagged_total,omitempty"`
+	NonBillable *jsonTimeSpent    `json:"non_billable,omitempty"`
+	Collapsed   *int              `json:"collapsed,omitempty"`
+	Groups      []*jsonGroup      `json:"groups,omitempty"`
+	Warnings    []*gitime.Warning `json:"warnings"`
+	Violations  []*gitime.Warning `json:"violations"`
+	Diagnostics []*gitime.Warning `json:"diagnostics"`
+	Corrected   []*jsonCorrected  `json:"corrected,omitempty"`
+	Ranges      []*jsonRange      `json:"ranges,omitempty"`
+}
+
+type jsonRange struct {
+	Hash      string         `json:"hash"`
+	Line      string         `json:"line"`
+	Low       *jsonTimeSpent `json:"low"`
+	High      *jsonTimeSpent `json:"high"`
+	Policy    string         `json:"policy"`
+	TimeSpent *jsonTimeSpent `json:"time_spent"`
+}
+
+type jsonCorrected struct {
+	Hash      string         `json:"hash"`
+	Original  *jsonTimeSpent `json:"original"`
+	Corrected *jsonTimeSpent `json:"corrected"`
+}
+
 var sumCmd = &cobra.Command{
 	Use:               "sum",
 	Short:             locale.T("CommandSumSummary"),
 	Long:              locale.T("CommandSumDescription"),
 	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
 	Run: func(cmd *cobra.Command, args []string) {
-		ts, err := Sum()
+		if FlagUnit != "" && !isSupported(FlagUnit, gitime.SupportedUnits) {
+			fail(locale.Tf("UnitUnsupported", FlagUnit, strings.Join(gitime.SupportedUnits, ", ")), cmd)
+		}
+		if FlagWithSpan && FlagGroupBy == gitime.GroupByNone {
+			fail(locale.T("CommandSumFailureWithSpan"), cmd)
+		}
+		window, err := applyWindow()
+		if err != nil {
+			fail(err, cmd)
+		}
+		applyAuthorMatching()
+		err = applyTeam(FlagTargets)
+		if err != nil {
+			fail(err, cmd)
+		}
+		err = applyGrammar()
 		if err != nil {
 			fail(err, cmd)
 		}
-		if ts != nil {
-			fmt.Println(formatTimeSpent(ts.Normalize()))
+		levels, err := diagnosticLevels()
+		if err != nil {
+			fail(err, cmd)
+		}
+
+		collection, err := Sum()
+		if err != nil {
+			fail(err, cmd)
+		}
+		if FlagEnforceMax && !collection.Excluded.IsZero() {
+			collection.Warnings = append(collection.Warnings, &gitime.Warning{
+				Code:    gitime.CodeExcludedOverMax,
+				Message: locale.Tf("CommandSumExcluded", collection.Excluded.Normalize().String()),
+			})
+		}
+		applyDiagnosticLevels(collection, levels)
+		empty := !FlagStdin && areAllUnborn(FlagTargets)
+
+		switch FlagFormat {
+		case FormatText, FormatCsv:
+			if FlagVerbose && !FlagNoHeader && FlagGroupBy == gitime.GroupByNone {
+				printInfo(formatHeader(newJsonMeta()))
+			}
+			if FlagVerbose && window != nil {
+				printInfo(locale.Tf("CommandSumWindow", window.String()))
+			}
+			if FlagVerbose && FlagDedupe != gitime.DedupeNone {
+				printInfo(locale.Tf("CommandSumCollapsed", collection.Collapsed))
+			}
+			if FlagCounts {
+				printInfo(formatCounts(collection.Counts))
+			}
+			if FlagVerbose {
+				for _, corrected := range collection.Corrected {
+					printInfo(formatCorrected(corrected))
+				}
+				for _, collapsed := range collection.Ranges {
+					printInfo(formatCollapsedRange(collapsed))
+				}
+			}
+			printWarnings(collection.Warnings)
+			printWarnings(collection.Violations)
+			if empty && FlagFormat == FormatCsv {
+				printInfo(locale.T("CommandSumNoCommits"))
+			} else if empty {
+				fmt.Println(locale.T("CommandSumNoCommits"))
+			} else if FlagGroupBy == gitime.GroupByNone && FlagShowRaw {
+				fmt.Println(formatTimeSpent(collection.TimeSpent))
+			} else if FlagGroupBy == gitime.GroupByNone {
+				fmt.Println(formatTimeSpent(collection.TimeSpent.Normalize()))
+			} else if FlagFormat == FormatCsv {
+				err = printGroupsCsv(collection)
+			} else {
+				if !FlagNoHeader {
+					fmt.Println(formatHeader(newJsonMeta()))
+				}
+				err = printGroupsTable(collection)
+			}
+			if err == nil && isFlagging() && !empty {
+				err = printFlagged(flaggedOutput(), collection)
+			}
+		case FormatJson:
+			err = printJson(newJsonSum(collection, window))
+		default:
+			err = fmt.Errorf(locale.Tf("FormatUnsupported", FlagFormat))
+		}
+		if err != nil {
+			fail(err, cmd)
+		}
+		if FlagLogRuns || viper.GetBool("log_runs") {
+			if err := logRun(collection); err != nil {
+				unwritten := levels.Apply([]*gitime.Warning{{Code: gitime.CodeRunLogUnwritten, Message: err.Error()}})
+				printWarnings(unwritten)
+				collection.Warnings = append(collection.Warnings, unwritten...)
+			}
+		}
+		if gitime.HasErrors(collection.Warnings) || gitime.HasErrors(collection.Violations) {
+			os.Exit(1)
+		}
+		if FlagFailIfEmpty && empty {
+			os.Exit(1)
 		}
 	},
 }
 
+func newJsonSum(collection *gitime.Collection, window *gitime.Window) *jsonSum {
+	sum := &jsonSum{
+		Meta:        newJsonMeta(),
+		Total:       newJsonTimeSpent(collection.TimeSpent.Normalize()),
+		Warnings:    collection.Warnings,
+		Violations:  collection.Violations,
+		Diagnostics: append(append([]*gitime.Warning{}, collection.Warnings...), collection.Violations...),
+	}
+	sum.Meta.Counts = collection.Counts
+	if window != nil {
+		sum.Window = &jsonWindow{
+			Since: window.Start,
+			Until: window.End,
+		}
+	}
+	for _, corrected := range collection.Corrected {
+		sum.Corrected = append(sum.Corrected, &jsonCorrected{
+			Hash:      corrected.Hash,
+			Original:  newJsonTimeSpent(corrected.Original.Normalize()),
+			Corrected: newJsonTimeSpent(corrected.Corrected.Normalize()),
+		})
+	}
+	for _, collapsed := range collection.Ranges {
+		sum.Ranges = append(sum.Ranges, &jsonRange{
+			Hash:      collapsed.Hash,
+			Line:      collapsed.Line,
+			Low:       newJsonTimeSpent(collapsed.Range.Low.Normalize()),
+			High:      newJsonTimeSpent(collapsed.Range.High.Normalize()),
+			Policy:    collapsed.Range.Policy,
+			TimeSpent: newJsonTimeSpent(collapsed.TimeSpent.Normalize()),
+		})
+	}
+	if FlagEnforceMax {
+		sum.Excluded = newJsonTimeSpent(collection.Excluded.Normalize())
+	}
+	if FlagDedupe != gitime.DedupeNone {
+		sum.Collapsed = &collection.Collapsed
+	}
+	if isFlagging() {
+		sum.Flagged = newJsonFlagged(collection.Flagged)
+		sum.FlaggedTime = newJsonTimeSpent(gitime.SumFlagged(collection.Flagged).Normalize())
+	}
+	if FlagExcludeFlagged {
+		sum.NonBillable = newJsonTimeSpent(collection.NonBillable.Normalize())
+	}
+	if FlagGroupBy != gitime.GroupByNone {
+		sum.Groups = newJsonGroups(newReport(collection).Rows)
+	}
+
+	return sum
+}
+
+// skippedReasons are the locale keys of the reasons commits are skipped for, in the order they are listed
+var skippedReasons = []struct {
+	Reason string
+	Key    string
+}{
+	{gitime.SkippedMerge, "CommandSumCountsMerge"},
+	{gitime.SkippedAuthor, "CommandSumCountsAuthor"},
+	{gitime.SkippedDuplicate, "CommandSumCountsDuplicate"},
+}
+
+// formatCounts returns what the total is made of, like "scanned 12 commits: 8 matched, 3 skipped (merges: 3), 1 unparsable"
+func formatCounts(counts *gitime.Counts) string {
+	reasons := make([]string, 0, len(counts.Skipped))
+	for _, skipped := range skippedReasons {
+		if counts.Skipped[skipped.Reason] > 0 {
+			reasons = append(reasons, locale.Tf(skipped.Key, counts.Skipped[skipped.Reason]))
+		}
+	}
+	skipped := fmt.Sprintf("%d", counts.SkippedTotal())
+	if len(reasons) > 0 {
+		skipped += " (" + strings.Join(reasons, ", ") + ")"
+	}
+
+	return locale.Tf("CommandSumCounts", counts.Scanned, counts.Matched, skipped, counts.Unparsable)
+}
+
+// formatCorrected returns the original and corrected time spent by the commit, like "corrected abc1234: 3 days → 1 hour"
+func formatCorrected(corrected *gitime.CorrectedCommit) string {
+	values := make([]string, 0, 2)
+	for _, ts := range []*gitime.TimeSpent{corrected.Original, corrected.Corrected} {
+		if ts.IsZero() {
+			values = append(values, "0")
+		} else {
+			values = append(values, ts.Normalize().String())
+		}
+	}
+	short := (&gitime.Commit{Hash: corrected.Hash}).ShortHash()
+
+	return locale.Tf("CommandSumCorrected", short, values[0], values[1])
+}
+
+// formatCollapsedRange returns the bounds of the range and what it was collapsed into,
+// like "collapsed range in abc1234: 1h-2h → 1 hour 30 minutes (midpoint)"
+func formatCollapsedRange(collapsed *gitime.CollapsedRange) string {
+	short := (&gitime.Commit{Hash: collapsed.Hash}).ShortHash()
+
+	return locale.Tf(
+		"CommandSumRangeCollapsed",
+		short,
+		collapsed.Range.String(),
+		collapsed.TimeSpent.Normalize().String(),
+		collapsed.Range.Policy,
+	)
+}
+
 func formatTimeSpent(ts *gitime.TimeSpent) string {
 	out := ""
-	if FlagMinutes {
-		out = fmt.Sprintf("%d", ts.ToMinutes())
-	} else if FlagHours {
-		out = fmt.Sprintf("%d", ts.ToHours())
-	} else if FlagDays {
-		out = fmt.Sprintf("%d", ts.ToDays())
-	} else if FlagWeeks {
-		out = fmt.Sprintf("%d", ts.ToWeeks())
-	} else if FlagMonths {
-		out = fmt.Sprintf("%d", ts.ToMonths())
+	if FlagUnit != "" {
+		out = formatTimeSpentInUnit(ts, FlagUnit)
+	} else if FlagMaxWidth > 0 {
+		out = formatSentence(ts, FlagMaxWidth)
 	} else {
-		out = ts.String()
+		out = formatTimeSpentValue(ts)
 	}
 	if out == "" {
 		out = locale.T("CommandSumFailureNothingFound")
@@ -81,10 +376,161 @@ func formatTimeSpent(ts *gitime.TimeSpent) string {
 	return out
 }
 
-func Sum() (*gitime.TimeSpent, error) {
-	var gitLog string
+// formatTimeSpentValue formats the time spent according to the format flags, as a number or a sentence.
+func formatTimeSpentValue(ts *gitime.TimeSpent) string {
+	if FlagMinutes {
+		return fmt.Sprintf("%d", ts.ToMinutes())
+	} else if FlagHours {
+		return fmt.Sprintf("%d", ts.ToHours())
+	} else if FlagDays {
+		return fmt.Sprintf("%d", ts.ToDays())
+	} else if FlagWeeks {
+		return fmt.Sprintf("%d", ts.ToWeeks())
+	} else if FlagMonths {
+		return fmt.Sprintf("%d", ts.ToMonths())
+	}
+
+	return formatSentence(ts, 0)
+}
+
+// formatSentence formats the time spent as a sentence, compact if asked, and wrapped to maxWidth if not zero
+func formatSentence(ts *gitime.TimeSpent, maxWidth int) string {
+	components := ts.Components()
+	if FlagCompact {
+		components = ts.CompactComponents()
+	}
+
+	return gitime.WrapComponents(components, maxWidth)
+}
+
+// formatTimeSpentInUnit formats a total in the unit, followed by the full sentence.
+// Returns an empty string when there is no time spent at all.
+func formatTimeSpentInUnit(ts *gitime.TimeSpent, unit string) string {
+	sentence := formatSentence(ts, 0)
+	if sentence == "" {
+		return ""
+	}
+	value, err := ts.FormatInUnit(unit)
+	if err != nil {
+		return ""
+	}
+	valueFloat, _ := strconv.ParseFloat(value, 64)
+
+	return fmt.Sprintf("%s %s (%s)", value, gitime.UnitLabel(unit, valueFloat), sentence)
+}
+
+// isSupported tells whether the value of a flag is among the supported values
+func isSupported(value string, supportedValues []string) bool {
+	for _, supportedValue := range supportedValues {
+		if value == supportedValue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyWindow resolves the window of --last or --this, if any, into --since and --until
+func applyWindow() (*gitime.Window, error) {
+	window, err := resolveWindow()
+	if err != nil || window == nil {
+		return window, err
+	}
+	FlagSince = window.Start.In(time.Local).Format(time.DateTime)
+	FlagUntil = window.End.Add(-time.Second).In(time.Local).Format(time.DateTime)
+
+	return window, nil
+}
+
+// applyAuthorMatching configures how author names and emails are compared, from the flags and the configuration
+func applyAuthorMatching() {
+	gitime.FoldAccents = FlagFoldAccents || viper.GetBool("fold_accents")
+	gitime.CaseSensitiveEmails = FlagCaseSensitiveEmails || viper.GetBool("case_sensitive_emails")
+}
+
+// applyGrammar configures how directives are read, from the flags and the configuration :
+// the vocabulary of number words, if any, how ranges are collapsed, and how the bare "m" unit is read.
+func applyGrammar() error {
+	language := getFlagOrConfigString(FlagWordNumbers, "word_numbers")
+	if language != "" && !isSupported(language, gitime.SupportedNumberWordsLanguages) {
+		return fmt.Errorf(locale.Tf(
+			"CommandSumFailureWordNumbers",
+			language,
+			strings.Join(gitime.SupportedNumberWordsLanguages, ", "),
+		))
+	}
+	gitime.WordNumbers = gitime.NumberWordsByLanguage[language]
+
+	policy := getFlagOrConfigString(FlagRangePolicy, "range_policy")
+	if policy == "" {
+		policy = gitime.RangeMidpoint
+	}
+	if !isSupported(policy, gitime.SupportedRangePolicies) {
+		return fmt.Errorf(locale.Tf(
+			"CommandSumFailureRangePolicy",
+			policy,
+			strings.Join(gitime.SupportedRangePolicies, ", "),
+		))
+	}
+	gitime.RangePolicy = policy
+
+	ambiguousM := getFlagOrConfigString("", "ambiguous_m")
+	if ambiguousM == "" {
+		ambiguousM = gitime.AmbiguousMinutes
+	}
+	if !isSupported(ambiguousM, gitime.SupportedAmbiguousM) {
+		return fmt.Errorf(locale.Tf(
+			"CommandSumFailureAmbiguousM",
+			ambiguousM,
+			strings.Join(gitime.SupportedAmbiguousM, ", "),
+		))
+	}
+	gitime.AmbiguousM = ambiguousM
+
+	return nil
+}
+
+// applyDiagnosticLevels silences the allowed diagnostics of the collection, and sets the severity of the others.
+// With --strict, all the violations of the policies are errors.
+func applyDiagnosticLevels(collection *gitime.Collection, levels *gitime.DiagnosticLevels) {
+	collection.Warnings = levels.Apply(collection.Warnings)
+	collection.Violations = levels.Apply(collection.Violations)
+	if FlagStrict {
+		for _, violation := range collection.Violations {
+			violation.Severity = gitime.SeverityError
+		}
+	}
+}
+
+// resolveWindow returns the calendar-aligned window of --last or --this, or nil
+func resolveWindow() (*gitime.Window, error) {
+	if FlagLast == "" && FlagThis == "" {
+		return nil, nil
+	}
+	if FlagStdin || FlagStdinCommits {
+		return nil, fmt.Errorf(locale.T("CommandSumFailureStdinWindow"))
+	}
+	if FlagLast != "" {
+		return gitime.PreviousPeriod(FlagLast, time.Now())
+	}
+
+	return gitime.CurrentPeriod(FlagThis, time.Now())
+}
+
+func Sum() (*gitime.Collection, error) {
+	collector, err := newCollector()
+	if err != nil {
+		return nil, err
+	}
+	if FlagIncludeMrDescriptions {
+		err = checkMergeRequestDescriptions(collector.Forge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if FlagStdin {
-		if len(FlagAuthors) > 0 {
+		if len(FlagAuthors) > 0 || len(excludedAuthors) > 0 {
 			return nil, fmt.Errorf(locale.T("CommandSumFailureStdinAuthors"))
 		}
 		if FlagNoMerges {
@@ -96,15 +542,378 @@ func Sum() (*gitime.TimeSpent, error) {
 		if FlagUntil != "" {
 			return nil, fmt.Errorf(locale.T("CommandSumFailureStdinUntil"))
 		}
-		if FlagTarget != FlagTargetDefault {
+		if len(FlagTargets) != 1 || FlagTargets[0] != FlagTargetDefault {
 			return nil, fmt.Errorf(locale.T("CommandSumFailureStdinTarget"))
 		}
-		gitLog = reader.ReadStdin()
-	} else {
-		gitLog = reader.ReadGitLog(FlagAuthors, FlagNoMerges, FlagSince, FlagUntil, FlagTarget)
+		return collector.Collect(reader.ReadStdinCommits()), nil
+	}
+	if FlagStdinCommits {
+		return sumStdinCommits(collector)
+	}
+
+	if FlagGitlabGroup != "" {
+		targets, warnings, err := gitlabTargets()
+		if err != nil {
+			return nil, err
+		}
+		FlagTargets = targets
+		collection, err := sumTargets(collector, targets)
+		if err != nil {
+			return nil, err
+		}
+		collection.Warnings = append(warnings, collection.Warnings...)
+		return collection, nil
+	}
+
+	return sumTargets(collector, FlagTargets)
+}
+
+// sumTargets collects the time spent in the targets, each under the schedule of its own repository config.
+// A single target keeps its schedule for the output, whereas several targets are merged in minutes,
+// and then presented under the schedule of the invocation (user config and environment).
+func sumTargets(collector *gitime.Collector, targets []string) (*gitime.Collection, error) {
+	invocation := gitime.CurrentSchedule()
+	schedules := make([]gitime.Schedule, len(targets))
+	for i, target := range targets {
+		schedule, err := readSchedule(target, invocation)
+		if err != nil {
+			return nil, err
+		}
+		schedules[i] = schedule
+	}
+
+	if len(targets) == 1 {
+		gitime.UseSchedule(schedules[0])
+		return collectTarget(collector, targets[0])
+	}
+
+	total := &gitime.Collection{
+		TimeSpent:   &gitime.TimeSpent{},
+		Excluded:    &gitime.TimeSpent{},
+		Flagged:     make([]*gitime.FlaggedSpend, 0),
+		NonBillable: &gitime.TimeSpent{},
+		Warnings:    make([]*gitime.Warning, 0),
+		Violations:  make([]*gitime.Warning, 0),
+		Counts:      gitime.NewCounts(),
+	}
+	for i, target := range targets {
+		for _, difference := range schedules[i].Differences(schedules[0]) {
+			total.Warnings = append(total.Warnings, &gitime.Warning{
+				Code:     gitime.CodeScheduleConflict,
+				Location: target,
+				Message:  locale.Tf("WarningScheduleConflict", target, targets[0], difference),
+			})
+		}
+		gitime.UseSchedule(schedules[i])
+		collection, err := collectTarget(collector, target)
+		if err != nil {
+			return nil, err
+		}
+		total.Merge(collection.InMinutes())
+	}
+	gitime.UseSchedule(invocation)
+
+	return total, nil
+}
+
+// readSchedule returns the schedule of the repository config of the target, falling back to the fallback
+func readSchedule(target string, fallback gitime.Schedule) (gitime.Schedule, error) {
+	config, err := reader.ReadRepositoryConfig(target)
+	if err != nil {
+		return fallback, err
+	}
+	if config == nil {
+		return fallback, nil
+	}
+
+	return gitime.ScheduleFromConfig(config, fallback), nil
+}
+
+// sumStdinCommits collects the time spent in the commits listed in stdin, resolved in the target.
+// Hashes that do not resolve are reported first, and must be explicitly ignored.
+func sumStdinCommits(collector *gitime.Collector) (*gitime.Collection, error) {
+	if FlagNoMerges || FlagSince != "" || FlagUntil != "" {
+		return nil, fmt.Errorf(locale.T("CommandSumFailureStdinCommitsFilters"))
+	}
+	if len(FlagTargets) != 1 {
+		return nil, fmt.Errorf(locale.T("CommandSumFailureStdinCommitsTargets"))
+	}
+	target := FlagTargets[0]
+
+	resolution, err := reader.ResolveCommits(reader.ReadStdinHashes(), target)
+	if err != nil {
+		return nil, err
 	}
+	printInfo(locale.Tf(
+		"CommandSumStdinCommitsResolution",
+		len(resolution.Resolved),
+		len(resolution.Ambiguous),
+		len(resolution.Unknown),
+	))
+	unresolved := make([]*gitime.Warning, 0)
+	for _, hash := range resolution.Ambiguous {
+		unresolved = append(unresolved, &gitime.Warning{
+			Code:    gitime.CodeStdinCommitUnresolved,
+			Hash:    hash,
+			Message: locale.Tf("CommandSumStdinCommitAmbiguous", hash),
+		})
+	}
+	for _, hash := range resolution.Unknown {
+		unresolved = append(unresolved, &gitime.Warning{
+			Code:    gitime.CodeStdinCommitUnresolved,
+			Hash:    hash,
+			Message: locale.Tf("CommandSumStdinCommitUnknown", hash),
+		})
+	}
+	if len(unresolved) > 0 && !FlagIgnoreMissing {
+		printWarnings(unresolved)
+		return nil, fmt.Errorf(locale.T("CommandSumFailureStdinCommitsMissing"))
+	}
+	if FlagExpect >= 0 && len(resolution.Resolved) != FlagExpect {
+		return nil, fmt.Errorf(locale.Tf("CommandSumFailureStdinCommitsExpect", FlagExpect, len(resolution.Resolved)))
+	}
+
+	schedule, err := readSchedule(target, gitime.CurrentSchedule())
+	if err != nil {
+		return nil, err
+	}
+	gitime.UseSchedule(schedule)
+	corrections, warnings, err := readCorrections(target)
+	if err != nil {
+		return nil, err
+	}
+	collector.Corrections = corrections
+	collector.Changes = reader.NewChangesReader(target).Read
+	gitime.CommentChar = reader.ReadCommentChar(target)
+	commits := make([]*gitime.Commit, 0, len(resolution.Resolved))
+	skipped := map[string]int{}
+	for _, hash := range resolution.Resolved {
+		commit, err := reader.ReadGitCommit(hash, target)
+		if err != nil {
+			return nil, err
+		}
+		if !isAuthoredByAny(commit, FlagAuthors) {
+			skipped[gitime.SkippedAuthor]++
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	commits = excludeAuthors(commits, skipped)
+	collection := collector.Collect(commits)
+	collection.Counts.Filtered(skipped)
+	collection.Warnings = append(append(unresolved, warnings...), collection.Warnings...)
+
+	return collection, nil
+}
+
+// isAuthoredByAny tells whether the commit is authored by any of the authors, or if there are no authors at all
+func isAuthoredByAny(commit *gitime.Commit, authors []string) bool {
+	if len(authors) == 0 {
+		return true
+	}
+	for _, author := range authors {
+		if commit.IsAuthoredBy(author) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// areAllUnborn tells whether none of the targets has any commit yet
+func areAllUnborn(targets []string) bool {
+	for _, target := range targets {
+		if !reader.IsUnborn(target) {
+			return false
+		}
+	}
+
+	return true
+}
 
-	return gitime.CollectTimeSpent(gitLog), nil
+func collectTarget(collector *gitime.Collector, target string) (*gitime.Collection, error) {
+	corrections, warnings, err := readCorrections(target)
+	if err != nil {
+		return nil, err
+	}
+	collector.Corrections = corrections
+	collector.Rates, err = readRates(target)
+	if err != nil {
+		return nil, err
+	}
+	collector.Repository = targetName(target)
+	collector.Changes = reader.NewChangesReader(target).Read
+	gitime.CommentChar = reader.ReadCommentChar(target)
+	commits, skipped, err := reader.ReadGitLogCommitsCounting(FlagAuthors, FlagNoMerges, FlagSince, FlagUntil, target)
+	if err != nil {
+		return nil, err
+	}
+	if FlagIncludeMrDescriptions {
+		described, mrWarnings := mergeRequestCommits(target, commits)
+		for _, commit := range described {
+			if !isAuthoredByAny(commit, FlagAuthors) {
+				skipped[gitime.SkippedAuthor]++
+				continue
+			}
+			commits = append(commits, commit)
+		}
+		warnings = append(warnings, mrWarnings...)
+	}
+	commits = excludeAuthors(commits, skipped)
+	collection := collector.Collect(commits)
+	collection.Counts.Filtered(skipped)
+	collection.Warnings = append(warnings, collection.Warnings...)
+
+	return collection, nil
+}
+
+// readRates reads the rates of the labels in the repository config of the target, if any
+func readRates(target string) (gitime.Rates, error) {
+	config, err := reader.ReadRepositoryConfig(target)
+	if err != nil || config == nil {
+		return nil, err
+	}
+
+	return gitime.ParseRates(config.GetStringMapString("rates"))
+}
+
+// readCorrections reads the corrections of the repository of the target,
+// and warns about the corrections of commits the repository does not know.
+func readCorrections(target string) ([]*gitime.Correction, []*gitime.Warning, error) {
+	warnings := make([]*gitime.Warning, 0)
+	values, err := reader.ReadCorrections(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	corrections, err := gitime.ParseCorrections(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, 0, len(corrections))
+	for _, correction := range corrections {
+		hashes = append(hashes, correction.Hash)
+	}
+	resolution, err := reader.ResolveCommits(hashes, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, hash := range resolution.Ambiguous {
+		warnings = append(warnings, &gitime.Warning{
+			Code:    gitime.CodeCorrectionAmbiguous,
+			Hash:    hash,
+			Message: locale.Tf("WarningCorrectionAmbiguous", hash),
+		})
+	}
+	for _, hash := range resolution.Unknown {
+		warnings = append(warnings, &gitime.Warning{
+			Code:    gitime.CodeCorrectionUnknown,
+			Hash:    hash,
+			Message: locale.Tf("WarningCorrectionUnknown", hash),
+		})
+	}
+
+	return gitime.ResolveCorrections(corrections, resolution.Commits), warnings, nil
+}
+
+// newCollector configures a collector from the flags and the configuration
+func newCollector() (*gitime.Collector, error) {
+	collector := &gitime.Collector{
+		EnforceMax: FlagEnforceMax,
+		Dedupe:     FlagDedupe,
+		GroupBy:    FlagGroupBy,
+	}
+
+	if FlagGroupBy != gitime.GroupByNone && !isSupported(FlagGroupBy, gitime.SupportedGroupings) {
+		return nil, fmt.Errorf(locale.Tf(
+			"CommandSumFailureGroupBy",
+			FlagGroupBy,
+			strings.Join(gitime.SupportedGroupings, ", "),
+		))
+	}
+
+	if FlagSplit != "" && FlagGroupBy != gitime.GroupByDirectory && FlagGroupBy != gitime.GroupByFile {
+		return nil, fmt.Errorf(locale.T("CommandSumFailureSplitGroupBy"))
+	}
+	collector.Split = gitime.SplitLines
+	if FlagSplit != "" {
+		if !isSupported(FlagSplit, gitime.SupportedSplits) {
+			return nil, fmt.Errorf(locale.Tf(
+				"CommandSumFailureSplit",
+				FlagSplit,
+				strings.Join(gitime.SupportedSplits, ", "),
+			))
+		}
+		collector.Split = FlagSplit
+	}
+
+	if FlagDedupe != gitime.DedupeNone && !isSupported(FlagDedupe, gitime.SupportedDedupeStrategies) {
+		return nil, fmt.Errorf(locale.Tf(
+			"CommandSumFailureDedupe",
+			FlagDedupe,
+			strings.Join(gitime.SupportedDedupeStrategies, ", "),
+		))
+	}
+
+	linter, err := newLinter(FlagCheckPolicy)
+	if err != nil {
+		return nil, err
+	}
+	collector.Linter = linter
+
+	forge, err := newForge()
+	if err != nil {
+		return nil, err
+	}
+	collector.Forge = forge
+
+	nonWorkingDays, err := newNonWorkingDays()
+	if err != nil {
+		return nil, err
+	}
+	collector.NonWorkingDays = nonWorkingDays
+	collector.ExcludeFlagged = FlagExcludeFlagged
+
+	maxDirective := getFlagOrConfigString(FlagMaxDirective, "max_directive")
+	if maxDirective != "" {
+		ts, err := gitime.ParseTimeSpent(maxDirective)
+		if err != nil {
+			return nil, err
+		}
+		collector.MaxDirective = ts
+	}
+
+	return collector, nil
+}
+
+// newForge configures the forge from the flags and the configuration,
+// or else from the origin remote of the (first) target
+func newForge() (*gitime.Forge, error) {
+	name := getFlagOrConfigString(FlagForge, "forge")
+	issueRegex := getFlagOrConfigString(FlagIssueRegex, "issue_regex")
+	detected := false
+	if name == "" && FlagGitlabGroup != "" {
+		name = gitime.ForgeGitlab
+	}
+	if name == "" && issueRegex == "" && !FlagStdin && len(FlagTargets) > 0 {
+		name = gitime.DetectForge(reader.ReadRemoteURL(FlagTargets[0], "origin"))
+		detected = name != ""
+	}
+	if name == "" && issueRegex != "" {
+		name = gitime.ForgeCustom
+	}
+
+	forge, err := gitime.NewForge(name, issueRegex)
+	if err != nil {
+		return nil, err
+	}
+	if FlagVerbose && detected {
+		printInfo(locale.Tf("CommandSumForgeDetected", forge.Name))
+	} else if FlagVerbose {
+		printInfo(locale.Tf("CommandSumForge", forge.Name))
+	}
+
+	return forge, nil
 }
 
 func addFormatFlags(command *cobra.Command) {
@@ -144,12 +953,33 @@ func addFormatFlags(command *cobra.Command) {
 		locale.Tf("CommandSumFlagMonthsHelp", gitime.WeeksInOneMonth),
 	)
 
+	command.Flags().StringVar(
+		&FlagUnit,
+		"unit",
+		"",
+		locale.Tf("CommandSumFlagUnitHelp", strings.Join(gitime.SupportedUnits, "|")),
+	)
+	command.Flags().BoolVar(
+		&FlagCompact,
+		"compact",
+		false,
+		locale.T("CommandSumFlagCompactHelp"),
+	)
+	command.Flags().IntVar(
+		&FlagMaxWidth,
+		"max-width",
+		0,
+		locale.T("CommandSumFlagMaxWidthHelp"),
+	)
+
 	command.MarkFlagsMutuallyExclusive(
+		"unit",
 		"months",
 		"weeks",
 		"days",
 		"hours",
 		"minutes",
+		"max-width",
 	)
 }
 
@@ -160,6 +990,49 @@ func addFilterFlags(command *cobra.Command) {
 		[]string{},
 		locale.T("CommandSumFlagAuthorsHelp"),
 	)
+	command.Flags().StringVar(
+		&FlagTeamFile,
+		"team-file",
+		"",
+		locale.T("CommandSumFlagTeamFileHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagNotInTeam,
+		"not-in-team",
+		false,
+		locale.T("CommandSumFlagNotInTeamHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagPrintTeam,
+		"print-team",
+		false,
+		locale.T("CommandSumFlagPrintTeamHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagFoldAccents,
+		"fold-accents",
+		false,
+		locale.T("CommandSumFlagFoldAccentsHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagCaseSensitiveEmails,
+		"case-sensitive-emails",
+		false,
+		locale.T("CommandSumFlagCaseSensitiveEmailsHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagWordNumbers,
+		"word-numbers",
+		"",
+		locale.Tf("CommandSumFlagWordNumbersHelp", strings.Join(gitime.SupportedNumberWordsLanguages, "|")),
+	)
+	command.Flags().Lookup("word-numbers").NoOptDefVal = "en"
+	command.Flags().StringVar(
+		&FlagRangePolicy,
+		"range-policy",
+		"",
+		locale.Tf("CommandSumFlagRangePolicyHelp", strings.Join(gitime.SupportedRangePolicies, "|")),
+	)
 	command.Flags().BoolVar(
 		&FlagNoMerges,
 		"no-merges",
@@ -178,14 +1051,163 @@ func addFilterFlags(command *cobra.Command) {
 		"",
 		locale.T("CommandSumFlagUntilHelp"),
 	)
+	command.Flags().StringVar(
+		&FlagLast,
+		"last",
+		"",
+		locale.T("CommandSumFlagLastHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagThis,
+		"this",
+		"",
+		locale.T("CommandSumFlagThisHelp"),
+	)
+
+	command.MarkFlagsMutuallyExclusive("last", "this", "since")
+	command.MarkFlagsMutuallyExclusive("last", "this", "until")
 }
 
-func addTargetFlags(command *cobra.Command) {
+func addOutputFlags(command *cobra.Command) {
+	command.Flags().StringVar(
+		&FlagFormat,
+		"format",
+		FormatText,
+		locale.T("CommandSumFlagFormatHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagGroupBy,
+		"group-by",
+		gitime.GroupByNone,
+		locale.Tf("CommandSumFlagGroupByHelp", strings.Join(gitime.SupportedGroupings, "|")),
+	)
+	command.Flags().StringVar(
+		&FlagSplit,
+		"split",
+		"",
+		locale.Tf("CommandSumFlagSplitHelp", strings.Join(gitime.SupportedSplits, "|")),
+	)
+	command.Flags().StringVar(
+		&FlagForge,
+		"forge",
+		"",
+		locale.Tf("CommandSumFlagForgeHelp", strings.Join(gitime.SupportedForges, "|")),
+	)
 	command.Flags().StringVar(
-		&FlagTarget,
+		&FlagIssueRegex,
+		"issue-regex",
+		"",
+		locale.T("CommandSumFlagIssueRegexHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagWithSpan,
+		"with-span",
+		false,
+		locale.T("CommandSumFlagWithSpanHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagLogRuns,
+		"log-runs",
+		false,
+		locale.T("CommandSumFlagLogRunsHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagNoHeader,
+		"no-header",
+		false,
+		locale.T("CommandSumFlagNoHeaderHelp"),
+	)
+	addCountsFlag(command)
+	command.Flags().BoolVarP(
+		&FlagVerbose,
+		"verbose",
+		"v",
+		false,
+		locale.T("CommandSumFlagVerboseHelp"),
+	)
+}
+
+func addCountsFlag(command *cobra.Command) {
+	command.Flags().BoolVar(
+		&FlagCounts,
+		"counts",
+		false,
+		locale.T("CommandSumFlagCountsHelp"),
+	)
+}
+
+func addSanityFlags(command *cobra.Command) {
+	command.Flags().StringVar(
+		&FlagMaxDirective,
+		"max-directive",
+		"",
+		locale.T("CommandSumFlagMaxDirectiveHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagEnforceMax,
+		"enforce-max",
+		false,
+		locale.T("CommandSumFlagEnforceMaxHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagDedupe,
+		"dedupe",
+		gitime.DedupeNone,
+		locale.T("CommandSumFlagDedupeHelp"),
+	)
+}
+
+// newLinter configures a linter from the flags and the configuration.
+// The policies (like single-directive) are only enforced when asked for.
+func newLinter(withPolicies bool) (*gitime.Linter, error) {
+	linter := &gitime.Linter{}
+	if withPolicies {
+		err := linter.ParsePolicies(getFlagOrConfigString(FlagPolicy, "policy"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	minGranularity := getFlagOrConfigString(FlagMinGranularity, "min_granularity")
+	if minGranularity != "" {
+		ts, err := gitime.ParseTimeSpent(minGranularity)
+		if err != nil {
+			return nil, err
+		}
+		linter.MinGranularity = ts
+	}
+	linter.RejectAmbiguousM = gitime.AmbiguousM == gitime.AmbiguousReject
+
+	return linter, nil
+}
+
+func addPolicyFlags(command *cobra.Command) {
+	command.Flags().StringVar(
+		&FlagMinGranularity,
+		"min-granularity",
+		"",
+		locale.T("CommandSumFlagMinGranularityHelp"),
+	)
+	command.Flags().StringVar(
+		&FlagPolicy,
+		"policy",
+		"",
+		locale.Tf("CommandSumFlagPolicyHelp", strings.Join(gitime.SupportedPolicies, ", ")),
+	)
+}
+
+func addTargetFlags(command *cobra.Command) {
+	command.Flags().StringArrayVar(
+		&FlagTargets,
 		"target",
-		FlagTargetDefault,
-		locale.T("CommandSumFlagTargetHelp"),
+		[]string{FlagTargetDefault},
+		locale.T("CommandSumFlagTargetsHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagFailIfEmpty,
+		"fail-if-empty",
+		false,
+		locale.T("CommandSumFlagFailIfEmptyHelp"),
 	)
 	command.Flags().BoolVar(
 		&FlagStdin,
@@ -193,12 +1215,51 @@ func addTargetFlags(command *cobra.Command) {
 		false,
 		locale.T("CommandSumFlagStdinHelp"),
 	)
+	command.Flags().BoolVar(
+		&FlagStdinCommits,
+		"stdin-commits",
+		false,
+		locale.T("CommandSumFlagStdinCommitsHelp"),
+	)
+	command.Flags().BoolVar(
+		&FlagIgnoreMissing,
+		"ignore-missing",
+		false,
+		locale.T("CommandSumFlagIgnoreMissingHelp"),
+	)
+	command.Flags().IntVar(
+		&FlagExpect,
+		"expect",
+		-1,
+		locale.T("CommandSumFlagExpectHelp"),
+	)
+
+	command.MarkFlagsMutuallyExclusive("stdin", "stdin-commits")
 }
 
 func init() {
 	rootCmd.AddCommand(sumCmd)
 	sumCmd.Flags().SortFlags = false
 	addTargetFlags(sumCmd)
+	addGitlabFlags(sumCmd)
 	addFilterFlags(sumCmd)
 	addFormatFlags(sumCmd)
+	addShowRawFlag(sumCmd)
+	addOutputFlags(sumCmd)
+	addSanityFlags(sumCmd)
+	addPolicyFlags(sumCmd)
+	addFlaggingFlags(sumCmd)
+	sumCmd.Flags().BoolVar(
+		&FlagCheckPolicy,
+		"check-policy",
+		false,
+		locale.T("CommandSumFlagCheckPolicyHelp"),
+	)
+	sumCmd.Flags().BoolVar(
+		&FlagStrict,
+		"strict",
+		false,
+		locale.T("CommandSumFlagStrictHelp"),
+	)
+	addDiagnosticFlags(sumCmd)
 }
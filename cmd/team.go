@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"os"
+	"strings"
+)
+
+var (
+	FlagTeamFile  string
+	FlagNotInTeam bool
+	FlagPrintTeam bool
+)
+
+// excludedAuthors are the identities whose commits are skipped, like the members of the team with --not-in-team
+var excludedAuthors []string
+
+// applyTeam reads the team file, resolves its members through the mailmaps of the targets,
+// and restricts the authors to the members, or excludes them with --not-in-team.
+// With --print-team, it prints the members and their identities, and exits.
+func applyTeam(targets []string) error {
+	excludedAuthors = nil
+	if FlagTeamFile == "" {
+		if FlagNotInTeam || FlagPrintTeam {
+			return fmt.Errorf(locale.T("CommandSumFailureTeamFileMissing"))
+		}
+		return nil
+	}
+	if len(FlagAuthors) > 0 {
+		return fmt.Errorf(locale.T("CommandSumFailureTeamAuthors"))
+	}
+	content, err := os.ReadFile(FlagTeamFile)
+	if err != nil {
+		return fmt.Errorf(locale.Tf("CommandSumFailureTeamFile", FlagTeamFile, err.Error()))
+	}
+	members := gitime.ParseTeam(string(content))
+	if len(members) == 0 {
+		return fmt.Errorf(locale.Tf("CommandSumFailureTeamEmpty", FlagTeamFile))
+	}
+	mailmap := make([]*gitime.MailmapEntry, 0)
+	for _, target := range targets {
+		entries, err := reader.ReadMailmap(target)
+		if err != nil {
+			return err
+		}
+		mailmap = append(mailmap, entries...)
+	}
+	gitime.ResolveTeam(members, mailmap)
+
+	if FlagPrintTeam {
+		for _, member := range members {
+			fmt.Println(locale.Tf("CommandSumTeamMember", member.Entry, strings.Join(member.Identities, ", ")))
+		}
+		os.Exit(0)
+	}
+	if FlagNotInTeam {
+		excludedAuthors = gitime.TeamIdentities(members)
+	} else {
+		FlagAuthors = gitime.TeamIdentities(members)
+	}
+
+	return nil
+}
+
+// excludeAuthors returns the commits that are not by the excluded authors, and counts the others as skipped
+func excludeAuthors(commits []*gitime.Commit, skipped map[string]int) []*gitime.Commit {
+	if len(excludedAuthors) == 0 {
+		return commits
+	}
+	kept := make([]*gitime.Commit, 0, len(commits))
+	for _, commit := range commits {
+		if isAuthoredByAny(commit, excludedAuthors) {
+			skipped[gitime.SkippedAuthor]++
+			continue
+		}
+		kept = append(kept, commit)
+	}
+
+	return kept
+}
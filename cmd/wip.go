@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/reader"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/cobra"
+	"reflect"
+)
+
+// pendingMessageFiles are the files of the git directory that may hold a message not committed yet
+var pendingMessageFiles = []string{"COMMIT_EDITMSG", "MERGE_MSG"}
+
+var wipCmd = &cobra.Command{
+	Use:               "wip",
+	Short:             locale.T("CommandWipSummary"),
+	Long:              locale.T("CommandWipDescription"),
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Annotations:       map[string]string{annotationGit: "required"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if reader.ReadGitDir(FlagTarget) == "" {
+			fail(locale.Tf("CommandWipNotARepository", FlagTarget), cmd)
+		}
+		gitime.CommentChar = reader.ReadCommentChar(FlagTarget)
+		found := false
+
+		headDirectives := directiveLines("")
+		if head, err := reader.ReadGitCommit("HEAD", FlagTarget); err == nil {
+			headDirectives = directiveLines(head.Message)
+		}
+		for _, name := range pendingMessageFiles {
+			message := reader.ReadGitDirFile(FlagTarget, name)
+			ts := gitime.CollectTimeSpent(message)
+			// COMMIT_EDITMSG stays around after committing, so it only matters when it differs from HEAD
+			if ts.IsZero() || reflect.DeepEqual(directiveLines(message), headDirectives) {
+				continue
+			}
+			found = true
+			fmt.Println(locale.Tf("CommandWipMessage", name, ts.Normalize().String()))
+		}
+
+		for _, stash := range reader.ReadStashes(FlagTarget) {
+			ts := gitime.CollectTimeSpent(stash.Message)
+			if ts.IsZero() {
+				continue
+			}
+			found = true
+			subject := (&gitime.Commit{Message: stash.Message}).Subject()
+			fmt.Println(locale.Tf("CommandWipStash", stash.Ref, ts.Normalize().String(), subject))
+		}
+
+		unpushed, err := reader.ReadUnpushed(FlagTarget)
+		if err != nil {
+			fail(err, cmd)
+		}
+		for _, branch := range unpushed {
+			ts := &gitime.TimeSpent{}
+			commits := 0
+			for _, commit := range branch.Commits {
+				spent := gitime.CollectTimeSpent(commit.Message)
+				if !spent.IsZero() {
+					ts.Add(spent)
+					commits++
+				}
+			}
+			if commits == 0 {
+				continue
+			}
+			found = true
+			key := "CommandWipUnpushedPlural"
+			if commits == 1 {
+				key = "CommandWipUnpushedSingular"
+			}
+			if branch.Upstream == "" {
+				fmt.Println(locale.Tf(key+"NoUpstream", branch.Branch, ts.Normalize().String(), commits))
+				continue
+			}
+			fmt.Println(locale.Tf(key, branch.Branch, ts.Normalize().String(), commits, branch.Upstream))
+		}
+
+		if !found {
+			fmt.Println(locale.T("CommandWipNothing"))
+		}
+	},
+}
+
+// directiveLines returns the lines of the directives of the message
+func directiveLines(message string) []string {
+	lines := make([]string, 0)
+	for _, directive := range gitime.CollectDirectives(message) {
+		lines = append(lines, directive.Line)
+	}
+
+	return lines
+}
+
+func init() {
+	rootCmd.AddCommand(wipCmd)
+	wipCmd.Flags().StringVar(
+		&FlagTarget,
+		"target",
+		FlagTargetDefault,
+		locale.T("CommandSumFlagTargetHelp"),
+	)
+}
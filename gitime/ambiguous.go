@@ -0,0 +1,37 @@
+package gitime
+
+// Readings of the bare "m" unit, like in "/spend 3m", which GitLab reads as minutes and some people as months
+const (
+	AmbiguousMinutes = "minutes"
+	AmbiguousMonths  = "months"
+	AmbiguousReject  = "reject"
+)
+
+// SupportedAmbiguousM lists the readings accepted for AmbiguousM
+var SupportedAmbiguousM = []string{AmbiguousMinutes, AmbiguousMonths, AmbiguousReject}
+
+// AmbiguousM is how the bare "m" unit is read in directives.  AmbiguousReject still reads it as minutes,
+// like GitLab does, and leaves it to the linter to reject, so that teams may require "min" or "mo" instead.
+var AmbiguousM = AmbiguousMinutes
+
+// bareM is the spelling of the unit that AmbiguousM disambiguates
+const bareM = "m"
+
+// bareMReading returns how the bare "m" unit is read, AmbiguousMinutes or AmbiguousMonths
+func bareMReading() string {
+	if AmbiguousM == AmbiguousMonths {
+		return AmbiguousMonths
+	}
+
+	return AmbiguousMinutes
+}
+
+// minutesAbbreviation returns the abbreviation of minutes in the compact format,
+// which is only the bare "m" when it is read as minutes without complaint.
+func minutesAbbreviation() string {
+	if AmbiguousM == AmbiguousMinutes {
+		return minutesSpelling.Abbreviation
+	}
+
+	return "min"
+}
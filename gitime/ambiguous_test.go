@@ -0,0 +1,69 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCollectDirectivesWithAmbiguousM(t *testing.T) {
+	defer func() { AmbiguousM = AmbiguousMinutes }()
+	month := uint64(4 * 5 * 8 * 60)
+	tests := []struct {
+		line       string
+		reading    string
+		expected   uint64
+		ambiguousM string
+	}{
+		{"/spend 3m", AmbiguousMinutes, 3, AmbiguousMinutes},
+		{"/spend 3m", AmbiguousMonths, 3 * month, AmbiguousMonths},
+		{"/spend 3m", AmbiguousReject, 3, AmbiguousMinutes},
+		{"/spend 1h 30m", AmbiguousMonths, 60 + 30*month, AmbiguousMonths},
+		{"/spend 3min", AmbiguousMonths, 3, ""},
+		{"/spend 3mo", AmbiguousMinutes, 3 * month, ""},
+		{"/spend 30", AmbiguousMonths, 30, ""},
+		{"/spend 1-2m", AmbiguousMonths, month + month/2, AmbiguousMonths},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line+" "+tt.reading, func(t *testing.T) {
+			AmbiguousM = tt.reading
+			directives := CollectDirectives(tt.line)
+			if assert.Len(t, directives, 1) {
+				assert.Equal(t, tt.expected, directives[0].TimeSpent.ToMinutes())
+				assert.Equal(t, tt.ambiguousM, directives[0].AmbiguousM)
+			}
+		})
+	}
+}
+
+func TestCollectDirectivesWithAmbiguousMInWords(t *testing.T) {
+	defer func() { AmbiguousM, WordNumbers = AmbiguousMinutes, nil }()
+	AmbiguousM, WordNumbers = AmbiguousMonths, EnglishNumberWords
+	directives := CollectDirectives("/spend two m")
+	if assert.Len(t, directives, 1) {
+		assert.Equal(t, 2.0, directives[0].TimeSpent.Months)
+		assert.Equal(t, AmbiguousMonths, directives[0].AmbiguousM)
+	}
+}
+
+func TestLinter_LintMessageAmbiguousM(t *testing.T) {
+	defer func() { AmbiguousM = AmbiguousMinutes }()
+	AmbiguousM = AmbiguousReject
+	linter := &Linter{RejectAmbiguousM: true}
+	assert.Equal(t, []string{CodeAmbiguousM}, codes(linter.LintMessage("/spend 1h 30m")))
+	assert.Empty(t, linter.LintMessage("/spend 1h 30min\n/spend 2mo"))
+}
+
+func TestTimeSpent_CompactComponentsAvoidAmbiguousM(t *testing.T) {
+	defer func() { AmbiguousM = AmbiguousMinutes }()
+	ts := &TimeSpent{Months: 1, Minutes: 20}
+	for _, reading := range SupportedAmbiguousM {
+		AmbiguousM = reading
+		parsed, err := ParseTimeSpent(strings.Join(ts.CompactComponents(), " "))
+		if assert.NoError(t, err, reading) {
+			assert.Equal(t, ts, parsed, reading)
+		}
+	}
+	AmbiguousM = AmbiguousMonths
+	assert.Equal(t, []string{"1mo", "20min"}, ts.CompactComponents())
+}
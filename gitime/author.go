@@ -0,0 +1,60 @@
+package gitime
+
+import (
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// FoldAccents makes author names match regardless of their diacritics, like "Édouard" and "Edouard".
+// Author names always match regardless of their case.
+var FoldAccents = false
+
+// CaseSensitiveEmails makes the case of the local part of emails (before the @) significant.
+// The case of the domain never is.
+var CaseSensitiveEmails = false
+
+// FoldAuthorName returns the name the way it is compared to other names
+func FoldAuthorName(name string) string {
+	if FoldAccents {
+		name = stripDiacritics(name)
+	}
+
+	return strings.ToLower(name)
+}
+
+// FoldAuthorEmail returns the email the way it is compared to other emails
+func FoldAuthorEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return FoldAuthorName(email)
+	}
+	local, domain := email[:at], email[at:]
+	if !CaseSensitiveEmails {
+		local = strings.ToLower(local)
+	}
+
+	return local + strings.ToLower(domain)
+}
+
+// IsAuthoredBy tells whether the author, a name or an email, designates the author of the commit
+func (c *Commit) IsAuthoredBy(author string) bool {
+	if c.AuthorName != "" && FoldAuthorName(c.AuthorName) == FoldAuthorName(author) {
+		return true
+	}
+
+	return c.AuthorEmail != "" && FoldAuthorEmail(c.AuthorEmail) == FoldAuthorEmail(author)
+}
+
+// stripDiacritics decomposes the characters (NFD), removes their combining marks, and recomposes them (NFC)
+func stripDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	out, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+
+	return out
+}
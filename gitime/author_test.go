@@ -0,0 +1,42 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCommit_IsAuthoredByIgnoresCase(t *testing.T) {
+	commit := &Commit{AuthorName: "Durand", AuthorEmail: "Paul.Durand@Example.ORG"}
+	assert.True(t, commit.IsAuthoredBy("durand"))
+	assert.True(t, commit.IsAuthoredBy("DURAND"))
+	assert.True(t, commit.IsAuthoredBy("paul.durand@example.org"))
+	assert.False(t, commit.IsAuthoredBy("Dupont"))
+	assert.False(t, commit.IsAuthoredBy(""))
+}
+
+func TestCommit_IsAuthoredByWithCaseSensitiveEmails(t *testing.T) {
+	defer func() { CaseSensitiveEmails = false }()
+	CaseSensitiveEmails = true
+	commit := &Commit{AuthorEmail: "Paul.Durand@Example.ORG"}
+	assert.True(t, commit.IsAuthoredBy("Paul.Durand@example.org"))
+	assert.False(t, commit.IsAuthoredBy("paul.durand@example.org"))
+}
+
+func TestCommit_IsAuthoredByWithFoldedAccents(t *testing.T) {
+	commit := &Commit{AuthorName: "Édouard"}
+	assert.False(t, commit.IsAuthoredBy("Edouard"))
+
+	defer func() { FoldAccents = false }()
+	FoldAccents = true
+	assert.True(t, commit.IsAuthoredBy("Edouard"))
+	assert.True(t, commit.IsAuthoredBy("édouard"))
+	// Decomposed, with a combining acute accent
+	assert.True(t, commit.IsAuthoredBy("E\u0301douard"))
+}
+
+func TestFoldAuthorName(t *testing.T) {
+	defer func() { FoldAccents = false }()
+	FoldAccents = true
+	assert.Equal(t, "francois muller", FoldAuthorName("François Müller"))
+	assert.Equal(t, "søren", FoldAuthorName("Søren"))
+}
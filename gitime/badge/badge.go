@@ -0,0 +1,120 @@
+package badge
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"math"
+	"text/template"
+)
+
+// Named colors, as understood by shields.io, and their hexadecimal counterparts for our SVG.
+const (
+	ColorRed    = "red"
+	ColorYellow = "yellow"
+	ColorGreen  = "green"
+)
+
+var hexColors = map[string]string{
+	ColorRed:    "#e05d44",
+	ColorYellow: "#dfb317",
+	ColorGreen:  "#4c1",
+}
+
+// Badge is a shields.io-style flat badge, like "time-logged | 87%"
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// ForCoverage creates a badge displaying the coverage (between 0 and 1) as a percentage.
+// The badge is red below the yellow threshold, yellow below the green threshold, and green otherwise.
+// Thresholds are percentages.
+func ForCoverage(label string, coverage float64, yellowThreshold float64, greenThreshold float64) *Badge {
+	percent := math.Floor(coverage * 100.0)
+	color := ColorGreen
+	if percent < greenThreshold {
+		color = ColorYellow
+	}
+	if percent < yellowThreshold {
+		color = ColorRed
+	}
+
+	return &Badge{
+		Label:   label,
+		Message: fmt.Sprintf("%d%%", int64(percent)),
+		Color:   color,
+	}
+}
+
+// endpoint follows the schema of https://shields.io/badges/endpoint-badge
+type endpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// JSON renders the badge for the shields.io endpoint, for use with their dynamic badge service
+func (b *Badge) JSON() ([]byte, error) {
+	return json.MarshalIndent(&endpoint{
+		SchemaVersion: 1,
+		Label:         b.Label,
+		Message:       b.Message,
+		Color:         b.Color,
+	}, "", "  ")
+}
+
+// assetsFS holds the templates of the badges, embedded so that the binary works from any directory
+//
+//go:embed *.tmpl
+var assetsFS embed.FS
+
+// Assets returns the embedded templates of the badges
+func Assets() fs.FS {
+	return assetsFS
+}
+
+var svgTemplate = template.Must(template.ParseFS(assetsFS, "badge.svg.tmpl"))
+
+// SVG renders the badge as a flat SVG image
+func (b *Badge) SVG() ([]byte, error) {
+	labelWidth := textWidth(b.Label)
+	messageWidth := textWidth(b.Message)
+	color, isNamed := hexColors[b.Color]
+	if !isNamed {
+		color = b.Color
+	}
+
+	var out bytes.Buffer
+	err := svgTemplate.Execute(&out, map[string]any{
+		"Label":        escapeXML(b.Label),
+		"Message":      escapeXML(b.Message),
+		"Color":        escapeXML(color),
+		"Width":        labelWidth + messageWidth,
+		"LabelWidth":   labelWidth,
+		"MessageWidth": messageWidth,
+		"LabelX":       float64(labelWidth) / 2.0,
+		"MessageX":     float64(labelWidth) + float64(messageWidth)/2.0,
+	})
+
+	return out.Bytes(), err
+}
+
+// escapeXML escapes the text for the SVG, so that labels like "R&D <team>" are not read as markup
+func escapeXML(text string) string {
+	var out bytes.Buffer
+	_ = xml.EscapeText(&out, []byte(text))
+
+	return out.String()
+}
+
+// textWidth approximates the width in pixels of the text in Verdana 11px, padding included.
+// We do not ship font metrics, so this is a tad wider than what shields.io would yield.
+func textWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
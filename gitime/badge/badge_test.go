@@ -0,0 +1,56 @@
+package badge
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestForCoverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		coverage float64
+		message  string
+		color    string
+	}{
+		{"nothing", 0.0, "0%", ColorRed},
+		{"below yellow", 0.499, "49%", ColorRed},
+		{"at yellow", 0.5, "50%", ColorYellow},
+		{"below green", 0.87, "87%", ColorYellow},
+		{"at green", 0.9, "90%", ColorGreen},
+		{"everything", 1.0, "100%", ColorGreen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := ForCoverage("time-logged", tt.coverage, 50, 90)
+			assert.Equal(t, tt.message, b.Message)
+			assert.Equal(t, tt.color, b.Color)
+		})
+	}
+}
+
+func TestBadge_JSON(t *testing.T) {
+	b := ForCoverage("time-logged", 0.87, 50, 80)
+	out, err := b.JSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"schemaVersion":1,"label":"time-logged","message":"87%","color":"green"}`, string(out))
+}
+
+func TestBadge_SVG(t *testing.T) {
+	b := ForCoverage("time-logged", 0.87, 50, 80)
+	out, err := b.SVG()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `aria-label="time-logged: 87%"`)
+	assert.Contains(t, string(out), `fill="#4c1"`)
+}
+
+func TestBadge_SVGEscapesText(t *testing.T) {
+	b := &Badge{Label: "R&D <team>", Message: `"87%"`, Color: ColorGreen}
+	out, err := b.SVG()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `aria-label="R&amp;D &lt;team&gt;: &#34;87%&#34;"`)
+	assert.Contains(t, string(out), `>R&amp;D &lt;team&gt;</text>`)
+	assert.NotContains(t, string(out), "<team>")
+	require.NoError(t, xml.Unmarshal(out, new(struct{})), "the SVG is well-formed XML")
+}
@@ -0,0 +1,148 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+/*
+
+Calendar configuration, used to align windows of time on weeks, months and quarters.
+
+	GIT_SPEND_WEEK_START=sunday GIT_SPEND_TIMEZONE=America/Chicago git-spend sum --last week
+	GIT_SPEND_WORKING_DAYS=sunday,monday,tuesday,wednesday,thursday git-spend sum --flag-weekend-spend
+
+*/
+
+const (
+	DefaultWeekStart = time.Monday
+)
+
+// DefaultWorkingDays are the days of the week on which time is spent, the others being the weekend
+var DefaultWorkingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+var (
+	WeekStart   = DefaultWeekStart
+	WorkingDays = DefaultWorkingDays
+	Location    = time.Local
+)
+
+// Periods of the calendar, to which windows may be aligned
+const (
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+)
+
+// SupportedPeriods lists the periods accepted by CurrentPeriod and PreviousPeriod
+var SupportedPeriods = []string{PeriodWeek, PeriodMonth, PeriodQuarter}
+
+// Window is a span of time from Start (inclusive) to End (exclusive)
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// String shows the absolute boundaries of the window, for humans
+func (w *Window) String() string {
+	return fmt.Sprintf(
+		"%s → %s (%s)",
+		w.Start.Format(time.DateTime),
+		w.End.Format(time.DateTime),
+		w.Start.Location().String(),
+	)
+}
+
+// UpdateCalendarConfiguration must be ran AFTER viper has loaded the config file and env
+func UpdateCalendarConfiguration() error {
+	WeekStart = DefaultWeekStart
+	WorkingDays = DefaultWorkingDays
+	Location = time.Local
+
+	weekStart := strings.ToLower(viper.GetString("week_start"))
+	if weekStart != "" {
+		day, found := parseWeekday(weekStart)
+		if !found {
+			return fmt.Errorf(locale.Tf("CalendarWeekStartUnsupported", weekStart))
+		}
+		WeekStart = day
+	}
+
+	// Like "monday,tuesday" from the environment, or a list in the config file
+	workingDays := strings.FieldsFunc(
+		strings.ToLower(strings.Join(viper.GetStringSlice("working_days"), ",")),
+		func(r rune) bool { return r == ',' || r == ' ' },
+	)
+	if len(workingDays) > 0 {
+		WorkingDays = make([]time.Weekday, 0, len(workingDays))
+		for _, name := range workingDays {
+			day, found := parseWeekday(name)
+			if !found {
+				return fmt.Errorf(locale.Tf("CalendarWorkingDayUnsupported", name))
+			}
+			WorkingDays = append(WorkingDays, day)
+		}
+	}
+
+	timezone := viper.GetString("timezone")
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return err
+		}
+		Location = location
+	}
+
+	return nil
+}
+
+// parseWeekday reads the day of the week of the (lowercase, english) name, like monday
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToLower(day.String()) == name {
+			return day, true
+		}
+	}
+
+	return time.Sunday, false
+}
+
+// CurrentPeriod returns the window of the (incomplete) period holding now
+func CurrentPeriod(period string, now time.Time) (*Window, error) {
+	now = now.In(Location)
+	y, m, d := now.Date()
+	switch period {
+	case PeriodWeek:
+		offset := (int(now.Weekday()) - int(WeekStart) + 7) % 7
+		start := time.Date(y, m, d-offset, 0, 0, 0, 0, Location)
+		return &Window{Start: start, End: start.AddDate(0, 0, 7)}, nil
+	case PeriodMonth:
+		start := time.Date(y, m, 1, 0, 0, 0, 0, Location)
+		return &Window{Start: start, End: start.AddDate(0, 1, 0)}, nil
+	case PeriodQuarter:
+		start := time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, Location)
+		return &Window{Start: start, End: start.AddDate(0, 3, 0)}, nil
+	}
+
+	return nil, fmt.Errorf(locale.Tf("CalendarPeriodUnsupported", period, strings.Join(SupportedPeriods, ", ")))
+}
+
+// PreviousPeriod returns the window of the last complete period before now
+func PreviousPeriod(period string, now time.Time) (*Window, error) {
+	current, err := CurrentPeriod(period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The day before the current period starts belongs to the previous period
+	return CurrentPeriod(period, current.Start.AddDate(0, 0, -1))
+}
+
+func init() {
+	viper.SetDefault("week_start", strings.ToLower(DefaultWeekStart.String()))
+	viper.SetDefault("working_days", "")
+	viper.SetDefault("timezone", "")
+}
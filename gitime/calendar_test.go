@@ -0,0 +1,85 @@
+package gitime
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPeriods(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	Location = paris
+	defer func() { Location = time.Local; WeekStart = DefaultWeekStart }()
+
+	// A Wednesday
+	now := time.Date(2024, 3, 13, 15, 30, 0, 0, paris)
+
+	tests := []struct {
+		name      string
+		weekStart time.Weekday
+		previous  bool
+		period    string
+		start     string
+		end       string
+	}{
+		{"this week", time.Monday, false, PeriodWeek, "2024-03-11", "2024-03-18"},
+		{"last week", time.Monday, true, PeriodWeek, "2024-03-04", "2024-03-11"},
+		{"this week starting sunday", time.Sunday, false, PeriodWeek, "2024-03-10", "2024-03-17"},
+		{"last week starting sunday", time.Sunday, true, PeriodWeek, "2024-03-03", "2024-03-10"},
+		{"this month", time.Monday, false, PeriodMonth, "2024-03-01", "2024-04-01"},
+		{"last month", time.Monday, true, PeriodMonth, "2024-02-01", "2024-03-01"},
+		{"this quarter", time.Monday, false, PeriodQuarter, "2024-01-01", "2024-04-01"},
+		{"last quarter", time.Monday, true, PeriodQuarter, "2023-10-01", "2024-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WeekStart = tt.weekStart
+			var window *Window
+			if tt.previous {
+				window, err = PreviousPeriod(tt.period, now)
+			} else {
+				window, err = CurrentPeriod(tt.period, now)
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, window.Start.Format(time.DateOnly))
+			assert.Equal(t, tt.end, window.End.Format(time.DateOnly))
+			assert.Equal(t, paris, window.Start.Location())
+		})
+	}
+}
+
+func TestPeriodsOnTheFirstDayOfTheWeek(t *testing.T) {
+	defer func() { Location = time.Local }()
+	Location = time.UTC
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	window, err := CurrentPeriod(PeriodWeek, monday)
+	require.NoError(t, err)
+	assert.Equal(t, monday, window.Start)
+}
+
+func TestPeriodUnsupported(t *testing.T) {
+	_, err := CurrentPeriod("fortnight", time.Now())
+	assert.Error(t, err)
+}
+
+func TestWorkingDaysConfiguration(t *testing.T) {
+	defer func() {
+		viper.Set("working_days", "")
+		require.NoError(t, UpdateCalendarConfiguration())
+	}()
+	assert.Equal(t, DefaultWorkingDays, WorkingDays)
+
+	viper.Set("working_days", "Sunday, monday,tuesday")
+	require.NoError(t, UpdateCalendarConfiguration())
+	assert.Equal(t, []time.Weekday{time.Sunday, time.Monday, time.Tuesday}, WorkingDays)
+
+	viper.Set("working_days", []string{"saturday"})
+	require.NoError(t, UpdateCalendarConfiguration())
+	assert.Equal(t, []time.Weekday{time.Saturday}, WorkingDays)
+
+	viper.Set("working_days", "someday")
+	assert.Error(t, UpdateCalendarConfiguration())
+}
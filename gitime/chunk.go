@@ -0,0 +1,48 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"strings"
+)
+
+// ChunkCommits orders the commits oldest first, drops the ones up to the cursor included (unless it is empty),
+// and splits the others in chunks of size commits, or in a single chunk when size is zero.
+// The commits are expected newest first, like git log lists them.  The cursor is a hash, or a prefix of one,
+// so that a run interrupted after a chunk resumes exactly with the commit following the last one of that chunk.
+func ChunkCommits(commits []*Commit, size int, cursor string) ([][]*Commit, error) {
+	ordered := make([]*Commit, 0, len(commits))
+	for i := len(commits) - 1; i >= 0; i-- {
+		ordered = append(ordered, commits[i])
+	}
+	if cursor != "" {
+		found := -1
+		for i, commit := range ordered {
+			if !strings.HasPrefix(commit.Hash, cursor) {
+				continue
+			}
+			if found >= 0 {
+				return nil, fmt.Errorf(locale.Tf("ChunkCursorAmbiguous", cursor))
+			}
+			found = i
+		}
+		if found < 0 {
+			return nil, fmt.Errorf(locale.Tf("ChunkCursorNotFound", cursor))
+		}
+		ordered = ordered[found+1:]
+	}
+
+	chunks := make([][]*Commit, 0)
+	if size <= 0 {
+		size = len(ordered)
+	}
+	for start := 0; start < len(ordered); start += size {
+		end := start + size
+		if end > len(ordered) {
+			end = len(ordered)
+		}
+		chunks = append(chunks, ordered[start:end])
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,81 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// newestFirst returns commits with hashes like "c4000…", newest first like git log lists them
+func newestFirst(count int) []*Commit {
+	commits := make([]*Commit, 0, count)
+	for i := count; i >= 1; i-- {
+		commits = append(commits, &Commit{Hash: fmt.Sprintf("c%d%038d", i, 0)})
+	}
+
+	return commits
+}
+
+func TestChunkCommits(t *testing.T) {
+	commits := newestFirst(5)
+	chunks, err := ChunkCommits(commits, 2, "")
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, commits[4], chunks[0][0])
+	assert.Equal(t, commits[3], chunks[0][1])
+	assert.Len(t, chunks[2], 1)
+	assert.Equal(t, commits[0], chunks[2][0])
+
+	chunks, err = ChunkCommits(commits, 0, "")
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.Len(t, chunks[0], 5)
+
+	chunks, err = ChunkCommits(commits, 0, commits[0].Hash)
+	require.NoError(t, err)
+	assert.Len(t, chunks, 0)
+}
+
+func TestChunkCommitsCursorIsAlreadyDone(t *testing.T) {
+	commits := newestFirst(5)
+	chunks, err := ChunkCommits(commits, 2, commits[3].Hash[:12])
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, commits[2], chunks[0][0], "the commit of the cursor is not processed again")
+
+	_, err = ChunkCommits(commits, 2, "deadbeef")
+	assert.Error(t, err)
+	_, err = ChunkCommits(commits, 2, "c")
+	assert.Error(t, err, "ambiguous cursor")
+}
+
+// TestChunkCommitsResumesIdentically interrupts a run after each chunk in turn,
+// and resumes it from the last printed cursor, to the same output as an uninterrupted run.
+func TestChunkCommitsResumesIdentically(t *testing.T) {
+	commits := newestFirst(7)
+	run := func(cursor string, interruptAfter int) (string, string) {
+		out := &strings.Builder{}
+		chunks, err := ChunkCommits(commits, 3, cursor)
+		require.NoError(t, err)
+		for i, chunk := range chunks {
+			if i == interruptAfter {
+				break
+			}
+			for _, commit := range chunk {
+				out.WriteString(commit.Hash + "\n")
+			}
+			cursor = chunk[len(chunk)-1].Hash
+		}
+
+		return out.String(), cursor
+	}
+
+	expected, _ := run("", -1)
+	for interruptAfter := 0; interruptAfter <= 3; interruptAfter++ {
+		first, cursor := run("", interruptAfter)
+		rest, _ := run(cursor, -1)
+		assert.Equal(t, expected, first+rest, "interrupted after %d chunks", interruptAfter)
+	}
+}
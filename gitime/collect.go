@@ -0,0 +1,319 @@
+package gitime
+
+import "github.com/goutte/git-spend/locale"
+
+// Warning is a diagnostic : something suspicious that was noticed while collecting the time spent
+type Warning struct {
+	// Code is the diagnostic code of the warning, registered in DiagnosticCodes
+	Code string `json:"code"`
+	// Severity is set by DiagnosticLevels.Apply, like SeverityWarning
+	Severity string `json:"severity"`
+	// Hash is the hash of the commit the warning is about, if any
+	Hash string `json:"hash"`
+	// Location is what the warning is about within the commit or the run, like a directive or a repository
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Collector collects the time spent in commits, according to its settings
+type Collector struct {
+	// MaxDirective is the sanity cap of a single directive, or nil for no cap
+	MaxDirective *TimeSpent
+	// EnforceMax excludes directives exceeding MaxDirective from the total, instead of only warning
+	EnforceMax bool
+	// Dedupe is the strategy used to count duplicated commits only once, see DedupeCherryPick
+	Dedupe string
+	// GroupBy is how to group the time spent, see GroupByAuthor
+	GroupBy string
+	// Linter reports the violations of the policies of the team, if any
+	Linter *Linter
+	// Forge extracts the issue references, when grouping by issue
+	Forge *Forge
+	// Corrections fix the time spent in some commits, see Correction
+	Corrections []*Correction
+	// Repository is the name of the repository of the commits, when grouping by repository
+	Repository string
+	// Split is how the time spent by a commit is apportioned, when grouping by directory or file, see SplitLines
+	Split string
+	// Changes reads the files changed by a commit, when grouping by directory or file,
+	// with the lines they changed only when lines is true, since git may have to fetch blobs to count them.
+	// It is only called for the commits holding some time spent.
+	Changes func(commit *Commit, lines bool) ([]*FileChange, error)
+	// NonWorkingDays flags the directives spending time on the weekend or on holidays, or nil
+	NonWorkingDays *NonWorkingDays
+	// ExcludeFlagged excludes the flagged directives from the total, instead of only listing them
+	ExcludeFlagged bool
+	// Rates are the rates of the labels of the directives, if any, to warn about the labels without one
+	Rates Rates
+}
+
+// Collection is what a Collector collected from commits
+type Collection struct {
+	// TimeSpent is the total time spent, not normalized
+	TimeSpent *TimeSpent
+	// Excluded is the time spent in directives that were excluded from the total
+	Excluded *TimeSpent
+	// Flagged are the directives spending time on non-working days, sorted by date
+	Flagged []*FlaggedSpend
+	// NonBillable is the time spent in flagged directives that were excluded from the total
+	NonBillable *TimeSpent
+	// Collapsed is how many duplicated commits were not counted
+	Collapsed int
+	// Groups holds the time spent per group, sorted by decreasing time spent, if grouping was asked for
+	Groups []*Group
+	// Results are the time spent by each commit holding some, or by each share of it when splitting,
+	// with the key of their group, for reports to group them their own way
+	Results  []*Result
+	Warnings []*Warning
+	// Violations of the policies of the Linter
+	Violations []*Warning
+	// Corrected are the commits whose time spent was corrected
+	Corrected []*CorrectedCommit
+	// Counts tell how many commits were scanned, matched and skipped
+	Counts *Counts
+	// Ranges are the directives spending ranges of time, like "/spend 1-2h", that were collapsed
+	Ranges []*CollapsedRange
+}
+
+// CollapsedRange is a directive of a commit spending a range of time, collapsed under its policy
+type CollapsedRange struct {
+	Hash      string
+	Line      string
+	Range     *TimeRange
+	TimeSpent *TimeSpent
+}
+
+// Collect the time spent in the directives of the commits
+func (c *Collector) Collect(commits []*Commit) *Collection {
+	collection := &Collection{
+		TimeSpent:   &TimeSpent{},
+		Excluded:    &TimeSpent{},
+		Flagged:     make([]*FlaggedSpend, 0),
+		NonBillable: &TimeSpent{},
+		Warnings:    make([]*Warning, 0),
+		Violations:  make([]*Warning, 0),
+		Corrected:   make([]*CorrectedCommit, 0),
+		Counts:      NewCounts(),
+		Ranges:      make([]*CollapsedRange, 0),
+		Results:     make([]*Result, 0),
+	}
+
+	collection.Counts.Scanned = len(commits)
+	if c.Dedupe == DedupeCherryPick {
+		commits, collection.Collapsed = dedupeCherryPicks(commits)
+		collection.Counts.Skipped[SkippedDuplicate] = collection.Collapsed
+	}
+
+	for _, commit := range commits {
+		counted := &TimeSpent{}
+		nonBillable := &TimeSpent{}
+		labels := make([]share, 0)
+		if commit.LossyEncoding != "" {
+			collection.Warnings = append(collection.Warnings, &Warning{
+				Code:    CodeLossyEncoding,
+				Hash:    commit.Hash,
+				Message: locale.Tf("WarningLossyEncoding", commit.ShortHash(), commit.LossyEncoding),
+			})
+		}
+		for _, violation := range c.Linter.LintMessage(commit.Message) {
+			collection.Violations = append(collection.Violations, &Warning{
+				Code:    violation.Code,
+				Hash:    commit.Hash,
+				Message: locale.Tf("ViolationInCommit", commit.ShortHash(), commit.AuthorName, violation.Message),
+			})
+		}
+		collection.Counts.Count(commit)
+		directives := CollectDirectives(commit.Message)
+		for _, directive := range directives {
+			if directive.Range != nil {
+				collection.Ranges = append(collection.Ranges, &CollapsedRange{
+					Hash:      commit.Hash,
+					Line:      directive.Line,
+					Range:     directive.Range,
+					TimeSpent: directive.TimeSpent,
+				})
+			}
+			if c.isOverMax(directive) {
+				collection.Warnings = append(collection.Warnings, &Warning{
+					Code:     CodeDirectiveOverMax,
+					Hash:     commit.Hash,
+					Location: directive.Line,
+					Message: locale.Tf(
+						"WarningDirectiveOverMax",
+						commit.ShortHash(),
+						directive.Line,
+						c.MaxDirective.String(),
+					),
+				})
+				if c.EnforceMax {
+					collection.Excluded.Add(directive.TimeSpent)
+					continue
+				}
+			}
+			date := directive.BucketDate(commit)
+			if reason := c.NonWorkingDays.Reason(date); reason != "" {
+				collection.Flagged = append(collection.Flagged, &FlaggedSpend{
+					Commit:    commit,
+					Line:      directive.Line,
+					Date:      date,
+					Reason:    reason,
+					TimeSpent: directive.TimeSpent,
+				})
+				if c.ExcludeFlagged {
+					nonBillable.Add(directive.TimeSpent)
+					continue
+				}
+			}
+			if len(c.Rates) > 0 && !c.Rates.Knows(directive.Label) {
+				collection.Warnings = append(collection.Warnings, &Warning{
+					Code:     CodeLabelUnknown,
+					Hash:     commit.Hash,
+					Location: directive.Line,
+					Message:  locale.Tf("WarningLabelUnknown", commit.ShortHash(), directive.Label),
+				})
+			}
+			labels = addLabelShare(labels, directive.Label, directive.TimeSpent)
+			counted.Add(directive.TimeSpent)
+		}
+		// Corrections fix the time spent by the whole commit, before its flagged part is excluded
+		if correction := findCorrection(c.Corrections, commit.Hash); correction != nil {
+			original := (&TimeSpent{}).Add(counted).Add(nonBillable)
+			corrected := correction.Apply(original)
+			collection.Corrected = append(collection.Corrected, &CorrectedCommit{
+				Hash:      commit.Hash,
+				Original:  original,
+				Corrected: corrected,
+			})
+			counted, nonBillable = excludeFlaggedShare(original, corrected, nonBillable)
+		}
+		collection.NonBillable.Add(nonBillable)
+		collection.TimeSpent.Add(counted)
+		if counted.IsZero() {
+			continue
+		}
+		if isSplitGrouping(c.GroupBy) {
+			results, err := c.shareResults(commit, counted)
+			if err != nil {
+				collection.Warnings = append(collection.Warnings, &Warning{
+					Code:    CodeChangesUnreadable,
+					Hash:    commit.Hash,
+					Message: locale.Tf("WarningChangesUnreadable", commit.ShortHash(), err.Error()),
+				})
+			}
+			collection.Results = append(collection.Results, results...)
+		} else if c.GroupBy == GroupByLabel {
+			collection.Results = append(collection.Results, c.labelResults(commit, counted, labels)...)
+		} else {
+			collection.Results = append(collection.Results, &Result{
+				Commit:     commit,
+				Repository: c.Repository,
+				Key:        c.groupKey(commit),
+				TimeSpent:  counted,
+			})
+		}
+	}
+	if c.GroupBy != GroupByNone {
+		collection.Groups = GroupResults(collection.Results, (*Result).GroupKey, c.groupId)
+	}
+	sortFlagged(collection.Flagged)
+
+	return collection
+}
+
+// excludeFlaggedShare splits the corrected time spent by a commit into what is counted, and what is not billable,
+// in the same proportions as the original time spent, from which nonBillable was excluded
+func excludeFlaggedShare(original *TimeSpent, corrected *TimeSpent, nonBillable *TimeSpent) (*TimeSpent, *TimeSpent) {
+	if nonBillable.IsZero() {
+		return corrected, nonBillable
+	}
+	excluded := corrected.toExactMinutes() * nonBillable.toExactMinutes() / original.toExactMinutes()
+
+	return &TimeSpent{Minutes: corrected.toExactMinutes() - excluded}, &TimeSpent{Minutes: excluded}
+}
+
+// InMinutes expresses all the durations of the collection in minutes, under the current schedule,
+// so that it may be merged with collections converted under another schedule.
+func (c *Collection) InMinutes() *Collection {
+	c.TimeSpent = c.TimeSpent.InMinutes()
+	c.Excluded = c.Excluded.InMinutes()
+	c.NonBillable = c.NonBillable.InMinutes()
+	for _, flagged := range c.Flagged {
+		flagged.TimeSpent = flagged.TimeSpent.InMinutes()
+	}
+	for _, group := range c.Groups {
+		group.TimeSpent = group.TimeSpent.InMinutes()
+	}
+	for _, result := range c.Results {
+		result.TimeSpent = result.TimeSpent.InMinutes()
+	}
+	for _, corrected := range c.Corrected {
+		corrected.Original = corrected.Original.InMinutes()
+		corrected.Corrected = corrected.Corrected.InMinutes()
+	}
+	for _, collapsed := range c.Ranges {
+		collapsed.TimeSpent = collapsed.TimeSpent.InMinutes()
+		collapsed.Range = &TimeRange{
+			Low:    collapsed.Range.Low.InMinutes(),
+			High:   collapsed.Range.High.InMinutes(),
+			Policy: collapsed.Range.Policy,
+		}
+	}
+
+	return c
+}
+
+// Merge adds the other collection into this one, merging groups of the same key
+func (c *Collection) Merge(other *Collection) {
+	c.TimeSpent.Add(other.TimeSpent)
+	c.Excluded.Add(other.Excluded)
+	c.NonBillable.Add(other.NonBillable)
+	c.Flagged = append(c.Flagged, other.Flagged...)
+	sortFlagged(c.Flagged)
+	c.Collapsed += other.Collapsed
+	c.Warnings = append(c.Warnings, other.Warnings...)
+	c.Violations = append(c.Violations, other.Violations...)
+	c.Corrected = append(c.Corrected, other.Corrected...)
+	c.Ranges = append(c.Ranges, other.Ranges...)
+	c.Results = append(c.Results, other.Results...)
+	if c.Counts != nil && other.Counts != nil {
+		c.Counts.Add(other.Counts)
+	}
+	if other.Groups == nil {
+		return
+	}
+
+	groups := make(map[string]*Group)
+	for _, group := range c.Groups {
+		groups[group.identity()] = group
+	}
+	for _, group := range other.Groups {
+		mine, found := groups[group.identity()]
+		if !found {
+			groups[group.identity()] = group
+			continue
+		}
+		mine.TimeSpent.Add(group.TimeSpent)
+		mine.Commits += group.Commits
+		for spelling, count := range group.spellings {
+			if mine.spellings != nil {
+				mine.spellings[spelling] += count
+			}
+		}
+		if group.First.Before(mine.First) {
+			mine.First = group.First
+		}
+		if group.Last.After(mine.Last) {
+			mine.Last = group.Last
+		}
+	}
+	c.Groups = sortGroups(groups)
+}
+
+// isOverMax compares minutes, so that the cap honors the time modulo configuration
+func (c *Collector) isOverMax(directive *Directive) bool {
+	if c.MaxDirective == nil {
+		return false
+	}
+
+	return directive.TimeSpent.ToMinutes() > c.MaxDirective.ToMinutes()
+}
@@ -0,0 +1,51 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var collectTestCommits = []*Commit{
+	{Hash: "aaaaaaaaaa", Message: "feat: typo\n\n/spend 300h"},
+	{Hash: "bbbbbbbbbb", Message: "feat: legit\n\n/spend 2h\n/spend 1d"},
+}
+
+func TestCollector_Collect(t *testing.T) {
+	collection := (&Collector{}).Collect(collectTestCommits)
+	assert.Equal(t, uint64(302*60+480), collection.TimeSpent.ToMinutes())
+	assert.True(t, collection.Excluded.IsZero())
+	assert.Empty(t, collection.Warnings)
+}
+
+func TestCollector_CollectWithMaxDirective(t *testing.T) {
+	collector := &Collector{MaxDirective: &TimeSpent{Hours: 24}}
+	collection := collector.Collect(collectTestCommits)
+	assert.Equal(t, uint64(302*60+480), collection.TimeSpent.ToMinutes())
+	assert.True(t, collection.Excluded.IsZero())
+	assert.Len(t, collection.Warnings, 1)
+	assert.Equal(t, "aaaaaaaaaa", collection.Warnings[0].Hash)
+}
+
+func TestCollector_CollectWithEnforcedMaxDirective(t *testing.T) {
+	collector := &Collector{MaxDirective: &TimeSpent{Hours: 24}, EnforceMax: true}
+	collection := collector.Collect(collectTestCommits)
+	assert.Equal(t, uint64(2*60+480), collection.TimeSpent.ToMinutes())
+	assert.Equal(t, uint64(300*60), collection.Excluded.ToMinutes())
+	assert.Len(t, collection.Warnings, 1)
+}
+
+func TestCollector_CollectComparesNormalizedMinutes(t *testing.T) {
+	// 1 day is 8 hours with the default time modulo, so a cap of 8h is not exceeded
+	collector := &Collector{MaxDirective: &TimeSpent{Hours: 8}, EnforceMax: true}
+	collection := collector.Collect([]*Commit{{Message: "/spend 1d"}})
+	assert.Empty(t, collection.Warnings)
+}
+
+func TestParseTimeSpent(t *testing.T) {
+	ts, err := ParseTimeSpent("1h30")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(90), ts.ToMinutes())
+
+	_, err = ParseTimeSpent("a while")
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package gitime
+
+import "strings"
+
+// DefaultCommentChar is the character git starts comment lines with, unless core.commentChar says otherwise
+const DefaultCommentChar = "#"
+
+// CommentChar starts the comment lines of commit messages, like the ones of commit templates.
+// Comment lines never hold directives.
+var CommentChar = DefaultCommentChar
+
+// scissors is what follows the comment character on the line below which `git commit --verbose` puts the diff
+const scissors = " ------------------------ >8 ------------------------"
+
+// isCommentLine tells whether the (untrimmed) line of a message is a comment, like git would
+func isCommentLine(line string) bool {
+	return CommentChar != "" && strings.HasPrefix(line, CommentChar)
+}
+
+// isScissorsLine tells whether everything below that (untrimmed) line should be ignored, like git would
+func isScissorsLine(line string) bool {
+	return CommentChar != "" && line == CommentChar+scissors
+}
+
+// CollectCommentedDirectives returns the comment lines of the message that would be directives if uncommented,
+// like a "# /spend" line of a commit template that was not filled in properly.
+func CollectCommentedDirectives(message string) []string {
+	commented := make([]string, 0)
+	if CommentChar == "" {
+		return commented
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r", "\n"), "\n") {
+		if isScissorsLine(line) {
+			break
+		}
+		if !isCommentLine(line) {
+			continue
+		}
+		directive := extractDirectiveFromLine(strings.TrimSpace(strings.TrimPrefix(line, CommentChar)))
+		if directive != nil && !directive.TimeSpent.IsZero() {
+			commented = append(commented, strings.TrimSpace(line))
+		}
+	}
+
+	return commented
+}
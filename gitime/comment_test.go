@@ -0,0 +1,37 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectDirectivesSkipsCommentLines(t *testing.T) {
+	defer func() { CommentChar = DefaultCommentChar }()
+	message := "feat: x\n\n/spend 1h\n# /spend 2h\n; /spend 4h\n"
+	assert.Equal(t, uint64(60), CollectTimeSpent(message).ToMinutes())
+
+	CommentChar = ";"
+	assert.Equal(t, uint64(60), CollectTimeSpent(message).ToMinutes())
+	assert.True(t, isCommentLine("; /spend 4h"))
+	assert.False(t, isCommentLine("# /spend 2h"))
+}
+
+func TestCollectDirectivesStopsAtScissors(t *testing.T) {
+	defer func() { CommentChar = DefaultCommentChar }()
+	message := "feat: x\n\n/spend 1h\n" +
+		"# ------------------------ >8 ------------------------\n" +
+		"diff --git a/README.md b/README.md\n" +
+		" /spend 2h\n"
+	assert.Equal(t, uint64(60), CollectTimeSpent(message).ToMinutes())
+
+	CommentChar = ";"
+	assert.Equal(t, uint64(180), CollectTimeSpent(message).ToMinutes())
+}
+
+func TestCollectCommentedDirectives(t *testing.T) {
+	defer func() { CommentChar = DefaultCommentChar }()
+	CommentChar = ";"
+	message := "feat: x\n\n; /spend 1h\n; Please enter the commit message\n;/spend\n"
+	assert.Equal(t, []string{"; /spend 1h"}, CollectCommentedDirectives(message))
+	assert.Empty(t, CollectDirectives(message))
+}
@@ -0,0 +1,37 @@
+package gitime
+
+import (
+	"strings"
+	"time"
+)
+
+// Commit holds what we need to know about a commit in order to collect its time spent.
+// Commits read from standard input may only have a Message.
+type Commit struct {
+	Hash        string
+	AuthorName  string
+	AuthorEmail string
+	Date        time.Time
+	// CommitDate is when the commit was made, which differs from its (author) Date when it was rebased or amended
+	CommitDate time.Time
+	Message    string
+	// LossyEncoding is the encoding the commit declared (UTF-8 when none), when it was not actually in it,
+	// so that the characters that could not be read were replaced in its message or author
+	LossyEncoding string
+}
+
+// ShortHash returns the abbreviated hash of the commit, like git does
+func (c *Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+
+	return c.Hash
+}
+
+// Subject returns the first line of the message
+func (c *Commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+
+	return strings.TrimSpace(subject)
+}
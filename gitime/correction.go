@@ -0,0 +1,145 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MinCorrectionHashLength is the length of the shortest hash a correction may be about,
+// like git abbreviates them, so that a correction never matches commits by accident
+const MinCorrectionHashLength = 7
+
+var correctionHashRegex = regexp.MustCompile(fmt.Sprintf("^[0-9a-f]{%d,40}$", MinCorrectionHashLength))
+
+// Correction fixes the time spent in a published commit, without rewriting history.
+// It either replaces the time spent, or adds a (perhaps negative) delta to it.
+type Correction struct {
+	// Hash of the commit, perhaps abbreviated
+	Hash string
+	// Replacement is the time spent by the commit instead of its directives, or nil for a delta
+	Replacement *TimeSpent
+	// Delta is added to (or subtracted from, when Negative) the time spent by the commit
+	Delta    *TimeSpent
+	Negative bool
+}
+
+// CorrectedCommit is a commit whose time spent was corrected during collection
+type CorrectedCommit struct {
+	Hash      string     `json:"hash"`
+	Original  *TimeSpent `json:"original"`
+	Corrected *TimeSpent `json:"corrected"`
+}
+
+// ParseCorrection parses the value of a correction, like "1h30" to replace the time spent,
+// or "+30m" and "-1h" to add to or subtract from it.  "0" ignores the time spent by the commit.
+// The hash must hold at least MinCorrectionHashLength hexadecimal characters.
+func ParseCorrection(hash string, value string) (*Correction, error) {
+	correction := &Correction{Hash: strings.ToLower(strings.TrimSpace(hash))}
+	if !correctionHashRegex.MatchString(correction.Hash) {
+		return nil, fmt.Errorf(locale.Tf("CorrectionHashInvalid", hash, MinCorrectionHashLength))
+	}
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		correction.Replacement = &TimeSpent{}
+		return correction, nil
+	}
+
+	sign := ""
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		sign, value = value[:1], strings.TrimSpace(value[1:])
+	}
+	ts, err := ParseTimeSpent(value)
+	if err != nil {
+		return nil, fmt.Errorf(locale.Tf("CorrectionUnparsable", hash, err.Error()))
+	}
+	if sign == "" {
+		correction.Replacement = ts
+	} else {
+		correction.Delta = ts
+		correction.Negative = sign == "-"
+	}
+
+	return correction, nil
+}
+
+// ParseCorrections parses the corrections of a config, keyed by commit hash, sorted by hash
+func ParseCorrections(values map[string]string) ([]*Correction, error) {
+	corrections := make([]*Correction, 0, len(values))
+	for hash, value := range values {
+		correction, err := ParseCorrection(hash, value)
+		if err != nil {
+			return nil, err
+		}
+		corrections = append(corrections, correction)
+	}
+	sort.Slice(corrections, func(i, j int) bool {
+		return corrections[i].Hash < corrections[j].Hash
+	})
+
+	return corrections, nil
+}
+
+// ResolveCorrections returns the corrections of the hashes that resolved to a single commit,
+// about its full hash.  The corrections of unknown or ambiguous hashes are never applied.
+func ResolveCorrections(corrections []*Correction, commits map[string]string) []*Correction {
+	resolved := make([]*Correction, 0, len(corrections))
+	for _, correction := range corrections {
+		full, found := commits[correction.Hash]
+		if !found {
+			continue
+		}
+		resolvedCorrection := *correction
+		resolvedCorrection.Hash = strings.ToLower(full)
+		resolved = append(resolved, &resolvedCorrection)
+	}
+
+	return resolved
+}
+
+// Matches tells whether the correction is about the commit of the (full) hash.
+// Corrections should be resolved to a single commit first, since an abbreviated hash may match several.
+func (c *Correction) Matches(hash string) bool {
+	return len(c.Hash) >= MinCorrectionHashLength && strings.HasPrefix(strings.ToLower(hash), c.Hash)
+}
+
+// Apply returns the corrected time spent.  A negative delta never brings it below zero.
+func (c *Correction) Apply(ts *TimeSpent) *TimeSpent {
+	if c.Replacement != nil {
+		return (&TimeSpent{}).Add(c.Replacement)
+	}
+	if !c.Negative {
+		return (&TimeSpent{}).Add(ts).Add(c.Delta)
+	}
+
+	return &TimeSpent{Minutes: math.Max(0, ts.toExactMinutes()-c.Delta.toExactMinutes())}
+}
+
+// String returns the correction the way it is written in the config
+func (c *Correction) String() string {
+	if c.Replacement != nil && c.Replacement.IsZero() {
+		return "0"
+	}
+	if c.Replacement != nil {
+		return c.Replacement.String()
+	}
+	if c.Negative {
+		return "-" + c.Delta.String()
+	}
+
+	return "+" + c.Delta.String()
+}
+
+// findCorrection returns the correction of the commit, or nil
+func findCorrection(corrections []*Correction, hash string) *Correction {
+	for _, correction := range corrections {
+		if correction.Matches(hash) {
+			return correction
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseCorrection(t *testing.T) {
+	replacement, err := ParseCorrection("ABC1234", "1h30")
+	require.NoError(t, err)
+	assert.Equal(t, "abc1234", replacement.Hash)
+	assert.Equal(t, uint64(90), replacement.Apply(&TimeSpent{Hours: 8}).ToMinutes())
+	assert.Equal(t, "1 hour 30 minutes", replacement.String())
+
+	more, err := ParseCorrection("abc1234", "+30m")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(90), more.Apply(&TimeSpent{Hours: 1}).ToMinutes())
+	assert.Equal(t, "+30 minutes", more.String())
+
+	less, err := ParseCorrection("abc1234", "- 2h")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(60), less.Apply(&TimeSpent{Hours: 3}).ToMinutes())
+	// Never below zero
+	assert.Equal(t, uint64(0), less.Apply(&TimeSpent{Hours: 1}).ToMinutes())
+
+	nothing, err := ParseCorrection("abc1234", "0")
+	require.NoError(t, err)
+	assert.True(t, nothing.Apply(&TimeSpent{Hours: 3}).IsZero())
+	assert.Equal(t, "0", nothing.String())
+
+	_, err = ParseCorrection("abc1234", "a lot")
+	assert.Error(t, err)
+}
+
+func TestParseCorrectionOfShortHash(t *testing.T) {
+	for _, hash := range []string{"", "a", "abc123", "abc123z", "not a hash"} {
+		_, err := ParseCorrection(hash, "0")
+		assert.Error(t, err, hash)
+	}
+	_, err := ParseCorrections(map[string]string{"abc1234": "1h", "a": "0"})
+	assert.Error(t, err)
+}
+
+func TestResolveCorrections(t *testing.T) {
+	corrections, err := ParseCorrections(map[string]string{"abc1234": "0", "abc1235": "1h", "0000000": "+1h"})
+	require.NoError(t, err)
+	// abc1234 is ambiguous and 0000000 unknown, so neither resolved
+	resolved := ResolveCorrections(corrections, map[string]string{"abc1235": "abc1235feedfacefeedfacefeedfacefeedface"})
+	require.Len(t, resolved, 1)
+	assert.Equal(t, "abc1235feedfacefeedfacefeedfacefeedface", resolved[0].Hash)
+	assert.Equal(t, "1 hour", resolved[0].String())
+	assert.Equal(t, "abc1235", corrections[2].Hash, "the corrections are left alone")
+
+	collector := &Collector{Corrections: resolved}
+	collection := collector.Collect([]*Commit{
+		{Hash: "abc1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Message: "/spend 2h"},
+		{Hash: "abc1234bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", Message: "/spend 3h"},
+		{Hash: "abc1235feedfacefeedfacefeedfacefeedface", Message: "/spend 4h"},
+	})
+	assert.Equal(t, uint64(360), collection.TimeSpent.ToMinutes())
+}
+
+func TestCorrection_Matches(t *testing.T) {
+	correction := &Correction{Hash: "abc1234"}
+	assert.True(t, correction.Matches("abc1234def"))
+	assert.True(t, correction.Matches("ABC1234DEF"))
+	assert.False(t, correction.Matches("abc"))
+	assert.False(t, (&Correction{}).Matches("abc"))
+	assert.False(t, (&Correction{Hash: "a"}).Matches("abc1234def"))
+}
+
+func TestCollector_CollectWithCorrections(t *testing.T) {
+	corrections, err := ParseCorrections(map[string]string{"bbbbbbb": "1h", "ccccccc": "-1h"})
+	require.NoError(t, err)
+	collector := &Collector{GroupBy: GroupByAuthor, Corrections: corrections}
+	collection := collector.Collect([]*Commit{
+		{Hash: "aaaaaaa1", AuthorName: "Alice", Message: "/spend 2h"},
+		{Hash: "bbbbbbb1", AuthorName: "Bob", Message: "/spend 3d"},
+		{Hash: "ccccccc1", AuthorName: "Alice", Message: "/spend 30m"},
+	})
+
+	assert.Equal(t, uint64(180), collection.TimeSpent.ToMinutes())
+	require.Len(t, collection.Corrected, 2)
+	assert.Equal(t, "bbbbbbb1", collection.Corrected[0].Hash)
+	assert.Equal(t, uint64(1440), collection.Corrected[0].Original.ToMinutes())
+	assert.Equal(t, uint64(60), collection.Corrected[0].Corrected.ToMinutes())
+	// The commit corrected to nothing is not activity anymore
+	require.Len(t, collection.Groups, 2)
+	assert.Equal(t, 1, collection.Groups[0].Commits)
+}
@@ -0,0 +1,192 @@
+package gitime
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Reasons why commits were skipped instead of being collected
+const (
+	SkippedMerge     = "merge"
+	SkippedAuthor    = "author"
+	SkippedDuplicate = "duplicate"
+)
+
+// Counts tell what the total is made of, so that a filter silently eating half the history gets noticed
+type Counts struct {
+	// Scanned is how many commits were read, including the skipped ones
+	Scanned int `json:"scanned"`
+	// Matched is how many commits hold at least one valid directive
+	Matched int `json:"matched"`
+	// Skipped is how many commits were skipped by the filters, by reason, like SkippedMerge
+	Skipped map[string]int `json:"skipped"`
+	// Unparsable is how many directive lines failed to parse
+	Unparsable int `json:"unparsable"`
+}
+
+// NewCounts returns counts of nothing
+func NewCounts() *Counts {
+	return &Counts{Skipped: make(map[string]int)}
+}
+
+// CountCommits returns the counts of the commits, none of them skipped yet
+func CountCommits(commits []*Commit) *Counts {
+	counts := NewCounts()
+	counts.Scanned = len(commits)
+	for _, commit := range commits {
+		counts.Count(commit)
+	}
+
+	return counts
+}
+
+// Count records whether the commit, already scanned and not skipped, holds time spent, and its unparsable directives
+func (c *Counts) Count(commit *Commit) {
+	if len(CollectDirectives(commit.Message)) > 0 {
+		c.Matched++
+	}
+	c.Unparsable += len(CollectUnparsableDirectives(commit.Message))
+}
+
+// Filtered records the commits that were skipped before even reaching the collector, by reason
+func (c *Counts) Filtered(skipped map[string]int) {
+	for reason, count := range skipped {
+		if count == 0 {
+			continue
+		}
+		c.Scanned += count
+		c.Skipped[reason] += count
+	}
+}
+
+// Add adds the other counts to these
+func (c *Counts) Add(other *Counts) {
+	c.Scanned += other.Scanned
+	c.Matched += other.Matched
+	c.Unparsable += other.Unparsable
+	for reason, count := range other.Skipped {
+		c.Skipped[reason] += count
+	}
+}
+
+// SkippedTotal is how many commits were skipped, for any reason
+func (c *Counts) SkippedTotal() int {
+	total := 0
+	for _, count := range c.Skipped {
+		total += count
+	}
+
+	return total
+}
+
+// Coverage returns the ratio (between 0 and 1) of the commits that were not skipped holding some time spent,
+// like Coverage, or zero when no commit was counted
+func (c *Counts) Coverage() float64 {
+	counted := c.Scanned - c.SkippedTotal()
+	if counted <= 0 {
+		return 0.0
+	}
+
+	return float64(c.Matched) / float64(counted)
+}
+
+// keywordRegex matches the lines that attempt to be directives, whether they succeed or not
+var keywordRegex = regexp.MustCompile(`^\s*/spen[dt](?:\s|:|$)`)
+
+// CollectUnparsableDirectives returns the lines of the message that start like a directive,
+// but from which no time spent could be parsed, like "/spend a while".
+func CollectUnparsableDirectives(message string) []string {
+	unparsable := make([]string, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r", "\n"), "\n") {
+		if isScissorsLine(line) {
+			break
+		}
+		if isCommentLine(line) || !keywordRegex.MatchString(line) {
+			continue
+		}
+		directive := extractDirectiveFromLine(strings.TrimSpace(line))
+		if directive == nil || directive.TimeSpent.IsZero() {
+			unparsable = append(unparsable, strings.TrimSpace(line))
+		}
+	}
+
+	return unparsable
+}
+
+// keywords are the spellings of the keyword of the directives, without their slash
+var keywords = []string{"spend", "spent"}
+
+// CollectNearMissDirectives returns the lines of the message that were probably meant as directives,
+// but are not read as such, like "/spned 1h", "/Spend 1h", "\spend 1h" or "spent: 2h".
+// Lines without a time spent after the keyword, like "/send the report", are not near misses.
+func CollectNearMissDirectives(message string) []string {
+	nearMisses := make([]string, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r", "\n"), "\n") {
+		if isScissorsLine(line) {
+			break
+		}
+		if isCommentLine(line) || keywordRegex.MatchString(line) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// The slash may be missing, like in "spent 2h", but the time spent must be there
+		first := strings.TrimLeft(strings.ToLower(strings.TrimRight(fields[0], ":")), "/\\")
+		if !isNearKeyword(first) || len(fields) < 2 {
+			continue
+		}
+		if _, err := ParseTimeSpent(strings.Join(fields[1:], " ")); err == nil {
+			nearMisses = append(nearMisses, strings.TrimSpace(line))
+		}
+	}
+
+	return nearMisses
+}
+
+// isNearKeyword tells whether the word is a keyword with at most one typo, like "spned" or "spedn"
+func isNearKeyword(word string) bool {
+	for _, keyword := range keywords {
+		if editDistance(word, keyword) <= 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editDistance returns how many insertions, deletions, substitutions or swaps of adjacent runes
+// turn a into b
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	distances := make([][]int, len(ra)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(rb)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			best := distances[i-1][j-1] + cost
+			if distances[i-1][j]+1 < best {
+				best = distances[i-1][j] + 1
+			}
+			if distances[i][j-1]+1 < best {
+				best = distances[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && distances[i-2][j-2]+1 < best {
+				best = distances[i-2][j-2] + 1
+			}
+			distances[i][j] = best
+		}
+	}
+
+	return distances[len(ra)][len(rb)]
+}
@@ -0,0 +1,57 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectUnparsableDirectives(t *testing.T) {
+	message := "feat: x\n\n/spend 1h\n/spend a while\n/spent\n/spending more\n# /spend later\n/spend 0\n"
+	assert.Equal(t, []string{"/spend a while", "/spent", "/spend 0"}, CollectUnparsableDirectives(message))
+	assert.Empty(t, CollectUnparsableDirectives("feat: x\n\n/spend 1h30"))
+}
+
+func TestCollectNearMissDirectives(t *testing.T) {
+	message := "feat: x\n\n/spned 1h\n/Spend 2h\n\\spend 3h\nspent: 4h\n/spend 1h\n/spending more\n" +
+		"spend the afternoon\n# /spedn 1h\n/send the report\n"
+	assert.Equal(t, []string{"/spned 1h", "/Spend 2h", "\\spend 3h", "spent: 4h"},
+		CollectNearMissDirectives(message))
+	assert.Empty(t, CollectNearMissDirectives("feat: x\n\n/spend 1h30\n/spend soon"))
+}
+
+func TestCollector_CollectCounts(t *testing.T) {
+	commits := []*Commit{
+		{Hash: "aaaaaaaaaa", Message: "feat: a\n\n/spend 1h"},
+		{Hash: "bbbbbbbbbb", Message: "feat: b\n\n/spend soon"},
+		{Hash: "cccccccccc", Message: "feat: c"},
+	}
+	collection := (&Collector{}).Collect(commits)
+	assert.Equal(t, 3, collection.Counts.Scanned)
+	assert.Equal(t, 1, collection.Counts.Matched)
+	assert.Equal(t, 1, collection.Counts.Unparsable)
+	assert.Equal(t, 0, collection.Counts.SkippedTotal())
+
+	assert.InDelta(t, 1.0/3.0, collection.Counts.Coverage(), 1e-9)
+
+	collection.Counts.Filtered(map[string]int{SkippedMerge: 2, SkippedAuthor: 0})
+	assert.Equal(t, 5, collection.Counts.Scanned)
+	assert.Equal(t, map[string]int{SkippedMerge: 2}, collection.Counts.Skipped)
+	assert.InDelta(t, 1.0/3.0, collection.Counts.Coverage(), 1e-9, "skipped commits are not covered")
+	assert.Equal(t, 0.0, NewCounts().Coverage())
+}
+
+func TestCountCommits(t *testing.T) {
+	commits := []*Commit{
+		{Hash: "aaaaaaaaaa", Message: "feat: a\n\n/spend 1h"},
+		{Hash: "bbbbbbbbbb", Message: "feat: b\n\n/spend soon"},
+		{Hash: "cccccccccc", Message: "feat: c"},
+	}
+	assert.Equal(t, (&Collector{}).Collect(commits).Counts, CountCommits(commits), "like the collector")
+}
+
+func TestCollection_MergeAddsCounts(t *testing.T) {
+	collection := (&Collector{}).Collect(collectTestCommits)
+	collection.Merge((&Collector{}).Collect(collectTestCommits[:1]))
+	assert.Equal(t, 3, collection.Counts.Scanned)
+	assert.Equal(t, 3, collection.Counts.Matched)
+}
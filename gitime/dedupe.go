@@ -0,0 +1,83 @@
+package gitime
+
+import "regexp"
+
+// Strategies to count only once the time spent in duplicated commits
+const (
+	DedupeNone       = ""
+	DedupeCherryPick = "cherry-pick"
+)
+
+// SupportedDedupeStrategies lists the strategies accepted by Collector.Dedupe
+var SupportedDedupeStrategies = []string{DedupeCherryPick}
+
+// cherryPickRegex matches the trailer that `git cherry-pick -x` appends to messages
+var cherryPickRegex = regexp.MustCompile("\\(cherry picked from commit ([0-9a-f]{7,64})\\)")
+
+// dedupeCherryPicks follows the cherry-pick trailers to build groups of equivalent commits,
+// and keeps only one commit per group : the original one if it is there, or else the oldest one.
+// Commits are expected in the order of git log, newest first.
+// Also returns how many commits were collapsed.
+func dedupeCherryPicks(commits []*Commit) ([]*Commit, int) {
+	parents := make(map[string]string)
+	var find func(hash string) string
+	find = func(hash string) string {
+		parent, found := parents[hash]
+		if !found || parent == hash {
+			return hash
+		}
+		root := find(parent)
+		parents[hash] = root
+		return root
+	}
+	union := func(a string, b string) {
+		rootA, rootB := find(a), find(b)
+		if rootA != rootB {
+			parents[rootA] = rootB
+		}
+	}
+
+	isCherryPick := make(map[*Commit]bool)
+	for _, commit := range commits {
+		if commit.Hash == "" {
+			continue
+		}
+		for _, matches := range cherryPickRegex.FindAllStringSubmatch(commit.Message, -1) {
+			isCherryPick[commit] = true
+			union(commit.Hash, resolveHash(matches[1], commits))
+		}
+	}
+
+	representatives := make(map[string]*Commit)
+	for _, commit := range commits {
+		if commit.Hash == "" {
+			continue
+		}
+		root := find(commit.Hash)
+		representative, found := representatives[root]
+		// Overwriting favors older commits, since they come last
+		if !found || isCherryPick[representative] {
+			representatives[root] = commit
+		}
+	}
+
+	kept := make([]*Commit, 0, len(representatives))
+	for _, commit := range commits {
+		if commit.Hash == "" || representatives[find(commit.Hash)] == commit {
+			kept = append(kept, commit)
+		}
+	}
+
+	return kept, len(commits) - len(kept)
+}
+
+// resolveHash expands an abbreviated hash using the hashes of the commits, when possible
+func resolveHash(hash string, commits []*Commit) string {
+	for _, commit := range commits {
+		if len(commit.Hash) >= len(hash) && commit.Hash[:len(hash)] == hash {
+			return commit.Hash
+		}
+	}
+
+	return hash
+}
@@ -0,0 +1,41 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDedupeCherryPicks(t *testing.T) {
+	original := &Commit{Hash: "1111111111", Message: "fix: thing\n\n/spend 1h"}
+	onRelease1 := &Commit{Hash: "2222222222", Message: "fix: thing\n\n/spend 1h\n\n(cherry picked from commit 1111111111)"}
+	// A cherry-pick of a cherry-pick, using an abbreviated hash
+	onRelease2 := &Commit{Hash: "3333333333", Message: "fix: thing\n\n/spend 1h\n\n(cherry picked from commit 1111111111)\n(cherry picked from commit 2222222)"}
+	unrelated := &Commit{Hash: "4444444444", Message: "feat: other\n\n/spend 2h"}
+	anonymous := &Commit{Message: "/spend 5m"}
+
+	kept, collapsed := dedupeCherryPicks([]*Commit{onRelease2, unrelated, onRelease1, anonymous, original})
+	assert.Equal(t, []*Commit{unrelated, anonymous, original}, kept)
+	assert.Equal(t, 2, collapsed)
+}
+
+func TestDedupeCherryPicksWithoutTheOriginal(t *testing.T) {
+	newest := &Commit{Hash: "2222222222", Message: "/spend 1h\n(cherry picked from commit 1111111111)"}
+	oldest := &Commit{Hash: "3333333333", Message: "/spend 1h\n(cherry picked from commit 1111111111)"}
+
+	kept, collapsed := dedupeCherryPicks([]*Commit{newest, oldest})
+	assert.Equal(t, []*Commit{oldest}, kept)
+	assert.Equal(t, 1, collapsed)
+}
+
+func TestCollector_CollectWithDedupe(t *testing.T) {
+	commits := []*Commit{
+		{Hash: "2222222222", Message: "/spend 1h\n(cherry picked from commit 1111111111)"},
+		{Hash: "1111111111", Message: "/spend 1h"},
+	}
+	collection := (&Collector{Dedupe: DedupeCherryPick}).Collect(commits)
+	assert.Equal(t, uint64(60), collection.TimeSpent.ToMinutes())
+	assert.Equal(t, 1, collection.Collapsed)
+
+	collection = (&Collector{}).Collect(commits)
+	assert.Equal(t, uint64(120), collection.TimeSpent.ToMinutes())
+}
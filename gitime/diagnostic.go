@@ -0,0 +1,139 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"strings"
+)
+
+// Severities of the diagnostics
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Diagnostic codes of the warnings, numbered after the codes of the violations of the Linter
+const (
+	CodeDirectiveOverMax         = "GT006"
+	CodeScheduleConflict         = "GT007"
+	CodeCorrectionUnknown        = "GT008"
+	CodeGitlabProjectSkipped     = "GT009"
+	CodeMergeRequestsUnavailable = "GT010"
+	CodeMergeRequestDuplicate    = "GT011"
+	CodeStdinCommitUnresolved    = "GT012"
+	CodeSessionUnresolved        = "GT013"
+	CodeLossyEncoding            = "GT014"
+	CodeLabelUnknown             = "GT015"
+	CodeExcludedOverMax          = "GT016"
+	CodeRunLogUnwritten          = "GT017"
+	CodeCorrectionAmbiguous      = "GT018"
+	CodeChangesUnreadable        = "GT019"
+)
+
+// DiagnosticCode documents a diagnostic code.  Codes are stable : they are never renumbered nor reused.
+type DiagnosticCode struct {
+	Code string `json:"code"`
+	// Severity is the severity of the diagnostics of the code, unless denied or allowed
+	Severity string `json:"severity"`
+	// Description is the locale key of the description of the code
+	Description string `json:"-"`
+}
+
+// DiagnosticCodes is the registry of the diagnostic codes, in order
+var DiagnosticCodes = []*DiagnosticCode{
+	{Code: CodeMinGranularity, Severity: SeverityWarning, Description: "DiagnosticMinGranularity"},
+	{Code: CodeManyDirectives, Severity: SeverityWarning, Description: "DiagnosticManyDirectives"},
+	{Code: CodeDirectiveNotOnLast, Severity: SeverityWarning, Description: "DiagnosticDirectiveNotOnLast"},
+	{Code: CodeRange, Severity: SeverityWarning, Description: "DiagnosticRange"},
+	{Code: CodeAmbiguousM, Severity: SeverityWarning, Description: "DiagnosticAmbiguousM"},
+	{Code: CodeDirectiveOverMax, Severity: SeverityWarning, Description: "DiagnosticDirectiveOverMax"},
+	{Code: CodeScheduleConflict, Severity: SeverityWarning, Description: "DiagnosticScheduleConflict"},
+	{Code: CodeCorrectionUnknown, Severity: SeverityWarning, Description: "DiagnosticCorrectionUnknown"},
+	{Code: CodeGitlabProjectSkipped, Severity: SeverityWarning, Description: "DiagnosticGitlabProjectSkipped"},
+	{Code: CodeMergeRequestsUnavailable, Severity: SeverityWarning, Description: "DiagnosticMergeRequestsUnavailable"},
+	{Code: CodeMergeRequestDuplicate, Severity: SeverityWarning, Description: "DiagnosticMergeRequestDuplicate"},
+	{Code: CodeStdinCommitUnresolved, Severity: SeverityWarning, Description: "DiagnosticStdinCommitUnresolved"},
+	{Code: CodeSessionUnresolved, Severity: SeverityWarning, Description: "DiagnosticSessionUnresolved"},
+	{Code: CodeLossyEncoding, Severity: SeverityWarning, Description: "DiagnosticLossyEncoding"},
+	{Code: CodeLabelUnknown, Severity: SeverityWarning, Description: "DiagnosticLabelUnknown"},
+	{Code: CodeExcludedOverMax, Severity: SeverityWarning, Description: "DiagnosticExcludedOverMax"},
+	{Code: CodeRunLogUnwritten, Severity: SeverityWarning, Description: "DiagnosticRunLogUnwritten"},
+	{Code: CodeCorrectionAmbiguous, Severity: SeverityWarning, Description: "DiagnosticCorrectionAmbiguous"},
+	{Code: CodeChangesUnreadable, Severity: SeverityWarning, Description: "DiagnosticChangesUnreadable"},
+}
+
+// FindDiagnosticCode returns the registered diagnostic code, or nil
+func FindDiagnosticCode(code string) *DiagnosticCode {
+	for _, diagnostic := range DiagnosticCodes {
+		if diagnostic.Code == strings.ToUpper(code) {
+			return diagnostic
+		}
+	}
+
+	return nil
+}
+
+// DiagnosticLevels change the severity of some diagnostic codes, like CI does to fail on specific warnings
+type DiagnosticLevels struct {
+	// Deny are the codes promoted to errors
+	Deny []string
+	// Allow are the codes silenced altogether
+	Allow []string
+}
+
+// Validate tells whether all the codes are registered, and none is both denied and allowed
+func (l *DiagnosticLevels) Validate() error {
+	for _, code := range append(append([]string{}, l.Deny...), l.Allow...) {
+		if FindDiagnosticCode(code) == nil {
+			return fmt.Errorf(locale.Tf("DiagnosticUnknown", code))
+		}
+	}
+	for _, code := range l.Deny {
+		if containsCode(l.Allow, code) {
+			return fmt.Errorf(locale.Tf("DiagnosticDeniedAndAllowed", code))
+		}
+	}
+
+	return nil
+}
+
+// Apply returns the warnings that are not allowed, with their severity set by the registry or by the denials
+func (l *DiagnosticLevels) Apply(warnings []*Warning) []*Warning {
+	kept := make([]*Warning, 0, len(warnings))
+	for _, warning := range warnings {
+		if containsCode(l.Allow, warning.Code) {
+			continue
+		}
+		warning.Severity = SeverityWarning
+		if registered := FindDiagnosticCode(warning.Code); registered != nil {
+			warning.Severity = registered.Severity
+		}
+		if containsCode(l.Deny, warning.Code) {
+			warning.Severity = SeverityError
+		}
+		kept = append(kept, warning)
+	}
+
+	return kept
+}
+
+// HasErrors tells whether any of the warnings has the error severity
+func HasErrors(warnings []*Warning) bool {
+	for _, warning := range warnings {
+		if warning.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsCode(codes []string, code string) bool {
+	for _, c := range codes {
+		if strings.EqualFold(c, code) {
+			return true
+		}
+	}
+
+	return false
+}
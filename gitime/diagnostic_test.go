@@ -0,0 +1,54 @@
+package gitime
+
+import (
+	"github.com/goutte/git-spend/locale"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestDiagnosticCodesAreStableAndDocumented(t *testing.T) {
+	codeRegex := regexp.MustCompile(`^GT[0-9]{3}$`)
+	seen := map[string]bool{}
+	for _, code := range DiagnosticCodes {
+		assert.Regexp(t, codeRegex, code.Code)
+		assert.False(t, seen[code.Code], code.Code)
+		seen[code.Code] = true
+		assert.Contains(t, []string{SeverityWarning, SeverityError}, code.Severity)
+		assert.NotEqual(t, code.Description, locale.T(code.Description), code.Code)
+	}
+}
+
+func TestDiagnosticLevels_Validate(t *testing.T) {
+	assert.NoError(t, (&DiagnosticLevels{Deny: []string{"GT006"}, Allow: []string{"gt001"}}).Validate())
+	assert.Error(t, (&DiagnosticLevels{Deny: []string{"GT999"}}).Validate())
+	assert.Error(t, (&DiagnosticLevels{Deny: []string{"GT006"}, Allow: []string{"GT006"}}).Validate())
+}
+
+func TestDiagnosticLevels_Apply(t *testing.T) {
+	levels := &DiagnosticLevels{Deny: []string{"GT006"}, Allow: []string{"GT007"}}
+	warnings := levels.Apply([]*Warning{
+		{Code: CodeDirectiveOverMax, Message: "over"},
+		{Code: CodeScheduleConflict, Message: "conflict"},
+		{Code: CodeCorrectionUnknown, Message: "unknown"},
+	})
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, SeverityError, warnings[0].Severity)
+		assert.Equal(t, SeverityWarning, warnings[1].Severity)
+	}
+	assert.True(t, HasErrors(warnings))
+	assert.False(t, HasErrors((&DiagnosticLevels{}).Apply(warnings)))
+}
+
+func TestCollector_CollectWarningsHaveCodes(t *testing.T) {
+	collector := &Collector{MaxDirective: &TimeSpent{Hours: 2}, Linter: &Linter{NoRange: true}}
+	collection := collector.Collect([]*Commit{
+		{Hash: "abcdef0123", AuthorName: "Alice", Message: "/spend 3h\n/spend 1-2h"},
+	})
+	for _, warning := range append(collection.Warnings, collection.Violations...) {
+		assert.NotNil(t, FindDiagnosticCode(warning.Code), warning.Message)
+	}
+	if assert.Len(t, collection.Warnings, 1) {
+		assert.Equal(t, "/spend 3h", collection.Warnings[0].Location)
+	}
+}
@@ -0,0 +1,94 @@
+package gitime
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Directive is a single /spend directive that was found in a message
+type Directive struct {
+	// Line is the (trimmed) line of the message that holds the directive
+	Line string
+	// TimeSpent is the time spent as written, not normalized
+	TimeSpent *TimeSpent
+	// Range holds the bounds of a directive spending a range of time, like "/spend 1-2h", or nil.
+	// TimeSpent is then the range collapsed under its policy.
+	Range *TimeRange
+	// Date is the optional date written after the time, or nil
+	Date *time.Time
+	// Label is the optional bracketed tag written after the time (and date), like oncall in "/spend 2h [oncall]"
+	Label string
+	// Note is the optional free text written after the time (and date, and label)
+	Note string
+	// AmbiguousM is how the bare "m" unit of the directive was read, AmbiguousMinutes or AmbiguousMonths,
+	// or empty when the directive has no such unit
+	AmbiguousM string
+	// Spans locates the directive within the message, when asked for with CollectDirectivesWithSpans
+	Spans *Spans
+}
+
+// BucketDate is the date the time spent by the directive of the commit is attributed to :
+// the date written in the directive, or else the bucket date of the commit.
+func (d *Directive) BucketDate(commit *Commit) time.Time {
+	if d.Date != nil {
+		return *d.Date
+	}
+
+	return commit.BucketDate()
+}
+
+var directiveDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	time.DateTime,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// parseDirectiveSuffix reads the optional date and note written after the time.
+// It also returns how many bytes the date takes, as written.
+func parseDirectiveSuffix(suffix string) (*time.Time, int, string) {
+	suffix = strings.TrimSpace(suffix)
+	matches := dateRegex.FindStringSubmatch(suffix)
+	if matches == nil {
+		return nil, 0, suffix
+	}
+
+	dateString := matches[dateRegex.SubexpIndex("date")]
+	for _, layout := range directiveDateLayouts {
+		date, err := time.ParseInLocation(layout, dateString, time.Local)
+		if err == nil {
+			return &date, len(dateString), strings.TrimSpace(suffix[len(matches[0]):])
+		}
+	}
+
+	return nil, 0, suffix
+}
+
+// readDirectiveSuffix reads the optional date, label and note written after the time, which ends at the end byte
+// of the (trimmed) line, and locates them in the spans.  A leading bracketed token is a label,
+// and everything after it is the note, so that "[oncall] pager at 3am" is labelled oncall.
+func readDirectiveSuffix(line string, end int, spans *Spans) (*time.Time, string, string) {
+	suffix := line[end:]
+	date, dateLength, rest := parseDirectiveSuffix(suffix)
+	if date != nil {
+		start := end + len(suffix) - len(strings.TrimLeftFunc(suffix, unicode.IsSpace))
+		spans.Date = &Span{Start: start, End: start + dateLength}
+	}
+	label, note := "", rest
+	if matches := labelRegex.FindStringSubmatch(rest); matches != nil {
+		label = matches[labelRegex.SubexpIndex("label")]
+		note = strings.TrimSpace(rest[len(matches[0]):])
+		// The line is trimmed, so the label and the note always end it
+		start := len(line) - len(rest)
+		spans.Label = &Span{Start: start, End: start + len(label) + 2}
+	}
+	if note != "" {
+		spans.Note = &Span{Start: len(line) - len(note), End: len(line)}
+	}
+
+	return date, label, note
+}
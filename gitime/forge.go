@@ -0,0 +1,133 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Forges, which each spell issue references their own way
+const (
+	ForgeGitlab = "gitlab"
+	ForgeGithub = "github"
+	ForgeJira   = "jira"
+	ForgeGitea  = "gitea"
+	ForgeAzure  = "azure"
+	ForgeCustom = "custom"
+)
+
+// SupportedForges lists the forges accepted by NewForge
+var SupportedForges = []string{ForgeGitlab, ForgeGithub, ForgeJira, ForgeGitea, ForgeAzure, ForgeCustom}
+
+// DefaultForge is used when the forge was neither specified nor detected, since /spend comes from GitLab
+const DefaultForge = ForgeGitlab
+
+// The reference grammars of each forge.  References are in the "ref" group,
+// and what comes before it only makes sure a reference is not the tail of a word.
+var forgeGrammars = map[string]string{
+	// #123, group/project#123, !45
+	ForgeGitlab: "(?:^|[^\\w/#!&-])(?P<ref>(?:[\\w.-]+(?:/[\\w.-]+)+)?[#!][0-9]+)\\b",
+	// #123, owner/repo#123
+	ForgeGithub: "(?:^|[^\\w/#&-])(?P<ref>(?:[\\w.-]+/[\\w.-]+)?#[0-9]+)\\b",
+	// ABC-123
+	ForgeJira: "\\b(?P<ref>[A-Z][A-Z0-9_]+-[0-9]+)\\b",
+	// #123, owner/repo#123, !45
+	ForgeGitea: "(?:^|[^\\w/#!&-])(?P<ref>(?:[\\w.-]+/[\\w.-]+)?[#!][0-9]+)\\b",
+	// AB#123
+	ForgeAzure: "\\b(?P<ref>AB#[0-9]+)\\b",
+}
+
+// Forge extracts the issue references of messages, using the grammar of a forge
+type Forge struct {
+	Name  string
+	regex *regexp.Regexp
+}
+
+// NewForge returns the forge of that name.  The custom forge uses issueRegex, whose "ref" group
+// (or else whole match) is the reference.  Other forges also accept issueRegex, to override their grammar.
+func NewForge(name string, issueRegex string) (*Forge, error) {
+	if name == "" {
+		name = DefaultForge
+	}
+	grammar, found := forgeGrammars[name]
+	if issueRegex != "" {
+		grammar, found = issueRegex, true
+	}
+	if !found {
+		if name == ForgeCustom {
+			return nil, fmt.Errorf(locale.T("ForgeCustomWithoutRegex"))
+		}
+		return nil, fmt.Errorf(locale.Tf("ForgeUnsupported", name, strings.Join(SupportedForges, ", ")))
+	}
+	regex, err := regexp.Compile(grammar)
+	if err != nil {
+		return nil, fmt.Errorf(locale.Tf("ForgeRegexInvalid", grammar, err.Error()))
+	}
+
+	return &Forge{Name: name, regex: regex}, nil
+}
+
+// References returns the issue references found in the message, in order, without duplicates
+func (f *Forge) References(message string) []string {
+	references := make([]string, 0)
+	seen := make(map[string]bool)
+	refIndex := f.regex.SubexpIndex("ref")
+	for _, matches := range f.regex.FindAllStringSubmatch(message, -1) {
+		reference := matches[0]
+		if refIndex != -1 {
+			reference = matches[refIndex]
+		}
+		if reference == "" || seen[reference] {
+			continue
+		}
+		seen[reference] = true
+		references = append(references, reference)
+	}
+
+	return references
+}
+
+// scpLikeRemoteRegex matches the remote URLs like git@github.com:owner/repo.git
+var scpLikeRemoteRegex = regexp.MustCompile(`^(?:[^@/]+@)?(?P<host>[^:/]+):(?P<path>[^/].*)$`)
+
+// DetectForge guesses the forge from the host of the URL of a remote, like git@github.com:owner/repo.git.
+// Only the host is considered, so that a project named like a forge does not fool it.
+// It returns an empty string when the forge cannot be guessed.
+func DetectForge(remoteURL string) string {
+	host := strings.ToLower(remoteHost(remoteURL))
+	if host == "" {
+		return ""
+	}
+	// Self-hosted instances are often named after their forge, like gitlab.example.org
+	labels := make(map[string]bool)
+	for _, label := range strings.Split(host, ".") {
+		labels[label] = true
+	}
+	switch {
+	case labels["github"]:
+		return ForgeGithub
+	case labels["gitlab"]:
+		return ForgeGitlab
+	case labels["gitea"], host == "codeberg.org":
+		return ForgeGitea
+	case host == "dev.azure.com", strings.HasSuffix(host, ".dev.azure.com"), strings.HasSuffix(host, ".visualstudio.com"):
+		return ForgeAzure
+	}
+
+	return ""
+}
+
+// remoteHost returns the host of the URL of a remote, for both URLs and scp-like addresses,
+// or an empty string for local paths.
+func remoteHost(remoteURL string) string {
+	if parsed, err := url.Parse(remoteURL); err == nil && parsed.Host != "" {
+		return parsed.Hostname()
+	}
+	if matches := scpLikeRemoteRegex.FindStringSubmatch(remoteURL); matches != nil {
+		return matches[scpLikeRemoteRegex.SubexpIndex("host")]
+	}
+
+	return ""
+}
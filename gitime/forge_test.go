@@ -0,0 +1,99 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDetectForge(t *testing.T) {
+	tests := []struct {
+		url   string
+		forge string
+	}{
+		{"git@github.com:Goutte/git-spend.git", ForgeGithub},
+		{"https://github.com/Goutte/git-spend", ForgeGithub},
+		{"git@gitlab.com:group/sub/project.git", ForgeGitlab},
+		{"https://gitlab.example.org/group/project.git", ForgeGitlab},
+		{"https://codeberg.org/owner/repo.git", ForgeGitea},
+		{"https://gitea.example.org/owner/repo.git", ForgeGitea},
+		{"https://dev.azure.com/org/project/_git/repo", ForgeAzure},
+		{"https://org.visualstudio.com/project/_git/repo", ForgeAzure},
+		{"git@ssh.dev.azure.com:v3/org/project/repo", ForgeAzure},
+		{"git@gitlab.com:me/github-tools.git", ForgeGitlab},
+		{"https://github.com/me/gitlab-migration.git", ForgeGithub},
+		{"ssh://git@gitlab.example.org:2222/group/gitea-backup.git", ForgeGitlab},
+		{"https://git.example.org/mirrors/github.git", ""},
+		{"https://mygithub.example.org/owner/repo.git", ""},
+		{"git@bitbucket.org:team/repo.git", ""},
+		{"/srv/git/repo.git", ""},
+		{"/srv/git/github.git", ""},
+		{"file:///srv/git/gitlab.git", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.forge, DetectForge(tt.url))
+		})
+	}
+}
+
+func TestForge_References(t *testing.T) {
+	message := "fix: #12 and group/sub/project#34, see !56 (ABC-78, AB#90)\n\nfoo#1 &#38; owner/repo#12 #12"
+	tests := []struct {
+		forge      string
+		references []string
+	}{
+		{ForgeGitlab, []string{"#12", "group/sub/project#34", "!56", "owner/repo#12"}},
+		{ForgeGithub, []string{"#12", "owner/repo#12"}},
+		{ForgeGitea, []string{"#12", "!56", "owner/repo#12"}},
+		{ForgeJira, []string{"ABC-78"}},
+		{ForgeAzure, []string{"AB#90"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.forge, func(t *testing.T) {
+			forge, err := NewForge(tt.forge, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.references, forge.References(message))
+		})
+	}
+}
+
+func TestForge_ReferencesCustom(t *testing.T) {
+	forge, err := NewForge(ForgeCustom, "\\[(?P<ref>T[0-9]+)\\]")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"T42", "T7"}, forge.References("[T42] fix [T7]"))
+
+	forge, err = NewForge(ForgeCustom, "T[0-9]+")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"T42"}, forge.References("[T42] fix"))
+
+	_, err = NewForge(ForgeCustom, "")
+	assert.Error(t, err)
+	_, err = NewForge(ForgeCustom, "(")
+	assert.Error(t, err)
+	_, err = NewForge("sourceforge", "")
+	assert.Error(t, err)
+}
+
+func TestNewForgeDefaultsToGitlab(t *testing.T) {
+	forge, err := NewForge("", "")
+	require.NoError(t, err)
+	assert.Equal(t, ForgeGitlab, forge.Name)
+}
+
+func TestCollector_CollectGroupByIssue(t *testing.T) {
+	forge, err := NewForge(ForgeGithub, "")
+	require.NoError(t, err)
+	collector := &Collector{GroupBy: GroupByIssue, Forge: forge}
+	collection := collector.Collect([]*Commit{
+		{Message: "fix #12 and #13\n\n/spend 1h"},
+		{Message: "feat: #13\n\n/spend 3h"},
+		{Message: "chore\n\n/spend 30m"},
+	})
+	require.Len(t, collection.Groups, 3)
+	assert.Equal(t, "#13", collection.Groups[0].Key)
+	assert.Equal(t, "#12", collection.Groups[1].Key)
+	assert.Equal(t, "", collection.Groups[2].Key)
+	assert.Equal(t, uint64(270), collection.TimeSpent.ToMinutes())
+}
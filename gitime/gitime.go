@@ -1,9 +1,12 @@
 package gitime
 
 import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Keep these sorted by decreasing priority, since first match breaks.
@@ -17,50 +20,174 @@ var expressions = []*regexp.Regexp{
 // If no time unit is specified, minutes are assumed.
 func CollectTimeSpent(message string) *TimeSpent {
 	ts := &TimeSpent{}
+	for _, directive := range CollectDirectives(message) {
+		ts.Add(directive.TimeSpent)
+	}
+
+	return ts
+}
+
+// CollectDirectives returns the directives spending some time found in the message, in order.
+func CollectDirectives(message string) []*Directive {
+	return collectDirectives(message, false)
+}
+
+// CollectDirectivesWithSpans is like CollectDirectives, but also locates the parts of each directive
+// within the message, as byte offsets.  See Spans.
+func CollectDirectivesWithSpans(message string) []*Directive {
+	return collectDirectives(message, true)
+}
+
+func collectDirectives(message string, withSpans bool) []*Directive {
+	directives := make([]*Directive, 0)
 	message = strings.ReplaceAll(message, "\r", "\n")
 	lines := strings.Split(message, "\n")
 
+	offset := 0
 	for _, line := range lines {
-		lineTs := extractTimeSpentFromLine(strings.TrimSpace(line))
-		if lineTs == nil {
+		lineOffset := offset
+		offset += len(line) + 1
+		if isScissorsLine(line) {
+			break
+		}
+		if isCommentLine(line) {
+			continue
+		}
+		directive := extractDirectiveFromLine(strings.TrimSpace(line))
+		if directive == nil || directive.TimeSpent.IsZero() {
 			continue
 		}
 
-		ts.Add(lineTs)
+		if withSpans {
+			leading := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+			directive.Spans = directive.Spans.shift(lineOffset + leading)
+		} else {
+			directive.Spans = nil
+		}
+		directives = append(directives, directive)
 	}
 
-	return ts
+	return directives
+}
+
+// ParseTimeSpent reads a duration written like in directives, such as "1h30" or "2 days"
+func ParseTimeSpent(duration string) (*TimeSpent, error) {
+	if strings.ContainsAny(duration, "\r\n") {
+		return nil, fmt.Errorf(locale.Tf("DurationUnparsable", duration))
+	}
+	ts := CollectTimeSpent("/spend " + duration)
+	if ts.IsZero() {
+		return nil, fmt.Errorf(locale.Tf("DurationUnparsable", duration))
+	}
+
+	return ts, nil
+}
+
+// HasTimeSpent tells whether the message holds at least one directive with some time in it
+func HasTimeSpent(message string) bool {
+	return !CollectTimeSpent(message).IsZero()
 }
 
-func extractTimeSpentFromLine(line string) *TimeSpent {
+// Coverage returns the ratio (between 0 and 1) of messages holding some time spent.
+// This is the compliance metric of a repository : the fraction of commits that do log time.
+// Coverage of no messages at all is zero.
+func Coverage(messages []string) float64 {
+	if len(messages) == 0 {
+		return 0.0
+	}
+
+	covered := 0
+	for _, message := range messages {
+		if HasTimeSpent(message) {
+			covered++
+		}
+	}
+
+	return float64(covered) / float64(len(messages))
+}
+
+func extractDirectiveFromLine(line string) *Directive {
+	if directive := extractRangeDirective(line); directive != nil {
+		return directive
+	}
 	for _, expression := range expressions {
-		ts := extractTimeSpentUsingRegexp(line, expression)
-		if ts != nil {
-			return ts
+		directive := extractDirectiveUsingRegexp(line, expression)
+		if directive == nil {
+			continue
 		}
+		if directive.TimeSpent.IsZero() && WordNumbers != nil {
+			if inWords := extractDirectiveUsingWords(line, WordNumbers); inWords != nil {
+				return inWords
+			}
+		}
+		return directive
 	}
 
 	return nil
 }
 
-func extractTimeSpentUsingRegexp(line string, r *regexp.Regexp) *TimeSpent {
-	matches := r.FindStringSubmatch(line)
-	if len(matches) == 0 {
+func extractDirectiveUsingRegexp(line string, r *regexp.Regexp) *Directive {
+	indices := r.FindStringSubmatchIndex(line)
+	if indices == nil {
 		return nil
 	}
+	matches := make([]string, len(indices)/2)
+	for i := range matches {
+		if indices[2*i] != -1 {
+			matches[i] = line[indices[2*i]:indices[2*i+1]]
+		}
+	}
 
 	months := extractTimeComponent(matches, r, "months")
 	weeks := extractTimeComponent(matches, r, "weeks")
 	days := extractTimeComponent(matches, r, "days")
 	hours := extractTimeComponent(matches, r, "hours")
 	minutes := extractTimeComponent(matches, r, "minutes")
+	ambiguousM := ""
+	if unitIndex := r.SubexpIndex("minutesUnit"); unitIndex != -1 && matches[unitIndex] == bareM {
+		ambiguousM = bareMReading()
+		if ambiguousM == AmbiguousMonths {
+			months, minutes = months+minutes, 0
+		}
+	}
+
+	// The tail is only there to work around the lack of negative lookahead, give it back.
+	end := indices[1]
+	tailIndex := r.SubexpIndex("tail")
+	if tailIndex != -1 && indices[2*tailIndex] != -1 {
+		end = indices[2*tailIndex]
+	}
+	spans := &Spans{
+		Directive: Span{Start: 0, End: len(line)},
+		Keyword:   *submatchSpan(indices, r, "keyword"),
+		Tokens:    make([]Span, 0),
+	}
+	for _, component := range spanComponents {
+		token := submatchSpan(indices, r, component)
+		if token == nil {
+			continue
+		}
+		if unit := submatchSpan(indices, r, component+"Unit"); unit != nil {
+			token.End = unit.End
+		}
+		spans.Tokens = append(spans.Tokens, *token)
+	}
+	date, label, note := readDirectiveSuffix(line, end, spans)
 
-	return &TimeSpent{
-		Months:  months,
-		Weeks:   weeks,
-		Days:    days,
-		Hours:   hours,
-		Minutes: minutes,
+	return &Directive{
+		Line: line,
+		TimeSpent: &TimeSpent{
+			Months:  months,
+			Weeks:   weeks,
+			Days:    days,
+			Hours:   hours,
+			Minutes: minutes,
+		},
+		Date:       date,
+		Label:      label,
+		Note:       note,
+		AmbiguousM: ambiguousM,
+		Spans:      spans,
 	}
 }
 
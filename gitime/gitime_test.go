@@ -5,6 +5,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"os"
 	"testing"
+	"time"
 )
 
 type TestData struct {
@@ -26,6 +27,10 @@ type CollectTestExpected struct {
 	Months    *uint64 `yaml:"months"`
 	String    *string `yaml:"string"`
 	StringRaw *string `yaml:"string_raw"`
+	// Notes, Labels and Dates are those of each directive, in order
+	Notes  *[]string `yaml:"notes"`
+	Labels *[]string `yaml:"labels"`
+	Dates  *[]string `yaml:"dates"`
 }
 
 func TestCollectTimeSpent(t *testing.T) {
@@ -76,6 +81,45 @@ func TestCollectTimeSpent(t *testing.T) {
 					t.Errorf("CollectTimeSpent(%s).String() = %v, want %v", tt.Message, got, *tt.Expected.StringRaw)
 				}
 			}
+			if tt.Expected.Notes != nil {
+				got := make([]string, 0)
+				for _, directive := range CollectDirectives(tt.Message) {
+					got = append(got, directive.Note)
+				}
+				require.Equal(t, *tt.Expected.Notes, got, "notes of CollectDirectives(%s)", tt.Message)
+			}
+			if tt.Expected.Labels != nil {
+				got := make([]string, 0)
+				for _, directive := range CollectDirectives(tt.Message) {
+					got = append(got, directive.Label)
+				}
+				require.Equal(t, *tt.Expected.Labels, got, "labels of CollectDirectives(%s)", tt.Message)
+			}
+			if tt.Expected.Dates != nil {
+				got := make([]string, 0)
+				for _, directive := range CollectDirectives(tt.Message) {
+					if directive.Date == nil {
+						got = append(got, "")
+					} else {
+						got = append(got, directive.Date.Format(time.DateTime))
+					}
+				}
+				require.Equal(t, *tt.Expected.Dates, got, "dates of CollectDirectives(%s)", tt.Message)
+			}
 		})
 	}
 }
+
+func TestCoverage(t *testing.T) {
+	require.Equal(t, 0.0, Coverage([]string{}))
+	require.Equal(t, 0.5, Coverage([]string{
+		"feat: a thing\n\n/spend 1h",
+		"fix: another thing",
+	}))
+	require.Equal(t, 0.25, Coverage([]string{
+		"/spent 10m",
+		"/spend nothing",
+		"/spend 0m",
+		"docs: /spend 1h not at line start",
+	}))
+}
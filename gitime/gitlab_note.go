@@ -0,0 +1,110 @@
+package gitime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// The lengths GitLab uses for time tracking, whatever the schedule of the repository,
+// since a /spend quick action is converted by GitLab and not by us.
+const (
+	gitlabMinute = int64(60)
+	gitlabHour   = 60 * gitlabMinute
+	gitlabDay    = 8 * gitlabHour
+	gitlabWeek   = 5 * gitlabDay
+	gitlabMonth  = 4 * gitlabWeek
+	// gitlabYear is a calendar year of 365.25 days, and not a year of working days
+	gitlabYear = int64(31557600)
+)
+
+// GitlabSeconds returns the seconds GitLab would record for the time spent, like for a /spend quick action
+func GitlabSeconds(ts *TimeSpent) int64 {
+	seconds := ts.Months*float64(gitlabMonth) +
+		ts.Weeks*float64(gitlabWeek) +
+		ts.Days*float64(gitlabDay) +
+		ts.Hours*float64(gitlabHour) +
+		ts.Minutes*float64(gitlabMinute)
+
+	return int64(math.Round(seconds))
+}
+
+// FormatGitlabDuration writes the seconds the way GitLab shows time spent, like "1w 2d 3h 30m".
+// Units are in decreasing order, without plural, and zero units are left out.
+func FormatGitlabDuration(seconds int64) string {
+	if seconds < 0 {
+		return "-" + FormatGitlabDuration(-seconds)
+	}
+	var years, months, weeks, days, hours, minutes int64
+	if seconds >= gitlabYear && seconds%gitlabYear < seconds%gitlabMonth {
+		// GitLab only counts years when they divide the duration better than months do
+		years = seconds / gitlabYear
+		seconds %= gitlabYear
+		months = seconds / gitlabMonth
+		seconds %= gitlabMonth
+		days = seconds / gitlabDay
+		seconds %= gitlabDay
+	} else {
+		months = seconds / gitlabMonth
+		seconds %= gitlabMonth
+		weeks = seconds / gitlabWeek
+		seconds %= gitlabWeek
+		days = seconds / gitlabDay
+		seconds %= gitlabDay
+	}
+	hours = seconds / gitlabHour
+	seconds %= gitlabHour
+	minutes = seconds / gitlabMinute
+	seconds %= gitlabMinute
+
+	components := make([]string, 0, 7)
+	for _, component := range []struct {
+		value int64
+		unit  string
+	}{
+		{years, "y"},
+		{months, "mo"},
+		{weeks, "w"},
+		{days, "d"},
+		{hours, "h"},
+		{minutes, "m"},
+		{seconds, "s"},
+	} {
+		if component.value > 0 {
+			components = append(components, fmt.Sprintf("%d%s", component.value, component.unit))
+		}
+	}
+
+	return strings.Join(components, " ")
+}
+
+// GitlabTimeSpentNote returns the system note GitLab writes when time is spent on an issue,
+// like "added 1h 30m of time spent at 2024-03-02", or "subtracted 30m of time spent at 2024-03-02".
+// It is empty when no time is spent.
+func GitlabTimeSpentNote(seconds int64, date time.Time) string {
+	if seconds == 0 {
+		return ""
+	}
+	action := "added"
+	if seconds < 0 {
+		action = "subtracted"
+		seconds = -seconds
+	}
+
+	return fmt.Sprintf("%s %s of time spent at %s", action, FormatGitlabDuration(seconds), date.Format(time.DateOnly))
+}
+
+// GitlabTimeSpentNotes returns the system notes GitLab would write for the directives of the commit,
+// one per directive, dated like the directive or else like the commit.
+func GitlabTimeSpentNotes(commit *Commit) []string {
+	notes := make([]string, 0)
+	for _, directive := range CollectDirectives(commit.Message) {
+		note := GitlabTimeSpentNote(GitlabSeconds(directive.TimeSpent), directive.BucketDate(commit))
+		if note != "" {
+			notes = append(notes, note)
+		}
+	}
+
+	return notes
+}
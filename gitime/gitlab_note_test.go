@@ -0,0 +1,60 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFormatGitlabDuration(t *testing.T) {
+	// Golden outputs of GitLab, as shown in the time tracking widget and system notes
+	golden := map[int64]string{
+		60:       "1m",
+		1800:     "30m",
+		3600:     "1h",
+		5400:     "1h 30m",
+		28800:    "1d",
+		30600:    "1d 30m",
+		144000:   "1w",
+		151200:   "1w 2h",
+		331200:   "2w 1d 4h",
+		576000:   "1mo",
+		752400:   "1mo 1w 1d 1h",
+		752460:   "1mo 1w 1d 1h 1m",
+		1188:     "19m 48s",
+		45:       "45s",
+		-5400:    "-1h 30m",
+		31557600: "1y",
+	}
+	for seconds, expected := range golden {
+		assert.Equal(t, expected, FormatGitlabDuration(seconds), "%d seconds", seconds)
+	}
+}
+
+func TestGitlabSecondsIgnoresTheSchedule(t *testing.T) {
+	defer UseSchedule(CurrentSchedule())
+	UseSchedule(Schedule{MinutesInOneHour: 60, HoursInOneDay: 7, DaysInOneWeek: 4, WeeksInOneMonth: 4})
+	assert.Equal(t, int64(28800), GitlabSeconds(&TimeSpent{Days: 1}))
+	assert.Equal(t, int64(5400), GitlabSeconds(&TimeSpent{Hours: 1.5}))
+	assert.Equal(t, int64(576000+144000), GitlabSeconds(&TimeSpent{Months: 1, Weeks: 1}))
+}
+
+func TestGitlabTimeSpentNote(t *testing.T) {
+	date := time.Date(2024, 3, 2, 23, 30, 0, 0, time.UTC)
+	assert.Equal(t, "added 1h 30m of time spent at 2024-03-02", GitlabTimeSpentNote(5400, date))
+	assert.Equal(t, "subtracted 30m of time spent at 2024-03-02", GitlabTimeSpentNote(-1800, date))
+	assert.Equal(t, "", GitlabTimeSpentNote(0, date))
+}
+
+func TestGitlabTimeSpentNotes(t *testing.T) {
+	defer func(location *time.Location) { Location = location }(Location)
+	Location = time.UTC
+	commit := &Commit{
+		Date:    time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC),
+		Message: "feat: x\n\n/spend 1.5h\n/spend 2d 2024-03-01\n/spend 0h",
+	}
+	assert.Equal(t, []string{
+		"added 1h 30m of time spent at 2024-03-04",
+		"added 2d of time spent at 2024-03-01",
+	}, GitlabTimeSpentNotes(commit))
+}
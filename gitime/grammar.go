@@ -2,16 +2,33 @@ package gitime
 
 import "regexp"
 
-var commandRegex = "^\\s*/spen[dt]\\s*:?\\s*"
+// unitSpelling is how a unit of time may be written in directives
+type unitSpelling struct {
+	// Pattern is the regular expression of the unit, as understood in directives
+	Pattern string
+	// Abbreviation is the shortest spelling understood by Pattern, used by the compact format
+	Abbreviation string
+}
+
+// The spellings of the units.  Parsing and compact formatting both use them, so that they always agree.
+var (
+	monthsSpelling  = unitSpelling{Pattern: "months?|mo", Abbreviation: "mo"}
+	weeksSpelling   = unitSpelling{Pattern: "weeks?|we?", Abbreviation: "w"}
+	daysSpelling    = unitSpelling{Pattern: "days?|da?", Abbreviation: "d"}
+	hoursSpelling   = unitSpelling{Pattern: "hours?|ho?", Abbreviation: "h"}
+	minutesSpelling = unitSpelling{Pattern: "minutes?|mins?|mi?", Abbreviation: "m"}
+)
+
+var commandRegex = "^\\s*(?P<keyword>/spen[dt])\\s*:?\\s*"
 var floatRegex = "[0-9]+[.]?[0-9]*|[0-9]*[.]?[0-9]+"
 
 // no negative lookahead in regexp, so we hack around it (to ignore datetime suffix)
 // there's also regexp2, but its API needs some more work at the time of this writing
-var minutesRegex = "(?P<minutes>" + floatRegex + ")\\s*(?:minutes?|mins?|mi?)?([^-/0-9]|$)"
-var hoursRegex = "(?P<hours>" + floatRegex + ")\\s*(?:hours?|ho?)\\s*"
-var daysRegex = "(?P<days>" + floatRegex + ")\\s*(?:days?|da?)\\s*"
-var weeksRegex = "(?P<weeks>" + floatRegex + ")\\s*(?:weeks?|we?)\\s*"
-var monthsRegex = "(?P<months>" + floatRegex + ")\\s*(?:months?|mo)\\s*"
+var minutesRegex = "(?P<minutes>" + floatRegex + ")\\s*(?P<minutesUnit>" + minutesSpelling.Pattern + ")?(?P<tail>[^-/0-9]|$)"
+var hoursRegex = "(?P<hours>" + floatRegex + ")\\s*(?P<hoursUnit>" + hoursSpelling.Pattern + ")\\s*"
+var daysRegex = "(?P<days>" + floatRegex + ")\\s*(?P<daysUnit>" + daysSpelling.Pattern + ")\\s*"
+var weeksRegex = "(?P<weeks>" + floatRegex + ")\\s*(?P<weeksUnit>" + weeksSpelling.Pattern + ")\\s*"
+var monthsRegex = "(?P<months>" + floatRegex + ")\\s*(?P<monthsUnit>" + monthsSpelling.Pattern + ")\\s*"
 var miP = "(?:" + minutesRegex + ")?"
 var hoP = "(?:" + hoursRegex + ")?"
 var daP = "(?:" + daysRegex + ")?"
@@ -19,3 +36,20 @@ var weP = "(?:" + weeksRegex + ")?"
 var moP = "(?:" + monthsRegex + ")?"
 
 var spentAllRegex = regexp.MustCompile(commandRegex + moP + weP + daP + hoP + miP)
+
+// unitsPattern matches the spelling of any unit, longest first so that "mo" is not read as "m"
+var unitsPattern = monthsSpelling.Pattern + "|" + weeksSpelling.Pattern + "|" + daysSpelling.Pattern + "|" +
+	hoursSpelling.Pattern + "|" + minutesSpelling.Pattern
+
+// rangeRegex matches hedging directives, like /spend 1-2h or /spend 30m-1h.
+// The dash (or en dash) sits between two numbers, so that it is never read as the sign of a negative time spent,
+// and the upper bound must be followed by a space, so that dates like 2023-03-25 are no ranges.
+var rangeRegex = regexp.MustCompile(commandRegex +
+	"(?P<low>" + floatRegex + ")\\s*(?P<lowUnit>" + unitsPattern + ")?\\s*[-–]\\s*" +
+	"(?P<high>" + floatRegex + ")\\s*(?P<highUnit>" + unitsPattern + ")?(?:\\s|$)")
+
+// dateRegex matches the optional date suffix after the time, like GitLab's /spend 1h 2023-03-25
+var dateRegex = regexp.MustCompile("^(?P<date>[0-9]{4}-[0-9]{2}-[0-9]{2}(?:[T ][0-9]{2}:[0-9]{2}(?::[0-9]{2})?(?:Z|[+-][0-9]{2}:?[0-9]{2})?)?)(?:\\s+|$)")
+
+// labelRegex matches the bracketed label that may lead the note of a directive, like [oncall]
+var labelRegex = regexp.MustCompile(`^\[(?P<label>[^\[\]\s]+)]`)
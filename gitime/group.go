@@ -0,0 +1,182 @@
+package gitime
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ways to group the time spent
+const (
+	GroupByNone      = ""
+	GroupByAuthor    = "author"
+	GroupByIssue     = "issue"
+	GroupByRepo      = "repo"
+	GroupByDirectory = "directory"
+	GroupByFile      = "file"
+	GroupByLabel     = "label"
+)
+
+// SupportedGroupings lists the groupings accepted by Collector.GroupBy
+var SupportedGroupings = []string{GroupByAuthor, GroupByIssue, GroupByRepo, GroupByDirectory, GroupByFile, GroupByLabel}
+
+// Group is the time spent by a group of commits, such as the commits of one author
+type Group struct {
+	Key       string
+	TimeSpent *TimeSpent
+	// Commits is how many commits of the group hold some time spent
+	Commits int
+	// First and Last are the bucket dates of the first and last commits holding some time spent
+	First time.Time
+	Last  time.Time
+	// id is what the commits of the group have in common, like the folded name of their author,
+	// whereas Key is its most common spelling
+	id        string
+	spellings map[string]int
+}
+
+// Result is the time spent by a commit, or by a share of it when splitting, attributed to the key of a group
+type Result struct {
+	Commit *Commit
+	// Repository is the name of the repository of the commit
+	Repository string
+	// Key is the key of the group the collector attributed the result to, as spelled in the commit,
+	// or empty when it was not grouping
+	Key       string
+	TimeSpent *TimeSpent
+}
+
+// GroupKey returns the key of the group the collector attributed the result to
+func (r *Result) GroupKey() string {
+	return r.Key
+}
+
+// GroupResults groups the results under the key of each, and sorts the groups by decreasing time spent,
+// and then by key.  Keys of the same identity, like two spellings of the name of an author, are the same group,
+// under their most common spelling.
+func GroupResults(results []*Result, key func(*Result) string, identity func(string) string) []*Group {
+	groups := make(map[string]*Group)
+	for _, result := range results {
+		k := key(result)
+		addToGroup(groups, identity(k), k, result.Commit, result.TimeSpent)
+	}
+
+	return sortGroups(groups)
+}
+
+// SpanDays returns how many calendar days there are from the first to the last activity, both included
+func (g *Group) SpanDays() int {
+	y, m, d := g.First.Date()
+	first := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	y, m, d = g.Last.Date()
+	last := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	return int(last.Sub(first).Hours()/24) + 1
+}
+
+// BucketDate is the date a commit's time spent is attributed to : its author date, in the configured timezone.
+func (c *Commit) BucketDate() time.Time {
+	return c.Date.In(Location)
+}
+
+// groupKey returns the key of the group the commit belongs to, as it is spelled in the commit.
+// A commit referencing several issues is attributed to the first one, so that time is not counted twice.
+func (c *Collector) groupKey(commit *Commit) string {
+	switch c.GroupBy {
+	case GroupByAuthor:
+		if commit.AuthorName != "" {
+			return commit.AuthorName
+		}
+		return commit.AuthorEmail
+	case GroupByIssue:
+		if c.Forge == nil {
+			return ""
+		}
+		references := c.Forge.References(commit.Message)
+		if len(references) == 0 {
+			return ""
+		}
+		return references[0]
+	case GroupByRepo:
+		return c.Repository
+	}
+
+	return ""
+}
+
+// groupId returns what the spellings of the key of a group have in common
+func (c *Collector) groupId(key string) string {
+	if c.GroupBy == GroupByAuthor {
+		return FoldAuthorName(key)
+	}
+	if c.GroupBy == GroupByLabel {
+		return strings.ToLower(key)
+	}
+
+	return key
+}
+
+// addToGroup adds the time spent by the commit to its group, creating it if needed
+func addToGroup(groups map[string]*Group, id string, key string, commit *Commit, ts *TimeSpent) {
+	group, found := groups[id]
+	date := commit.BucketDate()
+	if !found {
+		group = &Group{
+			Key:       key,
+			TimeSpent: &TimeSpent{},
+			First:     date,
+			Last:      date,
+			id:        id,
+			spellings: make(map[string]int),
+		}
+		groups[id] = group
+	}
+	group.TimeSpent.Add(ts)
+	group.Commits++
+	group.spellings[key]++
+	if date.Before(group.First) {
+		group.First = date
+	}
+	if date.After(group.Last) {
+		group.Last = date
+	}
+}
+
+// identity returns what the spellings of the key of the group have in common
+func (g *Group) identity() string {
+	if g.id == "" {
+		return g.Key
+	}
+
+	return g.id
+}
+
+// mostCommonSpelling returns the spelling of the key used by the most commits, the first one in case of a tie
+func (g *Group) mostCommonSpelling() string {
+	best := g.Key
+	for spelling, count := range g.spellings {
+		if count > g.spellings[best] || (count == g.spellings[best] && spelling < best) {
+			best = spelling
+		}
+	}
+
+	return best
+}
+
+// sortGroups sorts the groups by decreasing time spent, and then by key
+func sortGroups(groups map[string]*Group) []*Group {
+	sorted := make([]*Group, 0, len(groups))
+	for _, group := range groups {
+		group.Key = group.mostCommonSpelling()
+		sorted = append(sorted, group)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		mi, mj := sorted[i].TimeSpent.ToMinutes(), sorted[j].TimeSpent.ToMinutes()
+		if mi != mj {
+			return mi > mj
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return sorted
+}
@@ -0,0 +1,95 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCollector_CollectGroupedByAuthor(t *testing.T) {
+	defer func() { Location = time.Local }()
+	Location = time.UTC
+	commits := []*Commit{
+		{AuthorName: "Bob", Date: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC), Message: "/spend 45m"},
+		{AuthorName: "Alice", Date: time.Date(2024, 3, 4, 23, 0, 0, 0, time.UTC), Message: "chore: nothing"},
+		{AuthorName: "Alice", Date: time.Date(2024, 3, 2, 11, 0, 0, 0, time.UTC), Message: "/spend 1d"},
+		{AuthorName: "Alice", Date: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), Message: "/spend 2h"},
+	}
+
+	collection := (&Collector{GroupBy: GroupByAuthor}).Collect(commits)
+	require.Len(t, collection.Groups, 2)
+
+	alice := collection.Groups[0]
+	assert.Equal(t, "Alice", alice.Key)
+	assert.Equal(t, uint64(600), alice.TimeSpent.ToMinutes())
+	assert.Equal(t, 2, alice.Commits)
+	assert.Equal(t, "2024-03-01", alice.First.Format(time.DateOnly))
+	// The commit without directives is not activity
+	assert.Equal(t, "2024-03-02", alice.Last.Format(time.DateOnly))
+	assert.Equal(t, 2, alice.SpanDays())
+
+	bob := collection.Groups[1]
+	assert.Equal(t, "Bob", bob.Key)
+	assert.Equal(t, 1, bob.SpanDays())
+}
+
+func TestCollector_CollectGroupedByFoldedAuthor(t *testing.T) {
+	commits := []*Commit{
+		{AuthorName: "durand", Message: "/spend 1h"},
+		{AuthorName: "Durand", Message: "/spend 1h"},
+		{AuthorName: "Édouard", Message: "/spend 1h"},
+		{AuthorName: "Durand", Message: "/spend 1h"},
+		{AuthorName: "Edouard", Message: "/spend 1h"},
+	}
+
+	collection := (&Collector{GroupBy: GroupByAuthor}).Collect(commits)
+	require.Len(t, collection.Groups, 3)
+	assert.Equal(t, "Durand", collection.Groups[0].Key)
+	assert.Equal(t, 3, collection.Groups[0].Commits)
+
+	defer func() { FoldAccents = false }()
+	FoldAccents = true
+	collection = (&Collector{GroupBy: GroupByAuthor}).Collect(commits)
+	require.Len(t, collection.Groups, 2)
+	assert.Equal(t, "Durand", collection.Groups[0].Key)
+	// In case of a tie, the first spelling in lexical order is displayed
+	assert.Equal(t, "Edouard", collection.Groups[1].Key)
+	assert.Equal(t, 2, collection.Groups[1].Commits)
+}
+
+func TestCollector_CollectNotGrouped(t *testing.T) {
+	collection := (&Collector{}).Collect([]*Commit{{Message: "/spend 1h"}})
+	assert.Nil(t, collection.Groups)
+}
+
+func TestBucketDateUsesTheConfiguredTimezone(t *testing.T) {
+	defer func() { Location = time.Local }()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	Location = tokyo
+	commit := &Commit{Date: time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)}
+	assert.Equal(t, "2024-03-02", commit.BucketDate().Format(time.DateOnly))
+}
+
+func TestCollector_CollectResults(t *testing.T) {
+	commits := []*Commit{
+		{Hash: "b", AuthorName: "Bob", Message: "fix #2\n\n/spend 45m"},
+		{Hash: "n", AuthorName: "Alice", Message: "chore: nothing"},
+		{Hash: "a", AuthorName: "Alice", Message: "feat #1\n\n/spend 2h"},
+	}
+	forge, err := NewForge(ForgeGitlab, "")
+	require.NoError(t, err)
+
+	collection := (&Collector{GroupBy: GroupByIssue, Forge: forge, Repository: "api"}).Collect(commits)
+	require.Len(t, collection.Results, 2, "commits without time spent have no result")
+	assert.Equal(t, "b", collection.Results[0].Commit.Hash)
+	assert.Equal(t, "#2", collection.Results[0].Key)
+	assert.Equal(t, "api", collection.Results[1].Repository)
+
+	groups := GroupResults(collection.Results, func(result *Result) string {
+		return result.Repository
+	}, FoldAuthorName)
+	require.Len(t, groups, 1)
+	assert.Equal(t, uint64(165), groups[0].TimeSpent.ToMinutes())
+}
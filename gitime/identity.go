@@ -0,0 +1,109 @@
+package gitime
+
+import (
+	"github.com/goutte/git-spend/locale"
+	"strings"
+)
+
+// IdentityMigration rewrites the identities recorded by git-spend itself, like the authors of the snapshots,
+// after people changed identities, like when all the emails moved to another domain.
+// The history is never rewritten : only what git-spend wrote about it.
+type IdentityMigration struct {
+	// From is the domain of the emails replaced by the domain To, regardless of its case, like "@oldcorp.com".
+	// Emails of its subdomains, like "@eu.oldcorp.com", keep their subdomain, but lookalike domains are left alone.
+	From string
+	To   string
+	// Mailmap maps the identities, once migrated, to the proper identities of people
+	Mailmap []*MailmapEntry
+}
+
+// IdentityChange is an identity rewritten in a record, like the author of an entry of a ledger
+type IdentityChange struct {
+	// Record tells which record of the file holds the identity, like "commit 1a2b3c4"
+	Record string
+	Before string
+	After  string
+}
+
+// Migrate returns the identity the identity is now known as : its proper identity in the mailmap,
+// or else the identity with its domain From replaced by To, mapped by the mailmap in turn.
+func (m *IdentityMigration) Migrate(identity string) string {
+	if resolved := m.resolve(identity); resolved != identity {
+		return resolved
+	}
+
+	return m.resolve(m.migrateDomain(identity))
+}
+
+// migrateDomain returns the email with its domain From replaced by To, compared label by label,
+// or the identity itself when it is not an email of the domain.
+func (m *IdentityMigration) migrateDomain(identity string) string {
+	from := strings.TrimPrefix(m.From, "@")
+	at := strings.LastIndex(identity, "@")
+	if from == "" || at == -1 {
+		return identity
+	}
+	domain := identity[at+1:]
+	if len(domain) < len(from) || !strings.EqualFold(domain[len(domain)-len(from):], from) {
+		return identity
+	}
+	subdomain := domain[:len(domain)-len(from)]
+	if subdomain != "" && !strings.HasSuffix(subdomain, ".") {
+		return identity
+	}
+
+	return identity[:at+1] + subdomain + strings.TrimPrefix(m.To, "@")
+}
+
+// resolve returns the proper identity of the mailmap for the identity, or the identity itself.
+// Emails are mapped to proper emails, and names to proper names, so that records keep their kind of identity.
+func (m *IdentityMigration) resolve(identity string) string {
+	isEmail := strings.Contains(identity, "@")
+	for _, entry := range m.Mailmap {
+		if isEmail && entry.ProperEmail != "" && strings.EqualFold(entry.CommitEmail, identity) {
+			return entry.ProperEmail
+		}
+		if !isEmail && entry.ProperName != "" && entry.CommitName == identity {
+			return entry.ProperName
+		}
+	}
+
+	return identity
+}
+
+// MigrateIdentities migrates the identities in place, and returns what changed, for the record
+func (m *IdentityMigration) MigrateIdentities(identities []string, record string) []*IdentityChange {
+	changes := make([]*IdentityChange, 0)
+	for i, identity := range identities {
+		migrated := m.Migrate(identity)
+		if migrated == identity {
+			continue
+		}
+		changes = append(changes, &IdentityChange{Record: record, Before: identity, After: migrated})
+		identities[i] = migrated
+	}
+
+	return changes
+}
+
+// MigrateLedger migrates the author names and emails of the entries of the ledger in place, and returns what changed.
+// The filters of a snapshot are left alone, since they select commits in the history, which keeps the old identities.
+func (m *IdentityMigration) MigrateLedger(ledger []*LedgerEntry) []*IdentityChange {
+	changes := make([]*IdentityChange, 0)
+	for _, entry := range ledger {
+		short := (&Commit{Hash: entry.Hash}).ShortHash()
+		identities := []string{entry.Author}
+		if entry.AuthorEmail != "" && entry.AuthorEmail != entry.Author {
+			identities = append(identities, entry.AuthorEmail)
+		}
+		changes = append(changes, m.MigrateIdentities(identities, locale.Tf("IdentityRecordLedger", short))...)
+		if entry.AuthorEmail == entry.Author {
+			entry.AuthorEmail = identities[0]
+		} else if len(identities) > 1 {
+			entry.AuthorEmail = identities[1]
+		}
+		entry.Author = identities[0]
+	}
+
+	return changes
+}
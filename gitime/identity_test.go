@@ -0,0 +1,63 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIdentityMigration_Migrate(t *testing.T) {
+	migration := &IdentityMigration{
+		From: "@oldcorp.com",
+		To:   "@newcorp.com",
+		Mailmap: []*MailmapEntry{
+			{ProperName: "Jane Doe", ProperEmail: "jane.doe@newcorp.com", CommitName: "J. Doe", CommitEmail: "jd@oldcorp.com"},
+			{ProperEmail: "bob@newcorp.com", CommitEmail: "robert@newcorp.com"},
+		},
+	}
+	assert.Equal(t, "alice@newcorp.com", migration.Migrate("alice@oldcorp.com"))
+	assert.Equal(t, "alice@newcorp.com", migration.Migrate("alice@OldCorp.com"), "domains are compared regardless of their case")
+	assert.Equal(t, "jane.doe@newcorp.com", migration.Migrate("jd@oldcorp.com"), "the mailmap comes first")
+	assert.Equal(t, "Jane Doe", migration.Migrate("J. Doe"))
+	assert.Equal(t, "bob@newcorp.com", migration.Migrate("robert@oldcorp.com"), "the migrated identity is mapped too")
+	assert.Equal(t, "carol@elsewhere.org", migration.Migrate("carol@elsewhere.org"))
+	assert.Equal(t, "dave@eu.newcorp.com", migration.Migrate("dave@eu.oldcorp.com"), "subdomains keep their subdomain")
+	assert.Equal(t, "x@oldcorp.community", migration.Migrate("x@oldcorp.community"), "lookalike domains are left alone")
+	assert.Equal(t, "x@notoldcorp.com", migration.Migrate("x@notoldcorp.com"), "lookalike domains are left alone")
+	assert.Equal(t, "Carol", migration.Migrate("Carol"))
+}
+
+func TestIdentityMigration_MigrateLedger(t *testing.T) {
+	migration := &IdentityMigration{From: "@oldcorp.com", To: "@newcorp.com"}
+	ledger := []*LedgerEntry{
+		{Hash: "1a2b3c4d5e6f", Author: "alice@oldcorp.com", Minutes: 60},
+		{Hash: "2b3c4d5e6f7a", Author: "Bob", Minutes: 30},
+	}
+
+	changes := migration.MigrateLedger(ledger)
+	assert.Equal(t, []*IdentityChange{
+		{Record: "commit 1a2b3c4", Before: "alice@oldcorp.com", After: "alice@newcorp.com"},
+	}, changes)
+	assert.Equal(t, "alice@newcorp.com", ledger[0].Author)
+	assert.Equal(t, "Bob", ledger[1].Author)
+	assert.Empty(t, migration.MigrateLedger(ledger), "migrating twice changes nothing")
+}
+
+func TestIdentityMigration_MigrateLedgerEmails(t *testing.T) {
+	migration := &IdentityMigration{From: "@oldcorp.com", To: "@newcorp.com"}
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	ledger := NewLedger([]*Commit{
+		{Hash: "1a2b3c4d5e6f", AuthorName: "Alice", AuthorEmail: "alice@oldcorp.com", Date: date, Message: "/spend 1h"},
+		{Hash: "2b3c4d5e6f7a", AuthorEmail: "bob@oldcorp.com", Date: date, Message: "/spend 30m"},
+	}, nil)
+
+	changes := migration.MigrateLedger(ledger)
+	assert.Equal(t, []*IdentityChange{
+		{Record: "commit 1a2b3c4", Before: "alice@oldcorp.com", After: "alice@newcorp.com"},
+		{Record: "commit 2b3c4d5", Before: "bob@oldcorp.com", After: "bob@newcorp.com"},
+	}, changes)
+	assert.Equal(t, "Alice", ledger[0].Author)
+	assert.Equal(t, "alice@newcorp.com", ledger[0].AuthorEmail)
+	assert.Equal(t, "bob@newcorp.com", ledger[1].Author, "authors without a name are known by their email")
+	assert.Equal(t, "bob@newcorp.com", ledger[1].AuthorEmail)
+}
@@ -0,0 +1,242 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"io"
+)
+
+// Version is the version of JSON-RPC spoken by the Server
+const Version = "2.0"
+
+// MethodShutdown is handled by the Server itself : it answers, and then stops serving
+const MethodShutdown = "shutdown"
+
+// Error codes of JSON-RPC 2.0
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is the code of the errors returned by the methods, like an unknown revision
+	CodeServerError = -32000
+)
+
+// DefaultMaxMessageSize is the size of the largest request, in bytes, since requests are read line by line
+const DefaultMaxMessageSize = 16 * 1024 * 1024
+
+// Error is a JSON-RPC error, as answered to the client
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+}
+
+// InvalidParams returns the error answered when the params of a request do not fit its method
+func InvalidParams(reason string) *Error {
+	return &Error{Code: CodeInvalidParams, Message: locale.T("RpcInvalidParams"), Data: reason}
+}
+
+// Handler answers the params of a request with a result, that is marshalled to JSON.
+// Returning an *Error sets its code, and other errors are answered with CodeServerError.
+type Handler func(params json.RawMessage) (any, error)
+
+// Request is a JSON-RPC request.  Requests without an id are notifications, and are not answered.
+type Request struct {
+	Version string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response, holding either a result or an error
+type Response struct {
+	Version string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server answers the requests read from a stream, one JSON request (or batch) per line,
+// each answered by a line of JSON, until the stream ends or it is asked to shut down.
+// Malformed requests, requests too large and failing handlers are answered with errors, and never stop the server.
+type Server struct {
+	Methods map[string]Handler
+	// MaxMessageSize is the size of the largest line read, in bytes, or DefaultMaxMessageSize when zero
+	MaxMessageSize int
+	// shutdown is set by MethodShutdown
+	shutdown bool
+}
+
+// Serve reads the requests of in, and writes their responses to out
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	maxMessageSize := s.MaxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultMaxMessageSize
+	}
+	lines := bufio.NewReaderSize(in, 64*1024)
+	for !s.shutdown {
+		line, tooLong, readErr := readLine(lines, maxMessageSize)
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		line = bytes.TrimSpace(line)
+		var answer any
+		if tooLong {
+			answer = &Response{Version: Version, Id: nil, Error: &Error{
+				Code:    CodeInvalidRequest,
+				Message: locale.T("RpcRequestTooLarge"),
+				Data:    maxMessageSize,
+			}}
+		} else if len(line) > 0 {
+			answer = s.answer(line)
+		}
+		if answer != nil {
+			encoded, err := json.Marshal(answer)
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(append(encoded, '\n'))
+			if err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// readLine reads the next line of the reader, without its newline.
+// Lines longer than maxSize are read until their end, but discarded, and tell they are too long.
+func readLine(lines *bufio.Reader, maxSize int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := lines.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, chunk...)
+			if len(bytes.TrimSuffix(line, []byte{'\n'})) > maxSize {
+				tooLong = true
+				line = nil
+			}
+		}
+		if err != bufio.ErrBufferFull {
+			return line, tooLong, err
+		}
+	}
+}
+
+// answer returns the response to the line, a batch of responses, or nil when there is nothing to answer
+func (s *Server) answer(line []byte) any {
+	if line[0] != '[' {
+		// A notification must not be answered by a typed nil, that would be written as null
+		if response := s.answerRaw(line); response != nil {
+			return response
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(line, &batch); err != nil {
+		return newErrorResponse(nil, CodeParseError, "RpcParseError")
+	}
+	if len(batch) == 0 {
+		return newErrorResponse(nil, CodeInvalidRequest, "RpcInvalidRequest")
+	}
+	responses := make([]*Response, 0, len(batch))
+	for _, raw := range batch {
+		if response := s.answerRaw(raw); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+
+	return responses
+}
+
+func (s *Server) answerRaw(raw []byte) *Response {
+	if !json.Valid(raw) {
+		return newErrorResponse(nil, CodeParseError, "RpcParseError")
+	}
+	request := &Request{}
+	if err := json.Unmarshal(raw, request); err != nil {
+		return newErrorResponse(nil, CodeInvalidRequest, "RpcInvalidRequest")
+	}
+	if request.Version != Version || request.Method == "" {
+		return newErrorResponse(request.Id, CodeInvalidRequest, "RpcInvalidRequest")
+	}
+
+	result, rpcErr := s.call(request)
+	if len(request.Id) == 0 {
+		return nil
+	}
+	if rpcErr != nil {
+		return &Response{Version: Version, Id: request.Id, Error: rpcErr}
+	}
+	// A null result is still a result, like the one of a shutdown
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+
+	return &Response{Version: Version, Id: request.Id, Result: result}
+}
+
+// call runs the handler of the method of the request, turning its panics into internal errors
+func (s *Server) call(request *Request) (result any, rpcErr *Error) {
+	if request.Method == MethodShutdown {
+		s.shutdown = true
+		return nil, nil
+	}
+	handler, found := s.Methods[request.Method]
+	if !found {
+		return nil, &Error{
+			Code:    CodeMethodNotFound,
+			Message: locale.T("RpcMethodNotFound"),
+			Data:    request.Method,
+		}
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = nil
+			rpcErr = &Error{Code: CodeInternalError, Message: locale.T("RpcInternalError"), Data: fmt.Sprint(recovered)}
+		}
+	}()
+	result, err := handler(request.Params)
+	if err == nil {
+		return result, nil
+	}
+	if e, ok := err.(*Error); ok {
+		return nil, e
+	}
+
+	return nil, &Error{Code: CodeServerError, Message: err.Error()}
+}
+
+// newErrorResponse answers a request that could not be read, with a null id if it has none
+func newErrorResponse(id json.RawMessage, code int, key string) *Response {
+	return &Response{Version: Version, Id: id, Error: &Error{Code: code, Message: locale.T(key)}}
+}
+
+// DecodeParams decodes the params of a request into v, or returns the error answering params that do not fit
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return InvalidParams(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *Server {
+	return &Server{Methods: map[string]Handler{
+		"echo": func(params json.RawMessage) (any, error) {
+			var p struct {
+				Text string `json:"text"`
+			}
+			if err := DecodeParams(params, &p); err != nil {
+				return nil, err
+			}
+			return p, nil
+		},
+		"fail": func(params json.RawMessage) (any, error) {
+			return nil, errors.New("unknown revision")
+		},
+		"panic": func(params json.RawMessage) (any, error) {
+			panic("boom")
+		},
+	}}
+}
+
+func TestServeScriptedSession(t *testing.T) {
+	session := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hello"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"echo","params":{"text":`,
+		``,
+		`{"jsonrpc":"2.0","id":"three","method":"nope"}`,
+		`{"jsonrpc":"1.0","id":4,"method":"echo"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"echo","params":{"text":5}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"fail"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"panic"}`,
+		`{"jsonrpc":"2.0","method":"echo","params":{"text":"notification"}}`,
+		`[{"jsonrpc":"2.0","id":8,"method":"echo","params":{"text":"a"}},{"jsonrpc":"2.0","method":"echo"},42]`,
+		`[]`,
+		`{"jsonrpc":"2.0","id":9,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":10,"method":"echo","params":{"text":"too late"}}`,
+	}, "\n")
+	var out bytes.Buffer
+	require.NoError(t, newTestServer().Serve(strings.NewReader(session), &out))
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"text":"hello"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`,
+		`{"jsonrpc":"2.0","id":"three","error":{"code":-32601,"message":"method not found","data":"nope"}}`,
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32600,"message":"invalid request"}}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32602,"message":"invalid params","data":"json: cannot unmarshal number into Go struct field .text of type string"}}`,
+		`{"jsonrpc":"2.0","id":6,"error":{"code":-32000,"message":"unknown revision"}}`,
+		`{"jsonrpc":"2.0","id":7,"error":{"code":-32603,"message":"internal error","data":"boom"}}`,
+		`[{"jsonrpc":"2.0","id":8,"result":{"text":"a"}},{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}}]`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}}`,
+		`{"jsonrpc":"2.0","id":9,"result":null}`,
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", out.String())
+}
+
+func TestServeAfterARequestTooLarge(t *testing.T) {
+	session := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"` + strings.Repeat("a", 200) + `"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"echo","params":{"text":"b"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"echo","params":{"text":"` + strings.Repeat("c", 200) + `"}}`,
+	}, "\n")
+	server := newTestServer()
+	server.MaxMessageSize = 100
+	var out bytes.Buffer
+	require.NoError(t, server.Serve(strings.NewReader(session), &out))
+
+	tooLarge := `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"request too large","data":100}}`
+	expected := []string{
+		tooLarge,
+		`{"jsonrpc":"2.0","id":2,"result":{"text":"b"}}`,
+		tooLarge,
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", out.String())
+}
+
+func TestReadLine(t *testing.T) {
+	lines := bufio.NewReaderSize(strings.NewReader("abcd\n"+strings.Repeat("e", 40)+"\nfg"), 16)
+	line, tooLong, err := readLine(lines, 4)
+	require.NoError(t, err)
+	assert.False(t, tooLong)
+	assert.Equal(t, "abcd\n", string(line))
+	_, tooLong, err = readLine(lines, 4)
+	require.NoError(t, err)
+	assert.True(t, tooLong, "lines longer than the buffer are read until their end")
+	line, tooLong, err = readLine(lines, 4)
+	assert.Equal(t, io.EOF, err)
+	assert.False(t, tooLong)
+	assert.Equal(t, "fg", string(line))
+}
+
+func TestServeUntilTheEndOfTheStream(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, newTestServer().Serve(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"echo"}`), &out))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":{"text":""}}`+"\n", out.String())
+}
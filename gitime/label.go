@@ -0,0 +1,83 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"strconv"
+	"strings"
+)
+
+// DefaultRateLabel is the label of the default rate, for the time spent without a label or under a label without a rate
+const DefaultRateLabel = "default"
+
+// Rates map labels to the rates of the time spent under them, like on-call work billed at a premium.
+// Labels are compared regardless of their case.
+type Rates map[string]float64
+
+// ParseRates parses the rates of a config, keyed by label, like "oncall: 120"
+func ParseRates(values map[string]string) (Rates, error) {
+	rates := make(Rates, len(values))
+	for label, value := range values {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf(locale.Tf("RateUnparsable", label, value))
+		}
+		rates[strings.ToLower(label)] = rate
+	}
+
+	return rates, nil
+}
+
+// Rate returns the rate of the label, or else the default rate
+func (r Rates) Rate(label string) float64 {
+	if rate, found := r[strings.ToLower(label)]; found && label != "" {
+		return rate
+	}
+
+	return r[DefaultRateLabel]
+}
+
+// Knows tells whether the label has a rate of its own.  Time spent without a label is billed at the default rate.
+func (r Rates) Knows(label string) bool {
+	_, found := r[strings.ToLower(label)]
+
+	return label == "" || found
+}
+
+// labelResults attributes the time spent by the commit to the labels of its directives, in proportion of the time
+// spent under each, like the shares of split groupings.  A commit under a single label is attributed as is.
+func (c *Collector) labelResults(commit *Commit, ts *TimeSpent, labels []share) []*Result {
+	if len(labels) <= 1 {
+		key := ""
+		if len(labels) == 1 {
+			key = labels[0].Key
+		}
+		return []*Result{{Commit: commit, Repository: c.Repository, Key: key, TimeSpent: ts}}
+	}
+	results := make([]*Result, 0, len(labels))
+	for i, minutes := range apportion(ts.ToMinutes(), labels) {
+		if minutes == 0 {
+			continue
+		}
+		results = append(results, &Result{
+			Commit:     commit,
+			Repository: c.Repository,
+			Key:        labels[i].Key,
+			TimeSpent:  &TimeSpent{Minutes: float64(minutes)},
+		})
+	}
+
+	return results
+}
+
+// addLabelShare adds the time spent under the label to the shares of the labels of a commit, in order of appearance
+func addLabelShare(labels []share, label string, ts *TimeSpent) []share {
+	for i := range labels {
+		if strings.EqualFold(labels[i].Key, label) {
+			labels[i].Weight += ts.toExactMinutes()
+			return labels
+		}
+	}
+
+	return append(labels, share{Key: label, Weight: ts.toExactMinutes()})
+}
@@ -0,0 +1,51 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(map[string]string{"default": "80", "OnCall": "120.5"})
+	require.NoError(t, err)
+	assert.Equal(t, 120.5, rates.Rate("oncall"))
+	assert.Equal(t, 120.5, rates.Rate("ONCALL"))
+	assert.Equal(t, 80.0, rates.Rate("emergency"), "unknown labels use the default rate")
+	assert.Equal(t, 80.0, rates.Rate(""))
+	assert.True(t, rates.Knows("oncall"))
+	assert.True(t, rates.Knows(""), "time spent without a label is billed at the default rate")
+	assert.False(t, rates.Knows("emergency"))
+
+	_, err = ParseRates(map[string]string{"oncall": "a lot"})
+	assert.Error(t, err)
+	_, err = ParseRates(map[string]string{"oncall": "-5"})
+	assert.Error(t, err)
+}
+
+func TestCollector_CollectGroupedByLabel(t *testing.T) {
+	commits := []*Commit{
+		{Hash: "1", Message: "/spend 2h [oncall]"},
+		{Hash: "2", Message: "/spend 1h [OnCall]\n/spend 30m [review]\n/spend 30m"},
+		{Hash: "3", Message: "/spend 15m"},
+	}
+	collector := &Collector{GroupBy: GroupByLabel}
+
+	collection := collector.Collect(commits)
+	minutes := make(map[string]uint64)
+	for _, group := range collection.Groups {
+		// The spellings of a label are grouped together
+		minutes[strings.ToLower(group.Key)] = group.TimeSpent.ToMinutes()
+	}
+	assert.Equal(t, map[string]uint64{"": 45, "oncall": 180, "review": 30}, minutes)
+	assert.Equal(t, uint64(255), collection.TimeSpent.ToMinutes())
+	assert.Empty(t, collection.Warnings, "labels are free when no rates are configured")
+
+	collector.Rates = Rates{DefaultRateLabel: 80, "oncall": 120}
+	collection = collector.Collect(commits)
+	require.Len(t, collection.Warnings, 1)
+	assert.Equal(t, CodeLabelUnknown, collection.Warnings[0].Code)
+	assert.Equal(t, "2", collection.Warnings[0].Hash)
+	assert.Equal(t, uint64(255), collection.TimeSpent.ToMinutes(), "unknown labels are still counted")
+}
@@ -0,0 +1,159 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"strings"
+	"unicode"
+)
+
+// Policies that may be enforced by the Linter, on top of the minimum granularity
+const (
+	// PolicySingleDirective allows a single directive per message, on its final non-empty line
+	PolicySingleDirective = "single-directive"
+	// PolicyNoRange forbids hedging ranges, like "/spend 1-2h"
+	PolicyNoRange = "no-range"
+)
+
+// SupportedPolicies lists the policies accepted by ParsePolicies
+var SupportedPolicies = []string{PolicySingleDirective, PolicyNoRange}
+
+// Diagnostic codes of the violations, one per rule, so that editors may tell them apart
+const (
+	CodeMinGranularity     = "GT001"
+	CodeManyDirectives     = "GT002"
+	CodeDirectiveNotOnLast = "GT003"
+	CodeRange              = "GT004"
+	CodeAmbiguousM         = "GT005"
+)
+
+// Violation is the breaking of a rule of a policy
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// String returns the (localized) violation, prefixed by its code
+func (v *Violation) String() string {
+	return fmt.Sprintf("[%s] %s", v.Code, v.Message)
+}
+
+// Linter checks directives against the policies of a team
+type Linter struct {
+	// MinGranularity is the smallest time that may be logged, and directives must be multiples of it.
+	// Nil means no policy.
+	MinGranularity *TimeSpent
+	// SingleDirective enforces PolicySingleDirective
+	SingleDirective bool
+	// NoRange enforces PolicyNoRange
+	NoRange bool
+	// RejectAmbiguousM forbids the bare "m" unit, which is what AmbiguousReject asks for
+	RejectAmbiguousM bool
+}
+
+// ParsePolicies configures the linter with a comma-separated list of policies, like "single-directive"
+func (l *Linter) ParsePolicies(policies string) error {
+	for _, policy := range strings.Split(policies, ",") {
+		switch strings.TrimSpace(policy) {
+		case "":
+		case PolicySingleDirective:
+			l.SingleDirective = true
+		case PolicyNoRange:
+			l.NoRange = true
+		default:
+			return fmt.Errorf(locale.Tf("PolicyUnsupported", policy, strings.Join(SupportedPolicies, ", ")))
+		}
+	}
+
+	return nil
+}
+
+// LintMessage returns the violations of the policies by the directives of the message
+func (l *Linter) LintMessage(message string) []*Violation {
+	violations := make([]*Violation, 0)
+	if l == nil {
+		return violations
+	}
+
+	directives := CollectDirectivesWithSpans(message)
+	for _, directive := range directives {
+		violations = append(violations, l.LintDirective(directive)...)
+	}
+
+	if l.SingleDirective && len(directives) > 1 {
+		violations = append(violations, &Violation{
+			Code:    CodeManyDirectives,
+			Message: locale.Tf("ViolationManyDirectives", len(directives)),
+		})
+	}
+	if l.SingleDirective && len(directives) > 0 {
+		last := directives[len(directives)-1]
+		if last.Spans.Directive.End != lastContentLineEnd(message) {
+			violations = append(violations, &Violation{
+				Code:    CodeDirectiveNotOnLast,
+				Message: locale.Tf("ViolationDirectiveNotOnLast", last.Line),
+			})
+		}
+	}
+
+	return violations
+}
+
+// LintDirective returns the violations of the policies by the directive alone
+func (l *Linter) LintDirective(directive *Directive) []*Violation {
+	violations := make([]*Violation, 0)
+	if l == nil {
+		return violations
+	}
+
+	if l.MinGranularity != nil {
+		granularity := l.MinGranularity.ToMinutes()
+		minutes := directive.TimeSpent.ToMinutes()
+		if granularity > 0 && (minutes < granularity || minutes%granularity != 0) {
+			violations = append(violations, &Violation{
+				Code: CodeMinGranularity,
+				Message: locale.Tf(
+					"ViolationMinGranularity",
+					directive.TimeSpent.String(),
+					l.MinGranularity.String(),
+					directive.Line,
+				),
+			})
+		}
+	}
+	if l.NoRange && directive.Range != nil {
+		violations = append(violations, &Violation{
+			Code:    CodeRange,
+			Message: locale.Tf("ViolationRange", directive.Line),
+		})
+	}
+	if l.RejectAmbiguousM && directive.AmbiguousM != "" {
+		violations = append(violations, &Violation{
+			Code:    CodeAmbiguousM,
+			Message: locale.Tf("ViolationAmbiguousM", directive.Line),
+		})
+	}
+
+	return violations
+}
+
+// lastContentLineEnd returns the byte offset of the end of the final non-empty line of the message,
+// once trimmed, ignoring comment lines and whatever is below the scissors line
+func lastContentLineEnd(message string) int {
+	message = strings.ReplaceAll(message, "\r", "\n")
+	end := 0
+	offset := 0
+	for _, line := range strings.Split(message, "\n") {
+		lineOffset := offset
+		offset += len(line) + 1
+		if isScissorsLine(line) {
+			break
+		}
+		if isCommentLine(line) || strings.TrimSpace(line) == "" {
+			continue
+		}
+		end = lineOffset + len(strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+
+	return end
+}
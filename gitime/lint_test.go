@@ -0,0 +1,99 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLinter_LintMessageMinGranularity(t *testing.T) {
+	linter := &Linter{MinGranularity: &TimeSpent{Minutes: 15}}
+	tests := []struct {
+		name       string
+		message    string
+		violations int
+	}{
+		{"multiple", "/spend 45m", 0},
+		{"multiple in other units", "/spend 1h30\n/spend 1d", 0},
+		{"not a multiple", "/spend 50m", 1},
+		{"below", "/spend 5m", 1},
+		{"each directive is linted", "/spend 10m\n/spend 1h\n/spend 1h01", 2},
+		{"no directives", "feat: nothing", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, linter.LintMessage(tt.message), tt.violations)
+		})
+	}
+}
+
+func TestLinter_LintMessageWithoutPolicies(t *testing.T) {
+	assert.Empty(t, (&Linter{}).LintMessage("/spend 7m"))
+	var linter *Linter
+	assert.Empty(t, linter.LintMessage("/spend 7m"))
+}
+
+func TestCollector_CollectViolations(t *testing.T) {
+	collector := &Collector{Linter: &Linter{MinGranularity: &TimeSpent{Minutes: 15}}}
+	collection := collector.Collect([]*Commit{
+		{Hash: "abcdef0123", AuthorName: "Alice", Message: "/spend 10m"},
+		{Hash: "0123abcdef", AuthorName: "Bob", Message: "/spend 15m"},
+	})
+	assert.Len(t, collection.Violations, 1)
+	assert.Equal(t, "abcdef0123", collection.Violations[0].Hash)
+	assert.Contains(t, collection.Violations[0].Message, "Alice")
+	// Violations are only reported, the time is still counted
+	assert.Equal(t, uint64(25), collection.TimeSpent.ToMinutes())
+}
+
+func codes(violations []*Violation) []string {
+	c := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		c = append(c, violation.Code)
+	}
+
+	return c
+}
+
+func TestLinter_LintMessageMinGranularityCode(t *testing.T) {
+	linter := &Linter{MinGranularity: &TimeSpent{Minutes: 15}}
+	assert.Equal(t, []string{CodeMinGranularity}, codes(linter.LintMessage("/spend 10m")))
+}
+
+func TestLinter_LintMessageManyDirectives(t *testing.T) {
+	linter := &Linter{SingleDirective: true}
+	assert.Empty(t, linter.LintMessage("feat: a\n\n/spend 1h"))
+	assert.Equal(t,
+		[]string{CodeManyDirectives},
+		codes(linter.LintMessage("feat: a\n\n/spend 1h\n/spend 2h")),
+	)
+}
+
+func TestLinter_LintMessageDirectiveNotOnLast(t *testing.T) {
+	linter := &Linter{SingleDirective: true}
+	tests := []struct {
+		name    string
+		message string
+		codes   []string
+	}{
+		{"last", "feat: a\n\nbody\n/spend 1h", []string{}},
+		{"trailing blank lines", "feat: a\n\n/spend 1h  \n\n\n", []string{}},
+		{"trailing comments", "feat: a\n\n/spend 1h\n# Please enter the commit message\n#\n", []string{}},
+		{"not last", "feat: a\n\n/spend 1h\nmore body", []string{CodeDirectiveNotOnLast}},
+		{"both rules", "/spend 1h\n/spend 1h\nbody", []string{CodeManyDirectives, CodeDirectiveNotOnLast}},
+		{"no directives", "feat: a", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.codes, codes(linter.LintMessage(tt.message)))
+		})
+	}
+}
+
+func TestLinter_ParsePolicies(t *testing.T) {
+	linter := &Linter{}
+	assert.NoError(t, linter.ParsePolicies(""))
+	assert.False(t, linter.SingleDirective)
+	assert.NoError(t, linter.ParsePolicies("single-directive"))
+	assert.True(t, linter.SingleDirective)
+	assert.Error(t, linter.ParsePolicies("single-directive, no-fridays"))
+}
@@ -0,0 +1,110 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeRequest is a merged merge request, whose description may hold directives,
+// like in squash workflows where the commits are gone and the description stays.
+type MergeRequest struct {
+	// Iid is the number of the merge request within its project, like 123 for !123
+	Iid         int
+	Description string
+	AuthorName  string
+	MergedAt    time.Time
+	// Hashes are the hashes of the merge commit and of the squash commit, if any
+	Hashes []string
+	// Commits are the commits of the merge request, whose directives are not counted twice
+	Commits []*Commit
+}
+
+// Reference returns the reference of the merge request within its project, like "!123"
+func (r *MergeRequest) Reference() string {
+	return "!" + strconv.Itoa(r.Iid)
+}
+
+// mergeRequestReferenceRegex matches the references to merge requests, like "!123" or "group/project!123"
+var mergeRequestReferenceRegex = regexp.MustCompile(`(?:^|[^\w/#!&-])(?P<project>[\w.-]+(?:/[\w.-]+)+)?!(?P<iid>[0-9]+)\b`)
+
+// IsMergedOrReferencedBy tells whether the commit is the merge or squash commit of the merge request,
+// or references it, like GitLab merge commits do with "See merge request group/project!123".
+// References to merge requests of other projects than the project are ignored.
+func (r *MergeRequest) IsMergedOrReferencedBy(commit *Commit, project string) bool {
+	for _, hash := range r.Hashes {
+		if hash != "" && hash == commit.Hash {
+			return true
+		}
+	}
+	for _, matches := range mergeRequestReferenceRegex.FindAllStringSubmatch(commit.Message, -1) {
+		referenced := matches[mergeRequestReferenceRegex.SubexpIndex("project")]
+		iid := matches[mergeRequestReferenceRegex.SubexpIndex("iid")]
+		if iid == strconv.Itoa(r.Iid) && (referenced == "" || referenced == project) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasDirectives tells whether the description of the merge request holds directives
+func (r *MergeRequest) HasDirectives() bool {
+	return len(CollectDirectives(r.Description)) > 0
+}
+
+// DescriptionCommits returns a commit for the directives of the description of each merge request,
+// attributed to the author of the merge request at its merge date, and hashed like its reference ("!123").
+// Directives also written in one of the commits of the merge request are only counted in the commit,
+// and reported in the warnings.
+func DescriptionCommits(requests []*MergeRequest) ([]*Commit, []*Warning) {
+	commits := make([]*Commit, 0)
+	warnings := make([]*Warning, 0)
+	for _, request := range requests {
+		lines := make([]string, 0)
+		for _, directive := range CollectDirectives(request.Description) {
+			if commit := findDirectiveLine(request.Commits, directive.Line); commit != nil {
+				warnings = append(warnings, &Warning{
+					Code:     CodeMergeRequestDuplicate,
+					Hash:     commit.Hash,
+					Location: directive.Line,
+					Message: locale.Tf(
+						"WarningMergeRequestDuplicate",
+						request.Reference(),
+						commit.ShortHash(),
+						directive.Line,
+					),
+				})
+				continue
+			}
+			lines = append(lines, directive.Line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		commits = append(commits, &Commit{
+			Hash:       request.Reference(),
+			AuthorName: request.AuthorName,
+			Date:       request.MergedAt,
+			Message:    fmt.Sprintf("%s\n\n%s", request.Reference(), strings.Join(lines, "\n")),
+		})
+	}
+
+	return commits, warnings
+}
+
+// findDirectiveLine returns the first commit holding a directive written exactly like the line, or nil
+func findDirectiveLine(commits []*Commit, line string) *Commit {
+	for _, commit := range commits {
+		for _, directive := range CollectDirectives(commit.Message) {
+			if directive.Line == line {
+				return commit
+			}
+		}
+	}
+
+	return nil
+}
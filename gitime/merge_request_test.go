@@ -0,0 +1,60 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMergeRequest_IsMergedOrReferencedBy(t *testing.T) {
+	request := &MergeRequest{Iid: 12, Hashes: []string{"", "abc123"}}
+	tests := []struct {
+		name     string
+		commit   *Commit
+		expected bool
+	}{
+		{"squash commit", &Commit{Hash: "abc123", Message: "feat: x"}, true},
+		{"merge commit", &Commit{Hash: "fff", Message: "Merge branch 'x' into 'main'\n\nSee merge request group/project!12"}, true},
+		{"short reference", &Commit{Hash: "fff", Message: "fix: follow-up of !12"}, true},
+		{"other project", &Commit{Hash: "fff", Message: "See merge request other/project!12"}, false},
+		{"other merge request", &Commit{Hash: "fff", Message: "See merge request !123"}, false},
+		{"issue", &Commit{Hash: "fff", Message: "fix #12"}, false},
+		{"no hash", &Commit{Message: "feat: x"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, request.IsMergedOrReferencedBy(tt.commit, "group/project"))
+		})
+	}
+}
+
+func TestDescriptionCommits(t *testing.T) {
+	merged := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	requests := []*MergeRequest{
+		{
+			Iid:         12,
+			Description: "Squashed.\n\n/spend 2h\n/spend 30m review",
+			AuthorName:  "Alice",
+			MergedAt:    merged,
+			Commits:     []*Commit{{Hash: "def4567890", Message: "feat: x\n\n/spend 30m review"}},
+		},
+		{
+			Iid:         13,
+			Description: "/spend 1h",
+			Commits:     []*Commit{{Hash: "aaa4567890", Message: "feat: y\n\n/spend 1h"}},
+		},
+	}
+
+	commits, warnings := DescriptionCommits(requests)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, "!12", commits[0].Hash)
+		assert.Equal(t, "Alice", commits[0].AuthorName)
+		assert.Equal(t, merged, commits[0].Date)
+		assert.Equal(t, uint64(120), CollectTimeSpent(commits[0].Message).ToMinutes())
+	}
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, "def4567890", warnings[0].Hash)
+		assert.Contains(t, warnings[0].Message, "/spend 30m review")
+		assert.Equal(t, "aaa4567890", warnings[1].Hash)
+	}
+}
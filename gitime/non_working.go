@@ -0,0 +1,106 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/locale"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NonWorkingDays are the days on which some clients contractually exclude the time spent,
+// like the weekend and holidays
+type NonWorkingDays struct {
+	// Weekend are the days of the week that are not working days, if they are flagged
+	Weekend []time.Weekday
+	// Holidays are the names of the holidays, keyed by date, like "2024-12-25"
+	Holidays map[string]string
+}
+
+// FlaggedSpend is a directive spending time on a non-working day
+type FlaggedSpend struct {
+	Commit *Commit
+	Line   string
+	// Date is the bucket date of the directive, see Directive.BucketDate
+	Date time.Time
+	// Reason tells why the date is not a working day, like the name of the holiday
+	Reason    string
+	TimeSpent *TimeSpent
+}
+
+// WeekendOf returns the days of the week that are not among the working days
+func WeekendOf(workingDays []time.Weekday) []time.Weekday {
+	weekend := make([]time.Weekday, 0)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		working := false
+		for _, workingDay := range workingDays {
+			working = working || workingDay == day
+		}
+		if !working {
+			weekend = append(weekend, day)
+		}
+	}
+
+	return weekend
+}
+
+// ParseHolidays parses the holidays of a file, mapping dates to names, like "2024-12-25: Christmas".
+// Names may be empty.
+func ParseHolidays(values map[string]string) (map[string]string, error) {
+	holidays := make(map[string]string, len(values))
+	for key, name := range values {
+		// YAML reads unquoted dates as timestamps, that come back like "2024-12-25 00:00:00 +0000 utc"
+		fields := strings.Fields(key)
+		if len(fields) == 0 {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf(locale.Tf("HolidaysUnparsable", key))
+		}
+		holidays[date.Format(time.DateOnly)] = strings.TrimSpace(name)
+	}
+
+	return holidays, nil
+}
+
+// Reason returns why the date is not a working day, the holiday first, or an empty string when it is one.
+// No days are flagged by a nil NonWorkingDays.
+func (n *NonWorkingDays) Reason(date time.Time) string {
+	if n == nil {
+		return ""
+	}
+	if name, found := n.Holidays[date.Format(time.DateOnly)]; found {
+		if name == "" {
+			return locale.T("FlaggedHoliday")
+		}
+		return name
+	}
+	for _, day := range n.Weekend {
+		if date.Weekday() == day {
+			return locale.T("FlaggedWeekend")
+		}
+	}
+
+	return ""
+}
+
+// SumFlagged returns the total time spent by the flagged directives
+func SumFlagged(flagged []*FlaggedSpend) *TimeSpent {
+	total := &TimeSpent{}
+	for _, spend := range flagged {
+		total.Add(spend.TimeSpent)
+	}
+
+	return total
+}
+
+// sortFlagged sorts the flagged directives by date, and then by hash, for listings
+func sortFlagged(flagged []*FlaggedSpend) {
+	sort.SliceStable(flagged, func(i, j int) bool {
+		if !flagged[i].Date.Equal(flagged[j].Date) {
+			return flagged[i].Date.Before(flagged[j].Date)
+		}
+		return flagged[i].Commit.Hash < flagged[j].Commit.Hash
+	})
+}
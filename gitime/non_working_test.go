@@ -0,0 +1,90 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWeekendOf(t *testing.T) {
+	assert.Equal(t, []time.Weekday{time.Sunday, time.Saturday}, WeekendOf(DefaultWorkingDays))
+	assert.Equal(t, []time.Weekday{time.Friday, time.Saturday}, WeekendOf([]time.Weekday{
+		time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+	}))
+}
+
+func TestParseHolidays(t *testing.T) {
+	holidays, err := ParseHolidays(map[string]string{
+		"2024-12-25":                    "Christmas",
+		"2024-05-01 00:00:00 +0000 utc": " Labour day ",
+		"2025-01-01":                    "",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"2024-12-25": "Christmas",
+		"2024-05-01": "Labour day",
+		"2025-01-01": "",
+	}, holidays)
+
+	_, err = ParseHolidays(map[string]string{"christmas": "2024-12-25"})
+	assert.Error(t, err)
+}
+
+func TestCollector_CollectFlagsNonWorkingDays(t *testing.T) {
+	saturday := time.Date(2024, 3, 2, 10, 0, 0, 0, Location)
+	monday := saturday.AddDate(0, 0, 2)
+	commits := []*Commit{
+		{Hash: "bbbbbbbbbb", Date: monday, Message: "feat: dated\n\n/spend 1h 2024-12-25\n/spend 2h"},
+		{Hash: "aaaaaaaaaa", Date: saturday, Message: "feat: weekend\n\n/spend 3h"},
+	}
+	days := &NonWorkingDays{
+		Weekend:  WeekendOf(DefaultWorkingDays),
+		Holidays: map[string]string{"2024-12-25": "Christmas"},
+	}
+
+	collection := (&Collector{NonWorkingDays: days}).Collect(commits)
+	assert.Equal(t, uint64(6*60), collection.TimeSpent.ToMinutes())
+	require.Len(t, collection.Flagged, 2)
+	assert.Equal(t, "aaaaaaaaaa", collection.Flagged[0].Commit.Hash, "flagged directives are sorted by date")
+	assert.Equal(t, "weekend", collection.Flagged[0].Reason)
+	assert.Equal(t, "Christmas", collection.Flagged[1].Reason)
+	assert.Equal(t, "/spend 1h 2024-12-25", collection.Flagged[1].Line)
+	assert.Equal(t, uint64(4*60), SumFlagged(collection.Flagged).ToMinutes())
+	assert.True(t, collection.NonBillable.IsZero())
+
+	collection = (&Collector{NonWorkingDays: days, ExcludeFlagged: true}).Collect(commits)
+	assert.Equal(t, uint64(2*60), collection.TimeSpent.ToMinutes())
+	assert.Equal(t, uint64(4*60), collection.NonBillable.ToMinutes())
+	assert.Len(t, collection.Flagged, 2)
+
+	collection = (&Collector{}).Collect(commits)
+	assert.Empty(t, collection.Flagged)
+}
+
+func TestCollector_CollectCorrectsBeforeExcludingFlagged(t *testing.T) {
+	saturday := time.Date(2024, 3, 2, 10, 0, 0, 0, Location)
+	monday := saturday.AddDate(0, 0, 2)
+	commits := []*Commit{
+		{Hash: "aaaaaaaaaa", Date: saturday, Message: "feat: weekend\n\n/spend 3h"},
+		{Hash: "bbbbbbbbbb", Date: monday, Message: "feat: both\n\n/spend 1h 2024-03-03\n/spend 3h"},
+		{Hash: "cccccccccc", Date: saturday, Message: "feat: forgotten"},
+	}
+	corrections, err := ParseCorrections(map[string]string{
+		"aaaaaaa": "1h",
+		"bbbbbbb": "+4h",
+		"ccccccc": "+30m",
+	})
+	require.NoError(t, err)
+	days := &NonWorkingDays{Weekend: WeekendOf(DefaultWorkingDays)}
+
+	collection := (&Collector{NonWorkingDays: days, ExcludeFlagged: true, Corrections: corrections}).Collect(commits)
+	require.Len(t, collection.Corrected, 3)
+	assert.Equal(t, uint64(3*60), collection.Corrected[0].Original.ToMinutes(), "the flagged time is corrected too")
+	assert.Equal(t, uint64(4*60), collection.Corrected[1].Original.ToMinutes())
+	// The corrected time spent is excluded in the same proportion as the flagged directives :
+	// all of the 1h of the weekend commit, and a quarter of the 8h of the other one.
+	// The commit spending nothing has no flagged directive, so its correction is counted.
+	assert.Equal(t, uint64(3*60), collection.NonBillable.ToMinutes())
+	assert.Equal(t, uint64(6*60+30), collection.TimeSpent.ToMinutes())
+}
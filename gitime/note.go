@@ -0,0 +1,83 @@
+package gitime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NoteFormatVersion is the version of the format of the notes written by annotate, on their first line.
+// It changes whenever a field is removed or changes meaning, but not when a field is added.
+const NoteFormatVersion = 1
+
+// DefaultNotesRef is the ref of the notes written by annotate, unless told otherwise
+const DefaultNotesRef = "refs/notes/git-spend"
+
+// Note is the time spent by a commit, as attached to it in a git note for other tools to read
+// without reimplementing the grammar.  It is written as lines of "key: value", like:
+//
+//	git-spend-note: 1
+//	minutes: 150
+//	months: 0
+//	weeks: 0
+//	days: 0
+//	hours: 2
+//	mins: 30
+//	directive: /spend 2h
+//	directive: /spend 30m
+//
+// The minutes are the total, in the schedule of the repository.  Months to mins are the components
+// of the time spent as written in the directives, before normalization.  Directives are the lines they were
+// read from, in order.  A "corrected: true" line tells that a correction replaced or amended the directives.
+type Note struct {
+	Minutes    uint64
+	TimeSpent  *TimeSpent
+	Directives []string
+	Corrected  bool
+}
+
+// NewNote returns the note of the commit, or nil if the commit spends no time and was not corrected
+func NewNote(commit *Commit, corrections []*Correction) *Note {
+	note := &Note{TimeSpent: &TimeSpent{}, Directives: make([]string, 0)}
+	for _, directive := range CollectDirectives(commit.Message) {
+		note.TimeSpent.Add(directive.TimeSpent)
+		note.Directives = append(note.Directives, directive.Line)
+	}
+	correction := findCorrection(corrections, commit.Hash)
+	if note.TimeSpent.IsZero() && correction == nil {
+		return nil
+	}
+	if correction != nil {
+		note.TimeSpent = correction.Apply(note.TimeSpent)
+		note.Corrected = true
+	}
+	note.Minutes = note.TimeSpent.ToMinutes()
+
+	return note
+}
+
+// String returns the note in its documented format, ending with a newline
+func (n *Note) String() string {
+	lines := []string{
+		fmt.Sprintf("git-spend-note: %d", NoteFormatVersion),
+		fmt.Sprintf("minutes: %d", n.Minutes),
+		"months: " + formatNoteNumber(n.TimeSpent.Months),
+		"weeks: " + formatNoteNumber(n.TimeSpent.Weeks),
+		"days: " + formatNoteNumber(n.TimeSpent.Days),
+		"hours: " + formatNoteNumber(n.TimeSpent.Hours),
+		"mins: " + formatNoteNumber(n.TimeSpent.Minutes),
+	}
+	for _, directive := range n.Directives {
+		lines = append(lines, "directive: "+directive)
+	}
+	if n.Corrected {
+		lines = append(lines, "corrected: true")
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// formatNoteNumber writes the number without trailing zeros, like 2 or 1.5
+func formatNoteNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
@@ -0,0 +1,35 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNote_String(t *testing.T) {
+	note := NewNote(&Commit{Hash: "aaaaaaaaaa", Message: "feat: x\n\n/spend 2h\n/spend 1.5d"}, nil)
+	require.NotNil(t, note)
+	assert.Equal(t, uint64(840), note.Minutes)
+	assert.Equal(t, `git-spend-note: 1
+minutes: 840
+months: 0
+weeks: 0
+days: 1.5
+hours: 2
+mins: 0
+directive: /spend 2h
+directive: /spend 1.5d
+`, note.String())
+
+	assert.Nil(t, NewNote(&Commit{Hash: "bbbbbbbbbb", Message: "chore: nothing"}, nil))
+}
+
+func TestNewNoteIsCorrected(t *testing.T) {
+	corrections, err := ParseCorrections(map[string]string{"bbbbbbb": "+30m"})
+	require.NoError(t, err)
+	note := NewNote(&Commit{Hash: "bbbbbbbbbb", Message: "chore: forgot"}, corrections)
+	require.NotNil(t, note)
+	assert.Equal(t, uint64(30), note.Minutes)
+	assert.True(t, note.Corrected)
+	assert.Contains(t, note.String(), "\ncorrected: true\n")
+}
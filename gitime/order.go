@@ -0,0 +1,14 @@
+package gitime
+
+// Orders in which commits may be listed, like git log --author-date-order
+const (
+	OrderAuthorDate = "author-date"
+	OrderCommitDate = "commit-date"
+	OrderTopo       = "topo"
+)
+
+// SupportedOrders lists the orders accepted by --order
+var SupportedOrders = []string{OrderAuthorDate, OrderCommitDate, OrderTopo}
+
+// DefaultOrder is the order of timesheet-like outputs, since it is the order the work was done in
+const DefaultOrder = OrderAuthorDate
@@ -0,0 +1,139 @@
+package gitime
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Policies to collapse hedging ranges, like "/spend 1-2h", into a single time spent
+const (
+	RangeMidpoint = "midpoint"
+	RangeMin      = "min"
+	RangeMax      = "max"
+)
+
+// SupportedRangePolicies lists the policies accepted for RangePolicy
+var SupportedRangePolicies = []string{RangeMidpoint, RangeMin, RangeMax}
+
+// RangePolicy is how the ranges of directives are collapsed into their time spent
+var RangePolicy = RangeMidpoint
+
+// TimeRange holds the bounds of a directive spending a range of time, like "/spend 30-45m"
+type TimeRange struct {
+	Low  *TimeSpent
+	High *TimeSpent
+	// Policy is how the range was collapsed into the time spent of the directive, like RangeMidpoint
+	Policy string
+}
+
+// String returns the range as it would be written in a directive, like "30m-45m"
+func (r *TimeRange) String() string {
+	return strings.Join(r.Low.CompactComponents(), " ") + "-" + strings.Join(r.High.CompactComponents(), " ")
+}
+
+// extractRangeDirective reads the (trimmed) line like "/spend 1-2h", or returns nil when it does not spend a range.
+// The lower bound must be lower than the upper bound, so that lines like "/spend 1h -30m" are no ranges.
+func extractRangeDirective(line string) *Directive {
+	indices := rangeRegex.FindStringSubmatchIndex(line)
+	if indices == nil {
+		return nil
+	}
+	group := func(name string) string {
+		span := submatchSpan(indices, rangeRegex, name)
+		if span == nil {
+			return ""
+		}
+		return line[span.Start:span.End]
+	}
+
+	lowUnit, highUnit := unitComponent(strings.ToLower(group("lowUnit"))), unitComponent(strings.ToLower(group("highUnit")))
+	ambiguousM := ""
+	if strings.ToLower(group("lowUnit")) == bareM || strings.ToLower(group("highUnit")) == bareM {
+		ambiguousM = bareMReading()
+	}
+	if lowUnit < 0 && highUnit < 0 {
+		lowUnit, highUnit = len(spanComponents)-1, len(spanComponents)-1
+	} else if lowUnit < 0 {
+		lowUnit = highUnit
+	} else if highUnit < 0 {
+		highUnit = lowUnit
+	}
+	low, _ := strconv.ParseFloat(group("low"), 64)
+	high, _ := strconv.ParseFloat(group("high"), 64)
+	timeRange := &TimeRange{
+		Low:    timeSpentIn(lowUnit, low),
+		High:   timeSpentIn(highUnit, high),
+		Policy: RangePolicy,
+	}
+	if timeRange.Low.toExactMinutes() >= timeRange.High.toExactMinutes() {
+		return nil
+	}
+
+	// The trailing space is not part of the range
+	end := indices[1]
+	if end > 0 && unicode.IsSpace(rune(line[end-1])) {
+		end--
+	}
+	token := submatchSpan(indices, rangeRegex, "low")
+	token.End = end
+	spans := &Spans{
+		Directive: Span{Start: 0, End: len(line)},
+		Keyword:   *submatchSpan(indices, rangeRegex, "keyword"),
+		Tokens:    []Span{*token},
+	}
+	date, label, note := readDirectiveSuffix(line, end, spans)
+
+	var ts *TimeSpent
+	if lowUnit == highUnit {
+		ts = timeSpentIn(lowUnit, collapseRange(low, high, RangePolicy))
+	} else {
+		ts = &TimeSpent{Minutes: collapseRange(
+			timeRange.Low.toExactMinutes(),
+			timeRange.High.toExactMinutes(),
+			RangePolicy,
+		)}
+	}
+
+	return &Directive{
+		Line:       line,
+		TimeSpent:  ts,
+		Range:      timeRange,
+		Date:       date,
+		Label:      label,
+		Note:       note,
+		AmbiguousM: ambiguousM,
+		Spans:      spans,
+	}
+}
+
+// collapseRange returns the value of the range under the policy
+func collapseRange(low float64, high float64, policy string) float64 {
+	switch policy {
+	case RangeMin:
+		return low
+	case RangeMax:
+		return high
+	default:
+		return (low + high) / 2
+	}
+}
+
+// timeSpentIn returns the time spent of that value in the unit of the component, in the order of spanComponents
+func timeSpentIn(component int, value float64) *TimeSpent {
+	components := make([]float64, len(spanComponents))
+	components[component] = value
+
+	return timeSpentFromComponents(components)
+}
+
+// timeSpentFromComponents returns the time spent of the values of the components, in the order of spanComponents
+func timeSpentFromComponents(components []float64) *TimeSpent {
+	return &TimeSpent{
+		Months:  components[0],
+		Weeks:   components[1],
+		Days:    components[2],
+		Hours:   components[3],
+		Minutes: components[4],
+	}
+}
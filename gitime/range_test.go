@@ -0,0 +1,93 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectDirectivesWithRanges(t *testing.T) {
+	defer func() { RangePolicy = RangeMidpoint }()
+	tests := []struct {
+		line     string
+		policy   string
+		expected uint64
+		low      uint64
+		high     uint64
+	}{
+		{"/spend 1-2h", RangeMidpoint, 90, 60, 120},
+		{"/spend 1-2h", RangeMin, 60, 60, 120},
+		{"/spend 1-2h", RangeMax, 120, 60, 120},
+		{"/spend 30-45m", RangeMidpoint, 38, 30, 45},
+		{"/spend 30 - 45", RangeMin, 30, 30, 45},
+		{"/spend 30m-1h", RangeMax, 60, 30, 60},
+		{"/spend 1h–2h", RangeMidpoint, 90, 60, 120},
+		{"/spent: 0.5-1d", RangeMax, 480, 240, 480},
+		{"/spend 1mo-2mo", RangeMin, 4 * 5 * 8 * 60, 4 * 5 * 8 * 60, 2 * 4 * 5 * 8 * 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line+" "+tt.policy, func(t *testing.T) {
+			RangePolicy = tt.policy
+			directives := CollectDirectives(tt.line)
+			if assert.Len(t, directives, 1) && assert.NotNil(t, directives[0].Range) {
+				assert.Equal(t, tt.expected, directives[0].TimeSpent.ToMinutes())
+				assert.Equal(t, tt.low, directives[0].Range.Low.ToMinutes())
+				assert.Equal(t, tt.high, directives[0].Range.High.ToMinutes())
+				assert.Equal(t, tt.policy, directives[0].Range.Policy)
+			}
+		})
+	}
+}
+
+func TestCollectDirectivesWithoutRanges(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected uint64
+	}{
+		// Decreasing bounds are a time spent followed by a note, like a negative time spent
+		{"/spend 1h -30m", 60},
+		{"/spend 2-1h", 0},
+		{"/spend 1h-1h", 60},
+		// Dates are no ranges
+		{"/spend 2023-03-25", 0},
+		{"/spend 2h 2023-03-25", 120},
+		{"/spend 15 2023-03-26 14:10:00", 15},
+		// The upper bound must end the range
+		{"/spend 1-2hello", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			for _, directive := range CollectDirectives(tt.line) {
+				assert.Nil(t, directive.Range)
+			}
+			assert.Equal(t, tt.expected, CollectTimeSpent(tt.line).ToMinutes())
+		})
+	}
+}
+
+func TestCollectDirectivesWithSpansOfRanges(t *testing.T) {
+	directives := CollectDirectivesWithSpans("feat: x\n\n/spend 30m - 1h 2023-03-25 hedging")
+	if assert.Len(t, directives, 1) {
+		message := "feat: x\n\n/spend 30m - 1h 2023-03-25 hedging"
+		assert.Equal(t, "30m - 1h", directives[0].Spans.Tokens[0].Of(message))
+		assert.Equal(t, "2023-03-25", directives[0].Spans.Date.Of(message))
+		assert.Equal(t, "hedging", directives[0].Note)
+	}
+}
+
+func TestCollector_CollectRanges(t *testing.T) {
+	collection := (&Collector{}).Collect([]*Commit{{Hash: "aaaaaaaaaa", Message: "/spend 1-2h\n/spend 1h"}})
+	assert.Equal(t, uint64(150), collection.TimeSpent.ToMinutes())
+	if assert.Len(t, collection.Ranges, 1) {
+		assert.Equal(t, "aaaaaaaaaa", collection.Ranges[0].Hash)
+		assert.Equal(t, "1h-2h", collection.Ranges[0].Range.String())
+	}
+}
+
+func TestLinter_LintMessageNoRange(t *testing.T) {
+	linter := &Linter{}
+	assert.NoError(t, linter.ParsePolicies(PolicyNoRange))
+	violations := linter.LintMessage("/spend 1-2h\n/spend 1h")
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, CodeRange, violations[0].Code)
+	}
+}
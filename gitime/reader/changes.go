@@ -0,0 +1,98 @@
+package reader
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ChangesReader reads the files changed by the commits of the repository of a directory,
+// asking git only once per commit, and only for the commits it is asked about.
+type ChangesReader struct {
+	directory string
+	cache     map[string][]*gitime.FileChange
+}
+
+// NewChangesReader returns a reader of the changes of the commits of the repository of the directory
+func NewChangesReader(directory string) *ChangesReader {
+	return &ChangesReader{
+		directory: directory,
+		cache:     make(map[string][]*gitime.FileChange),
+	}
+}
+
+// Read returns the files changed by the commit, compared to its first parent,
+// or nothing for commits without a hash.
+// The lines changed are only counted when asked, since counting them reads the blobs, which mirrors cloned
+// without them (see SyncMirror) would have to fetch.  Git never prompts for credentials : it fails instead.
+func (r *ChangesReader) Read(commit *gitime.Commit, lines bool) ([]*gitime.FileChange, error) {
+	key := commit.Hash
+	if lines {
+		key += "\x00lines"
+	}
+	if changes, found := r.cache[key]; found {
+		return changes, nil
+	}
+	if commit.Hash == "" {
+		return make([]*gitime.FileChange, 0), nil
+	}
+
+	// Paths are NUL-terminated and never quoted, whatever core.quotePath
+	stat := "--name-only"
+	if lines {
+		stat = "--numstat"
+	}
+	show := exec.Command(
+		"git", "show", stat, "--format=", "-z", "--no-renames", "--diff-merges=first-parent",
+		commit.Hash,
+	)
+	show.Dir = r.directory
+	show.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := show.Output()
+	if exitError, isExitError := err.(*exec.ExitError); isExitError {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(exitError.Stderr)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	changes := parseNameOnly(string(out))
+	if lines {
+		changes = parseNumstat(string(out))
+	}
+	r.cache[key] = changes
+
+	return changes, nil
+}
+
+// parseNameOnly parses the output of git diff --name-only -z, where lines are not counted
+func parseNameOnly(names string) []*gitime.FileChange {
+	changes := make([]*gitime.FileChange, 0)
+	for _, name := range strings.Split(strings.TrimLeft(names, "\n"), "\x00") {
+		name = strings.TrimLeft(name, "\n")
+		if name == "" {
+			continue
+		}
+		changes = append(changes, &gitime.FileChange{Path: name})
+	}
+
+	return changes
+}
+
+// parseNumstat parses the output of git diff --numstat -z, where binary files have "-" for their lines
+func parseNumstat(numstat string) []*gitime.FileChange {
+	changes := make([]*gitime.FileChange, 0)
+	for _, record := range strings.Split(strings.TrimLeft(numstat, "\n"), "\x00") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\t", 3)
+		if len(fields) != 3 || fields[2] == "" {
+			continue
+		}
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		changes = append(changes, &gitime.FileChange{Path: fields[2], Lines: added + deleted})
+	}
+
+	return changes
+}
@@ -0,0 +1,87 @@
+package reader
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangesReader_Read(t *testing.T) {
+	repository := t.TempDir()
+	git := func(args ...string) string {
+		c := exec.Command("git", append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		c.Dir = repository
+		out, err := c.Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	require.NoError(t, os.MkdirAll(filepath.Join(repository, "api"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repository, "api", "server.go"), []byte("a\nb\nc\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repository, "logo.png"), []byte{0, 1, 2}, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repository, "with\ttab.md"), []byte("a\n"), 0644))
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "feat: init\n\n/spend 1h")
+	hash := git("rev-parse", "HEAD")
+
+	reader := NewChangesReader(repository)
+	changes, err := reader.Read(&gitime.Commit{Hash: hash}, true)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*gitime.FileChange{
+		{Path: "api/server.go", Lines: 3},
+		{Path: "logo.png", Lines: 0},
+		{Path: "with\ttab.md", Lines: 1},
+	}, changes)
+	// The changes are cached
+	cached, err := reader.Read(&gitime.Commit{Hash: hash}, true)
+	require.NoError(t, err)
+	assert.Same(t, changes[0], cached[0])
+
+	names, err := reader.Read(&gitime.Commit{Hash: hash}, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*gitime.FileChange{
+		{Path: "api/server.go"},
+		{Path: "logo.png"},
+		{Path: "with\ttab.md"},
+	}, names, "lines are not counted unless asked")
+
+	none, err := reader.Read(&gitime.Commit{}, true)
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+	_, err = reader.Read(&gitime.Commit{Hash: "0000000000000000000000000000000000000000"}, false)
+	assert.Error(t, err, "failures are returned, and not read as no change")
+}
+
+func TestChangesReader_ReadOfAMirrorWithoutBlobs(t *testing.T) {
+	origin := filepath.Join(t.TempDir(), "origin")
+	require.NoError(t, exec.Command("git", "init", "--quiet", origin).Run())
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "a.go"), []byte("a\n"), 0644))
+	for _, args := range [][]string{
+		{"config", "uploadpack.allowFilter", "true"},
+		{"add", "-A"},
+		{"-c", "user.name=Alice", "-c", "user.email=alice@example.com", "commit", "--quiet", "-m", "feat: a"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = origin
+		require.NoError(t, c.Run())
+	}
+	mirror := filepath.Join(t.TempDir(), "mirror")
+	clone := exec.Command("git", "clone", "--quiet", "--no-checkout", "--filter=blob:none", "file://"+origin, mirror)
+	out, err := clone.CombinedOutput()
+	require.NoError(t, err, string(out))
+	// Like a remote that cannot be reached without credentials
+	require.NoError(t, os.RemoveAll(origin))
+	hash := ReadHead(mirror)
+
+	reader := NewChangesReader(mirror)
+	names, err := reader.Read(&gitime.Commit{Hash: hash}, false)
+	require.NoError(t, err, "names are read without any blob")
+	assert.Equal(t, []*gitime.FileChange{{Path: "a.go"}}, names)
+	_, err = reader.Read(&gitime.Commit{Hash: hash}, true)
+	assert.Error(t, err, "lines cannot be counted without the blobs")
+}
@@ -2,6 +2,9 @@ package reader
 
 import "time"
 
+// parseTimePerhaps parses the --since or --until value as a date, in local time like git does,
+// unless it states its own offset.  The date is returned in local time, since go-gitlog hands
+// its wall clock to git without any offset.
 func parseTimePerhaps(input string) *time.Time {
 	layouts := []string{
 		time.RFC3339,
@@ -12,11 +15,17 @@ func parseTimePerhaps(input string) *time.Time {
 	}
 
 	for _, layout := range layouts {
-		parse, err := time.Parse(layout, input)
+		parse, err := time.ParseInLocation(layout, input, time.Local)
 		if err == nil {
+			parse = parse.In(time.Local)
 			return &parse
 		}
 	}
 
 	return nil
 }
+
+// IsDate tells whether the --since or --until value is a date, and not a ref
+func IsDate(input string) bool {
+	return parseTimePerhaps(input) != nil
+}
@@ -0,0 +1,64 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// useLocal sets the local time zone for the duration of the test, like TZ would
+func useLocal(t *testing.T, name string) {
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	local := time.Local
+	time.Local = location
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestResolveBoundOfDates(t *testing.T) {
+	useLocal(t, "UTC")
+	assert.Equal(t, "", ResolveBound("", "."))
+	assert.Equal(t, "2023-04-01T00:00:00Z", ResolveBound("2023-04-01", "."))
+	assert.Equal(t, "2023-04-01T13:37:00Z", ResolveBound("2023-04-01 13:37:00", "."))
+	assert.True(t, IsDate("2023-04-01"))
+	assert.False(t, IsDate("HEAD~3"))
+}
+
+func TestResolveBoundOfDatesInLocalTime(t *testing.T) {
+	useLocal(t, "Europe/Paris")
+	// git reads dates without an offset in local time, and so do we
+	assert.Equal(t, "2026-09-01T00:00:00+02:00", ResolveBound("2026-09-01", "."))
+	assert.Equal(t, "2026-12-01T13:37:00+01:00", ResolveBound("2026-12-01 13:37:00", "."))
+	assert.Equal(t, "2026-09-01T12:00:00+02:00", ResolveBound("2026-09-01T10:00:00Z", "."), "offsets are kept")
+
+	since := parseTimePerhaps("2026-09-01T10:00:00Z")
+	require.NotNil(t, since)
+	assert.Equal(t, "2026-09-01 12:00:00", since.Format(time.DateTime), "the wall clock handed to git is local")
+}
+
+func TestFreezeBound(t *testing.T) {
+	useLocal(t, "Europe/Paris")
+	repository, git := newWipRepository(t)
+	git("commit", "--quiet", "--allow-empty", "-m", "second")
+	first := ReadGitLogCommits(nil, false, "", "", repository)[1].Hash
+	head := ReadHead(repository)
+
+	frozen, err := FreezeBound("", repository)
+	require.NoError(t, err)
+	assert.Equal(t, "", frozen)
+	frozen, err = FreezeBound("2026-09-01", repository)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-09-01T00:00:00+02:00", frozen, "dates keep their offset")
+	frozen, err = FreezeBound("HEAD~1", repository)
+	require.NoError(t, err)
+	assert.Equal(t, first, frozen, "refs are replaced by the hash they point to")
+	_, err = FreezeBound("nope", repository)
+	assert.Error(t, err)
+
+	assert.True(t, IsAncestor(first, head, repository))
+	assert.True(t, IsAncestor(head, head, repository))
+	assert.False(t, IsAncestor(head, first, repository))
+	assert.False(t, IsAncestor("0123456789abcdef0123456789abcdef01234567", head, repository))
+	assert.False(t, IsAncestor(first, "", repository))
+}
@@ -0,0 +1,179 @@
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/spf13/viper"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RepositoryConfigNames are the names of the config file a repository may hold at its root
+var RepositoryConfigNames = []string{".git-spend.yaml", ".git-spend.yml"}
+
+// CorrectionsFileNames are the names of the corrections file a repository may hold at its root
+var CorrectionsFileNames = []string{".git-spend-corrections.yaml", ".git-spend-corrections.yml"}
+
+// ReadRepositoryConfig reads the config file at the root of the repository of the directory.
+// It returns nil when the repository has no config file.
+func ReadRepositoryConfig(directory string) (*viper.Viper, error) {
+	root := directory
+	toplevel := exec.Command("git", "rev-parse", "--show-toplevel")
+	toplevel.Dir = directory
+	if out, err := toplevel.Output(); err == nil {
+		root = strings.TrimSpace(string(out))
+	}
+
+	for _, name := range RepositoryConfigNames {
+		path := filepath.Join(root, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		config := viper.New()
+		config.SetConfigFile(path)
+		config.SetConfigType("yaml")
+		if err := config.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+
+	return nil, nil
+}
+
+// ReadCorrections reads the corrections of the repository of the directory, keyed by commit hash.
+// They are found in the corrections section of the repository config, and in the corrections file,
+// which maps hashes to corrections directly, and takes precedence.
+func ReadCorrections(directory string) (map[string]string, error) {
+	corrections := make(map[string]string)
+	config, err := ReadRepositoryConfig(directory)
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		for hash, value := range config.GetStringMapString("corrections") {
+			corrections[hash] = value
+		}
+	}
+
+	root := ReadToplevel(directory)
+	for _, name := range CorrectionsFileNames {
+		path := filepath.Join(root, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		file := viper.New()
+		file.SetConfigFile(path)
+		file.SetConfigType("yaml")
+		if err := file.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		for _, hash := range file.AllKeys() {
+			corrections[hash] = file.GetString(hash)
+		}
+		break
+	}
+
+	return corrections, nil
+}
+
+// ReadHolidays reads the holidays of the file, mapping dates to names, like "2024-12-25: Christmas"
+func ReadHolidays(path string) (map[string]string, error) {
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType("yaml")
+	if err := file.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for _, key := range file.AllKeys() {
+		values[key] = file.GetString(key)
+	}
+
+	return gitime.ParseHolidays(values)
+}
+
+// ReadCommentChar returns the character starting the comment lines of commit messages
+// in the repository of the directory, as configured in core.commentChar.
+// When it is "auto", git picks a character per message, and we assume the default one.
+func ReadCommentChar(directory string) string {
+	get := exec.Command("git", "config", "--get", "core.commentChar")
+	get.Dir = directory
+	out, err := get.Output()
+	if err != nil {
+		return gitime.DefaultCommentChar
+	}
+	commentChar := strings.TrimSpace(string(out))
+	if commentChar == "" || commentChar == "auto" {
+		return gitime.DefaultCommentChar
+	}
+
+	return commentChar
+}
+
+// MailmapFileName is the name of the mailmap file git reads at the root of a repository
+const MailmapFileName = ".mailmap"
+
+// mailmapLineRegex matches the valid lines of a mailmap file, like "Proper Name <proper@email> <commit@email>",
+// with an optional trailing comment
+var mailmapLineRegex = regexp.MustCompile(
+	`^(?P<name>[^<>#]*)<(?P<email>[^<>]*)>(?:(?P<commitName>[^<>#]*)<(?P<commitEmail>[^<>]*)>)?\s*(?:#.*)?$`,
+)
+
+// InvalidMailmapLines returns the lines of the mailmap file that git would silently ignore, with their number,
+// like "3: Alice alice@example.com"
+func InvalidMailmapLines(content string) []string {
+	invalid := make([]string, 0)
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || mailmapLineRegex.MatchString(trimmed) {
+			continue
+		}
+		invalid = append(invalid, fmt.Sprintf("%d: %s", i+1, trimmed))
+	}
+
+	return invalid
+}
+
+// ReadMailmap reads the mailmap file at the root of the repository of the directory, if any.
+// The lines git would ignore are ignored as well.
+func ReadMailmap(directory string) ([]*gitime.MailmapEntry, error) {
+	content, err := os.ReadFile(filepath.Join(ReadToplevel(directory), MailmapFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return make([]*gitime.MailmapEntry, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMailmap(string(content)), nil
+}
+
+// parseMailmap parses the valid lines of the content of a mailmap file
+func parseMailmap(content string) []*gitime.MailmapEntry {
+	entries := make([]*gitime.MailmapEntry, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		matches := mailmapLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		group := func(name string) string {
+			return strings.TrimSpace(matches[mailmapLineRegex.SubexpIndex(name)])
+		}
+		entry := &gitime.MailmapEntry{ProperName: group("name"), ProperEmail: group("email")}
+		if group("commitEmail") != "" {
+			entry.CommitName = group("commitName")
+			entry.CommitEmail = group("commitEmail")
+		} else {
+			// Like "Proper Name <commit@email>", which only fixes the name
+			entry.CommitEmail, entry.ProperEmail = entry.ProperEmail, ""
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,31 @@
+package reader
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInvalidMailmapLines(t *testing.T) {
+	mailmap := `# Team
+Alice Doe <alice@example.com>
+<bob@example.com> <bob@old.example.com>
+Carol <carol@example.com> <c@example.com> # renamed
+Dave <dave@example.com> Dave D <dd@example.com>
+
+Eve eve@example.com
+Frank <frank@example.com
+`
+	assert.Equal(t, []string{"7: Eve eve@example.com", "8: Frank <frank@example.com"}, InvalidMailmapLines(mailmap))
+	assert.Empty(t, InvalidMailmapLines(""))
+}
+
+func TestParseMailmap(t *testing.T) {
+	mailmap := "Alice Doe <alice@example.com>\n<bob@example.com> <bob@old.example.com>\n" +
+		"Carol <carol@example.com> C <c@example.com> # renamed\nEve eve@example.com\n"
+	assert.Equal(t, []*gitime.MailmapEntry{
+		{ProperName: "Alice Doe", CommitEmail: "alice@example.com"},
+		{ProperEmail: "bob@example.com", CommitEmail: "bob@old.example.com"},
+		{ProperName: "Carol", ProperEmail: "carol@example.com", CommitName: "C", CommitEmail: "c@example.com"},
+	}, parseMailmap(mailmap))
+}
@@ -0,0 +1,87 @@
+package reader
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+)
+
+// transcodeCommit makes the message and the author of the commit valid UTF-8, in place.
+// Git already re-encodes the commits that declare their encoding (see i18n.commitEncoding),
+// so the only commits left are those git could not re-encode : legacy commits declaring nothing,
+// commits declaring the wrong encoding, or any commit when git was built without iconv.
+// They are decoded from their declared encoding when it fits, or else converted lossily,
+// and then gitime.Commit.LossyEncoding tells which encoding they wrongly declared.
+func transcodeCommit(commit *gitime.Commit, body string, note string, directory string) {
+	if utf8.ValidString(body) && utf8.ValidString(commit.AuthorName) && utf8.ValidString(commit.AuthorEmail) {
+		commit.Message = body + "\n" + strings.ToValidUTF8(note, "�")
+		return
+	}
+
+	declared := readCommitEncoding(commit.Hash, directory)
+	lossless := true
+	for _, text := range []*string{&body, &commit.AuthorName, &commit.AuthorEmail} {
+		decoded, ok := decodeText(*text, declared)
+		*text = decoded
+		lossless = lossless && ok
+	}
+	// Notes are written by git notes, in UTF-8
+	commit.Message = body + "\n" + strings.ToValidUTF8(note, "�")
+	if !lossless {
+		commit.LossyEncoding = declared
+		if declared == "" {
+			commit.LossyEncoding = "UTF-8"
+		}
+	}
+}
+
+// decodeText returns the text in UTF-8, decoded from the declared encoding,
+// and whether it was decoded without replacing anything.
+func decodeText(text string, declared string) (string, bool) {
+	if utf8.ValidString(text) {
+		return text, true
+	}
+	if decoder := lookupEncoding(declared); decoder != nil {
+		decoded, err := decoder.NewDecoder().String(text)
+		if err == nil && utf8.ValidString(decoded) &&
+			strings.Count(decoded, "�") == strings.Count(text, "�") {
+			return decoded, true
+		}
+	}
+
+	return strings.ToValidUTF8(text, "�"), false
+}
+
+// lookupEncoding returns the encoding of the name, like ISO-8859-1 or Shift_JIS, or nil if unknown
+func lookupEncoding(name string) encoding.Encoding {
+	if name == "" {
+		return nil
+	}
+	if found, err := ianaindex.IANA.Encoding(name); err == nil && found != nil {
+		return found
+	}
+	if found, err := htmlindex.Get(name); err == nil {
+		return found
+	}
+
+	return nil
+}
+
+// readCommitEncoding returns the encoding declared by the commit, or an empty string if it declares none
+func readCommitEncoding(hash string, directory string) string {
+	if hash == "" {
+		return ""
+	}
+	show := exec.Command("git", "show", "--no-patch", "--format=%e", hash)
+	show.Dir = directory
+	out, err := show.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
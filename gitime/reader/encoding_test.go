@@ -0,0 +1,31 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		declared string
+		expected string
+		lossless bool
+	}{
+		{"utf-8 is left alone", "café", "ISO-8859-1", "café", true},
+		{"latin-1", "caf\xe9 cr\xe8me", "ISO-8859-1", "café crème", true},
+		{"latin-1 by its alias", "caf\xe9", "latin1", "café", true},
+		{"shift-jis", "\x93\xfa\x96\x7b\x8c\xea", "Shift_JIS", "日本語", true},
+		{"declaring nothing is declaring utf-8", "caf\xe9", "", "caf�", false},
+		{"declaring the wrong encoding", "\xff\xfe", "Shift_JIS", "�", false},
+		{"declaring an unknown encoding", "caf\xe9", "klingon", "caf�", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, lossless := decodeText(tt.text, tt.declared)
+			assert.Equal(t, tt.expected, decoded)
+			assert.Equal(t, tt.lossless, lossless)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package reader
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrGitMissing is returned when the git binary cannot be found in the PATH
+var ErrGitMissing = errors.New("git was not found in the PATH")
+
+// LookGit checks that the git binary is available, since the history is read by running it
+func LookGit() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return ErrGitMissing
+	}
+
+	return nil
+}
+
+// gitVersionRegex matches the version in the output of git --version, like "git version 2.39.3 (Apple Git-145)"
+var gitVersionRegex = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ReadGitVersion returns the version of git, like "2.43.0"
+func ReadGitVersion() (string, error) {
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	version := gitVersionRegex.FindString(string(out))
+	if version == "" {
+		return "", errors.New("cannot read the version of git")
+	}
+
+	return version, nil
+}
+
+// IsGitVersionAtLeast tells whether the version, like "2.43.0", is the minimum version, like "2.31", or above
+func IsGitVersionAtLeast(version string, minimum string) bool {
+	have, want := gitVersionRegex.FindStringSubmatch(version), gitVersionRegex.FindStringSubmatch(minimum)
+	if have == nil || want == nil {
+		return false
+	}
+	for i := 1; i < len(want); i++ {
+		h, _ := strconv.Atoi(have[i])
+		w, _ := strconv.Atoi(want[i])
+		if h != w {
+			return h > w
+		}
+	}
+
+	return true
+}
+
+// IsShallow tells whether the repository of the directory is a shallow clone, missing part of its history
+func IsShallow(directory string) bool {
+	shallow := exec.Command("git", "rev-parse", "--is-shallow-repository")
+	shallow.Dir = directory
+	out, err := shallow.Output()
+
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// ReadHookPath returns the path of the hook of the repository of the directory, like commit-msg,
+// wherever core.hooksPath puts it, or an empty string if the directory is not in a repository
+func ReadHookPath(directory string, hook string) string {
+	path := exec.Command("git", "rev-parse", "--git-path", "hooks/"+hook)
+	path.Dir = directory
+	out, err := path.Output()
+	if err != nil {
+		return ""
+	}
+	hookPath := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hookPath) {
+		hookPath = filepath.Join(directory, hookPath)
+	}
+	if absolute, err := filepath.Abs(hookPath); err == nil {
+		hookPath = absolute
+	}
+
+	return hookPath
+}
@@ -2,50 +2,381 @@ package reader
 
 import (
 	"fmt"
+	"github.com/goutte/git-spend/gitime"
 	"github.com/tsuyoshiwada/go-gitlog"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// ReadGitLog reads the git log of the repository of the specified directpry
+// ReadGitLog reads the git log of the repository of the specified directory
 func ReadGitLog(onlyAuthors []string, excludeMerge bool, since string, until string, directory string) string {
+	s := ""
+	for _, commit := range ReadGitLogCommits(onlyAuthors, excludeMerge, since, until, directory) {
+		s += commit.Message + "\n"
+	}
+
+	return s
+}
+
+// ReadGitLogCommits reads the commits of the git log of the repository of the specified directory,
+// and exits when git log fails
+func ReadGitLogCommits(onlyAuthors []string, excludeMerge bool, since string, until string, directory string) []*gitime.Commit {
+	commits, _, err := ReadGitLogCommitsCounting(onlyAuthors, excludeMerge, since, until, directory)
+	exitOnGitLogError(err)
+
+	return commits
+}
+
+// ReadGitLogCommitsInOrder is like ReadGitLogCommits, but lists the commits in the order, like gitime.OrderTopo,
+// newest first.  Commits of the same timestamp are left in the order git lists them : it never lists a parent
+// before its child, and lists the same history the same way every time.
+func ReadGitLogCommitsInOrder(
+	order string,
+	onlyAuthors []string,
+	excludeMerge bool,
+	since string,
+	until string,
+	directory string,
+) []*gitime.Commit {
+	commits, _, err := readGitLogCommits(order, onlyAuthors, excludeMerge, since, until, directory)
+	exitOnGitLogError(err)
+
+	return commits
+}
+
+// ReadGitLogCommitsCounting is like ReadGitLogCommits, but also returns how many commits were skipped,
+// by reason, like gitime.SkippedMerge.  Merges by other authors are skipped because of their author.
+// It returns the error of git log instead of exiting, like on an unknown revision.
+func ReadGitLogCommitsCounting(
+	onlyAuthors []string,
+	excludeMerge bool,
+	since string,
+	until string,
+	directory string,
+) ([]*gitime.Commit, map[string]int, error) {
+	return readGitLogCommits("", onlyAuthors, excludeMerge, since, until, directory)
+}
+
+// ReadGitLogCommitsInOrderCounting is like ReadGitLogCommitsCounting, but lists the commits in the order,
+// like ReadGitLogCommitsInOrder.
+func ReadGitLogCommitsInOrderCounting(
+	order string,
+	onlyAuthors []string,
+	excludeMerge bool,
+	since string,
+	until string,
+	directory string,
+) ([]*gitime.Commit, map[string]int, error) {
+	return readGitLogCommits(order, onlyAuthors, excludeMerge, since, until, directory)
+}
+
+// readGitLogCommits reads the commits in the order, or in the default order of git log when it is empty
+func readGitLogCommits(
+	order string,
+	onlyAuthors []string,
+	excludeMerge bool,
+	since string,
+	until string,
+	directory string,
+) ([]*gitime.Commit, map[string]int, error) {
+	skipped := map[string]int{}
+	if IsUnborn(directory) {
+		return make([]*gitime.Commit, 0), skipped, nil
+	}
+
 	git := gitlog.New(&gitlog.Config{
 		Path: directory,
 	})
-	rev := getRevArgsFromFlags(since, until)
-	params := &gitlog.Params{
-		IgnoreMerges: excludeMerge,
+	rev, err := getRevArgsFromFlags(since, until)
+	if err != nil {
+		return nil, nil, err
 	}
-	commits, err := git.Log(rev, params)
-	if exitError, isExitError := err.(*exec.ExitError); isExitError {
-		fmt.Println("git command unsuccessful:", err, "—", exitError.Stderr)
-		os.Exit(exitError.ExitCode())
+	if order != "" {
+		rev = &revOrdered{Order: order, Rev: rev}
 	}
+	commits, err := readGitLog(git, rev, &gitlog.Params{IgnoreMerges: excludeMerge})
 	if err != nil {
-		fmt.Println("cannot read git log:", err)
-		os.Exit(1)
+		return nil, nil, err
+	}
+	if excludeMerge {
+		merges, err := readGitLog(git, rev, &gitlog.Params{MergesOnly: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, merge := range merges {
+			if isCommitByAnyAuthor(toCommit(merge, directory), onlyAuthors) {
+				skipped[gitime.SkippedMerge]++
+			} else {
+				skipped[gitime.SkippedAuthor]++
+			}
+		}
 	}
 
-	s := ""
+	filtered := make([]*gitime.Commit, 0, len(commits))
 	for _, commit := range commits {
-		if !isCommitByAnyAuthor(commit, onlyAuthors) {
+		c := toCommit(commit, directory)
+		if !isCommitByAnyAuthor(c, onlyAuthors) {
+			skipped[gitime.SkippedAuthor]++
 			continue
 		}
+		filtered = append(filtered, c)
+	}
+
+	return filtered, skipped, nil
+}
 
-		// We read from the raw body because some newlines are eaten when separating subject an body.
-		// My non-tech friend commits without separating subject and body, like this:
-		//   > style: something amazing
-		//   > /spent 0.5h
-		// … and the "/spend 0.5h" ends up at the end of the Subject, without newline.
-		s += commit.RawBody + "\n"
-		// We also read from the note, and it might or might not be correct.
-		s += commit.Note + "\n"
+// readGitLog runs git log
+func readGitLog(git gitlog.GitLog, rev gitlog.RevArgs, params *gitlog.Params) ([]*gitlog.Commit, error) {
+	commits, err := git.Log(rev, params)
+	if exitError, isExitError := err.(*exec.ExitError); isExitError {
+		return nil, &gitLogError{
+			message:  fmt.Sprintf("git command unsuccessful: %v — %s", err, strings.TrimSpace(string(exitError.Stderr))),
+			exitCode: exitError.ExitCode(),
+		}
+	}
+	if err != nil {
+		return nil, &gitLogError{message: fmt.Sprintf("cannot read git log: %v", err), exitCode: 1}
 	}
 
-	return s
+	return commits, nil
 }
 
-func getRevArgsFromFlags(since string, until string) gitlog.RevArgs {
+// gitLogError is the failure of reading the git log, with the exit code of git, if any
+type gitLogError struct {
+	message  string
+	exitCode int
+}
+
+func (e *gitLogError) Error() string {
+	return e.message
+}
+
+// exitOnGitLogError prints the error of reading the git log, if any, and exits with the exit code of git
+func exitOnGitLogError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Println(err)
+	if e, ok := err.(*gitLogError); ok {
+		os.Exit(e.exitCode)
+	}
+	os.Exit(1)
+}
+
+// IsUnborn tells whether the directory is in a repository without any commit yet,
+// like a freshly initialized repository, or an orphan branch before its first commit.
+func IsUnborn(directory string) bool {
+	head := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	head.Dir = directory
+	if head.Run() == nil {
+		return false
+	}
+	repository := exec.Command("git", "rev-parse", "--git-dir")
+	repository.Dir = directory
+
+	return repository.Run() == nil
+}
+
+// ReadHead returns the hash of the commit HEAD points to, or an empty string if there is none
+func ReadHead(directory string) string {
+	head := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	head.Dir = directory
+	out, err := head.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// ReadGitDir returns the absolute path of the git directory of the repository of the directory,
+// or an empty string if the directory is not in a repository
+func ReadGitDir(directory string) string {
+	gitDir := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	gitDir.Dir = directory
+	out, err := gitDir.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// ReadToplevel returns the absolute path of the root of the repository of the directory,
+// or the directory itself if it is not in a repository
+func ReadToplevel(directory string) string {
+	toplevel := exec.Command("git", "rev-parse", "--show-toplevel")
+	toplevel.Dir = directory
+	out, err := toplevel.Output()
+	if err != nil {
+		return directory
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// ResolveBound returns what a --since or --until value resolves to in the repository of the directory:
+// dates are written in full (RFC3339), and refs are followed by the short hash of their commit.
+// Values that resolve to nothing are returned as is.
+func ResolveBound(bound string, directory string) string {
+	if bound == "" {
+		return ""
+	}
+	if date := parseTimePerhaps(bound); date != nil {
+		return date.Format(time.RFC3339)
+	}
+	short := exec.Command("git", "rev-parse", "--short", "--verify", "--quiet", bound+"^{commit}")
+	short.Dir = directory
+	out, err := short.Output()
+	if err != nil {
+		return bound
+	}
+
+	return fmt.Sprintf("%s (%s)", bound, strings.TrimSpace(string(out)))
+}
+
+// FreezeBound returns the --since or --until value in a form that reads the same commits later on:
+// dates are written in full (RFC3339) with their offset, and refs are replaced by the full hash of their commit.
+func FreezeBound(bound string, directory string) (string, error) {
+	if bound == "" {
+		return "", nil
+	}
+	if date := parseTimePerhaps(bound); date != nil {
+		return date.Format(time.RFC3339), nil
+	}
+	verify := exec.Command("git", "rev-parse", "--verify", "--quiet", bound+"^{commit}")
+	verify.Dir = directory
+	out, err := verify.Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s to a commit", bound)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// IsAncestor tells whether the commit of the hash is reachable from the commit of the descendant,
+// or is the descendant itself.  Commits git does not know are not reachable.
+func IsAncestor(hash string, descendant string, directory string) bool {
+	if hash == "" || descendant == "" {
+		return false
+	}
+	isAncestor := exec.Command("git", "merge-base", "--is-ancestor", hash, descendant)
+	isAncestor.Dir = directory
+
+	return isAncestor.Run() == nil
+}
+
+// ReadGitCommit reads the single commit the ref (hash, tag, HEAD~3…) resolves to
+func ReadGitCommit(ref string, directory string) (*gitime.Commit, error) {
+	verify := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	verify.Dir = directory
+	if err := verify.Run(); err != nil {
+		return nil, fmt.Errorf("cannot resolve %s to a commit", ref)
+	}
+
+	git := gitlog.New(&gitlog.Config{
+		Path: directory,
+	})
+	commits, err := git.Log(&revSingle{Ref: ref}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("cannot resolve %s to a commit", ref)
+	}
+
+	return toCommit(commits[0], directory), nil
+}
+
+// ReadRecentCommits reads the count most recent commits reachable from HEAD, most recent first
+func ReadRecentCommits(count int, directory string) ([]*gitime.Commit, error) {
+	recent := make([]*gitime.Commit, 0, count)
+	if IsUnborn(directory) {
+		return recent, nil
+	}
+
+	git := gitlog.New(&gitlog.Config{
+		Path: directory,
+	})
+	commits, err := git.Log(&revRecent{Count: count}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, commit := range commits {
+		recent = append(recent, toCommit(commit, directory))
+	}
+
+	return recent, nil
+}
+
+// revRecent is the RevArgs of the most recent commits reachable from HEAD
+type revRecent struct {
+	Count int
+}
+
+func (rev *revRecent) Args() []string {
+	return []string{"-n", strconv.Itoa(rev.Count), "HEAD"}
+}
+
+// orderFlags are the flags of git log listing the commits in each order
+var orderFlags = map[string]string{
+	gitime.OrderAuthorDate: "--author-date-order",
+	gitime.OrderCommitDate: "--date-order",
+	gitime.OrderTopo:       "--topo-order",
+}
+
+// revOrdered is the RevArgs of another RevArgs (or of HEAD when nil), listed in an order
+type revOrdered struct {
+	Order string
+	Rev   gitlog.RevArgs
+}
+
+func (rev *revOrdered) Args() []string {
+	args := []string{orderFlags[rev.Order]}
+	if rev.Rev != nil {
+		args = append(args, rev.Rev.Args()...)
+	}
+
+	return args
+}
+
+// revSingle is the RevArgs of the single commit a ref points to
+type revSingle struct {
+	Ref string
+}
+
+func (rev *revSingle) Args() []string {
+	return []string{"-n", "1", rev.Ref}
+}
+
+// toCommit converts the commit from gitlog of the repository of the directory into ours, in UTF-8
+func toCommit(commit *gitlog.Commit, directory string) *gitime.Commit {
+	c := &gitime.Commit{
+		Hash: commit.Hash.Long,
+	}
+	if commit.Author != nil {
+		c.AuthorName = commit.Author.Name
+		c.AuthorEmail = commit.Author.Email
+		c.Date = commit.Author.Date
+	}
+	if commit.Committer != nil {
+		c.CommitDate = commit.Committer.Date
+	}
+	// We read from the raw body because some newlines are eaten when separating subject an body.
+	// My non-tech friend commits without separating subject and body, like this:
+	//   > style: something amazing
+	//   > /spent 0.5h
+	// … and the "/spend 0.5h" ends up at the end of the Subject, without newline.
+	// We also read from the note, and it might or might not be correct.
+	transcodeCommit(c, commit.RawBody, commit.Note, directory)
+
+	return c
+}
+
+func getRevArgsFromFlags(since string, until string) (gitlog.RevArgs, error) {
 	var rev gitlog.RevArgs = nil
 	if since != "" {
 		sinceTime := parseTimePerhaps(since)
@@ -60,8 +391,7 @@ func getRevArgsFromFlags(since string, until string) gitlog.RevArgs {
 						Until: *untilTime,
 					}
 				} else {
-					fmt.Println("unsupported mix of dates and refs in --until and --since")
-					os.Exit(1)
+					return nil, fmt.Errorf("unsupported mix of dates and refs in --until and --since")
 				}
 			} else {
 				if sinceTime == nil {
@@ -70,8 +400,7 @@ func getRevArgsFromFlags(since string, until string) gitlog.RevArgs {
 						Old: since,
 					}
 				} else {
-					fmt.Println("unsupported mix of dates and refs in --since and --until")
-					os.Exit(1)
+					return nil, fmt.Errorf("unsupported mix of dates and refs in --since and --until")
 				}
 			}
 		} else {
@@ -100,23 +429,16 @@ func getRevArgsFromFlags(since string, until string) gitlog.RevArgs {
 			}
 		}
 	}
-	return rev
+	return rev, nil
 }
 
-func isCommitByAnyAuthor(commit *gitlog.Commit, authors []string) bool {
+func isCommitByAnyAuthor(commit *gitime.Commit, authors []string) bool {
 	if len(authors) == 0 {
 		return true
 	}
 
-	if commit.Author == nil {
-		return false
-	}
-
 	for _, author := range authors {
-		if commit.Author.Name == author {
-			return true
-		}
-		if commit.Author.Email == author {
+		if commit.IsAuthoredBy(author) {
 			return true
 		}
 	}
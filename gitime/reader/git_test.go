@@ -0,0 +1,44 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookGit(t *testing.T) {
+	assert.NoError(t, LookGit())
+
+	t.Setenv("PATH", t.TempDir())
+	assert.ErrorIs(t, LookGit(), ErrGitMissing)
+	// Readers degrade gracefully instead of crashing
+	assert.Equal(t, "", ReadHead("."))
+	assert.False(t, IsUnborn("."))
+}
+
+func TestIsGitVersionAtLeast(t *testing.T) {
+	assert.True(t, IsGitVersionAtLeast("2.43.0", "2.31"))
+	assert.True(t, IsGitVersionAtLeast("2.31", "2.31"))
+	assert.True(t, IsGitVersionAtLeast("3.0.1", "2.31"))
+	assert.False(t, IsGitVersionAtLeast("2.30.9", "2.31"))
+	assert.False(t, IsGitVersionAtLeast("1.9", "2.31"))
+	assert.False(t, IsGitVersionAtLeast("unknown", "2.31"))
+
+	version, err := ReadGitVersion()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\d+\.\d+`, version)
+}
+
+func TestReadHookPath(t *testing.T) {
+	repository := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--quiet", repository).Run())
+	require.NoError(t, os.Mkdir(filepath.Join(repository, "sub"), 0755))
+	gitDir := ReadGitDir(repository)
+	assert.Equal(t, filepath.Join(gitDir, "hooks", "commit-msg"), ReadHookPath(filepath.Join(repository, "sub"), "commit-msg"))
+	assert.False(t, IsShallow(repository))
+
+	assert.Equal(t, "", ReadHookPath(t.TempDir(), "commit-msg"))
+}
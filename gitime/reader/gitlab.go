@@ -0,0 +1,164 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultGitlabURL is the GitLab instance used when none is specified
+const DefaultGitlabURL = "https://gitlab.com"
+
+// GitlabProject is a project of a GitLab group, as listed by the API
+type GitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	HttpUrlToRepo     string `json:"http_url_to_repo"`
+}
+
+// GitlabMergeRequest is a merged merge request of a GitLab project, as listed by the API
+type GitlabMergeRequest struct {
+	Iid             int        `json:"iid"`
+	Description     string     `json:"description"`
+	MergeCommitSha  string     `json:"merge_commit_sha"`
+	SquashCommitSha string     `json:"squash_commit_sha"`
+	MergedAt        *time.Time `json:"merged_at"`
+	Author          struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// GitlabCommit is a commit of a GitLab merge request, as listed by the API
+type GitlabCommit struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// ReadGitlabGroupProjects lists the projects of the group and of its subgroups that the token may see,
+// following the pages of the API.
+func ReadGitlabGroupProjects(baseURL string, group string, token string) ([]*GitlabProject, error) {
+	projects := make([]*GitlabProject, 0)
+	page := "1"
+	for page != "" {
+		endpoint := fmt.Sprintf(
+			"%s/api/v4/groups/%s/projects?include_subgroups=true&per_page=100&page=%s",
+			strings.TrimRight(baseURL, "/"),
+			url.PathEscape(group),
+			url.QueryEscape(page),
+		)
+		pageProjects := make([]*GitlabProject, 0)
+		next, err := readGitlabPage(endpoint, token, &pageProjects)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the projects of the GitLab group %s: %w", group, err)
+		}
+		projects = append(projects, pageProjects...)
+		page = next
+	}
+
+	return projects, nil
+}
+
+// ReadGitlabMergedMergeRequests lists the merged merge requests of the project, following the pages of the API
+func ReadGitlabMergedMergeRequests(baseURL string, project string, token string) ([]*GitlabMergeRequest, error) {
+	requests := make([]*GitlabMergeRequest, 0)
+	page := "1"
+	for page != "" {
+		endpoint := fmt.Sprintf(
+			"%s/api/v4/projects/%s/merge_requests?state=merged&per_page=100&page=%s",
+			strings.TrimRight(baseURL, "/"),
+			url.PathEscape(project),
+			url.QueryEscape(page),
+		)
+		pageRequests := make([]*GitlabMergeRequest, 0)
+		next, err := readGitlabPage(endpoint, token, &pageRequests)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the merge requests of the GitLab project %s: %w", project, err)
+		}
+		requests = append(requests, pageRequests...)
+		page = next
+	}
+
+	return requests, nil
+}
+
+// ReadGitlabMergeRequestCommits lists the commits of the merge request of the project, following the pages of the API
+func ReadGitlabMergeRequestCommits(baseURL string, project string, iid int, token string) ([]*GitlabCommit, error) {
+	commits := make([]*GitlabCommit, 0)
+	page := "1"
+	for page != "" {
+		endpoint := fmt.Sprintf(
+			"%s/api/v4/projects/%s/merge_requests/%d/commits?per_page=100&page=%s",
+			strings.TrimRight(baseURL, "/"),
+			url.PathEscape(project),
+			iid,
+			url.QueryEscape(page),
+		)
+		pageCommits := make([]*GitlabCommit, 0)
+		next, err := readGitlabPage(endpoint, token, &pageCommits)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the commits of the merge request %s!%d: %w", project, iid, err)
+		}
+		commits = append(commits, pageCommits...)
+		page = next
+	}
+
+	return commits, nil
+}
+
+// readGitlabPage decodes a page of the API into the value, and returns the number of the next page, if any
+func readGitlabPage(endpoint string, token string, value interface{}) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		request.Header.Set("PRIVATE-TOKEN", token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s", response.Status)
+	}
+	err = json.NewDecoder(response.Body).Decode(value)
+	if err != nil {
+		return "", err
+	}
+
+	return response.Header.Get("X-Next-Page"), nil
+}
+
+// scpLikeRegex matches the remote URLs like git@gitlab.com:group/project.git
+var scpLikeRegex = regexp.MustCompile(`^(?:[^@/]+@)?(?P<host>[^:/]+):(?P<path>[^/].*)$`)
+
+// GitlabProjectFromRemote returns the URL of the GitLab instance and the path of the project of the remote URL,
+// like "https://gitlab.com" and "group/project" for git@gitlab.com:group/project.git,
+// or empty strings when the URL cannot be understood.
+func GitlabProjectFromRemote(remoteURL string) (string, string) {
+	host, path := "", ""
+	scheme := "https"
+	if parsed, err := url.Parse(remoteURL); err == nil && parsed.Host != "" {
+		host, path = parsed.Hostname(), parsed.Path
+		if parsed.Scheme == "http" {
+			scheme = "http"
+			host = parsed.Host
+		} else if parsed.Scheme == "https" {
+			host = parsed.Host
+		}
+	} else if matches := scpLikeRegex.FindStringSubmatch(remoteURL); matches != nil {
+		host = matches[scpLikeRegex.SubexpIndex("host")]
+		path = matches[scpLikeRegex.SubexpIndex("path")]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", ""
+	}
+
+	return scheme + "://" + host, path
+}
@@ -0,0 +1,60 @@
+package reader
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/goutte/git-spend/gitime/statefile"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// mirrorLockTimeout is how long to wait for another process syncing the same mirror, which may be slow
+const mirrorLockTimeout = 5 * time.Minute
+
+// SyncMirror clones the repository at the URL into a mirror in the cache directory,
+// or fetches it when it was cloned already, and returns the path of the mirror.
+// Neither blobs nor files are checked out, since only the commits are needed,
+// and the branches are fetched as they are in the remote.
+// The token, if any, is given to git through the environment, and never written in the mirror.
+// Mirrors are locked while they sync, so that concurrent invocations do not clone into each other.
+func SyncMirror(repositoryURL string, name string, cacheDirectory string, token string) (string, error) {
+	path := filepath.Join(cacheDirectory, filepath.FromSlash(name))
+	lock, err := statefile.Acquire(path, mirrorLockTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	var sync *exec.Cmd
+	if _, err := os.Stat(path); err == nil {
+		sync = exec.Command(
+			"git", "fetch", "--quiet", "--prune", "--update-head-ok",
+			"origin", "+refs/heads/*:refs/heads/*",
+		)
+		sync.Dir = path
+	} else {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return "", err
+		}
+		sync = exec.Command("git", "clone", "--quiet", "--no-checkout", "--filter=blob:none", repositoryURL, path)
+	}
+	sync.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte("oauth2:" + token))
+		sync.Env = append(
+			sync.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	if out, err := sync.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("cannot sync %s: %s", name, strings.TrimSpace(string(out)))
+	}
+
+	return path, nil
+}
@@ -0,0 +1,36 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncMirror(t *testing.T) {
+	origin := filepath.Join(t.TempDir(), "origin")
+	commit := func(message string) {
+		c := exec.Command("git", "-c", "user.name=Alice", "-c", "user.email=alice@example.com",
+			"commit", "--quiet", "--allow-empty", "-m", message)
+		c.Dir = origin
+		require.NoError(t, c.Run())
+	}
+	require.NoError(t, exec.Command("git", "init", "--quiet", origin).Run())
+	commit("feat: one\n\n/spend 1h")
+
+	cache := t.TempDir()
+	path, err := SyncMirror(origin, "group/project", cache, "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cache, "group", "project"), path)
+	assert.Len(t, ReadGitLogCommits(nil, false, "", "", path), 1)
+
+	// Subsequent syncs only fetch
+	commit("feat: two\n\n/spend 2h")
+	path, err = SyncMirror(origin, "group/project", cache, "")
+	require.NoError(t, err)
+	assert.Len(t, ReadGitLogCommits(nil, false, "", "", path), 2)
+
+	_, err = SyncMirror(filepath.Join(origin, "nope"), "group/nope", cache, "")
+	assert.Error(t, err)
+}
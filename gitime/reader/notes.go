@@ -0,0 +1,156 @@
+package reader
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime/statefile"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// notesLockName is the file of the git directory locked while git-spend writes notes,
+// so that concurrent invocations, like parallel CI jobs, take turns
+const notesLockName = "git-spend-notes"
+
+// notesLockTimeout is how long to wait for another git-spend process writing notes
+const notesLockTimeout = time.Minute
+
+// notesAttempts is how many times a write of the notes ref is tried,
+// since other processes (git-spend or not) may move the ref in between
+const notesAttempts = 5
+
+// notesBackoff is how long to wait before the first retry, doubled at each retry
+const notesBackoff = 100 * time.Millisecond
+
+// notesIdentity is who commits the notes when git has no identity configured, like in some CI jobs
+var notesIdentity = []string{
+	"GIT_AUTHOR_NAME=git-spend",
+	"GIT_AUTHOR_EMAIL=git-spend@localhost",
+	"GIT_COMMITTER_NAME=git-spend",
+	"GIT_COMMITTER_EMAIL=git-spend@localhost",
+}
+
+// LockNotes locks the notes of the repository of the directory against other git-spend processes,
+// until the returned function is called
+func LockNotes(directory string) (func(), error) {
+	gitDir := ReadGitDir(directory)
+	if gitDir == "" {
+		return nil, fmt.Errorf("%s is not in a git repository", directory)
+	}
+	lock, err := statefile.Acquire(filepath.Join(gitDir, notesLockName), notesLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() { _ = lock.Unlock() }, nil
+}
+
+// ReadNotes returns the content of the notes of the ref, by full hash of the commit they annotate
+func ReadNotes(ref string, directory string) (map[string]string, error) {
+	notes := make(map[string]string)
+	list := exec.Command("git", "notes", "--ref", ref, "list")
+	list.Dir = directory
+	out, err := list.Output()
+	if err != nil {
+		// The ref does not exist yet
+		return notes, nil
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		blob, commit, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		show := exec.Command("git", "cat-file", "blob", blob)
+		show.Dir = directory
+		content, err := show.Output()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the note of %s: %s", commit, err)
+		}
+		notes[commit] = string(content)
+	}
+
+	return notes, nil
+}
+
+// WriteNote attaches the content to the commit in the notes of the ref, replacing any previous note
+func WriteNote(ref string, hash string, content string, directory string) error {
+	return retryGit(directory, content, "notes", "--ref", ref, "add", "--force", "--file=-", hash)
+}
+
+// RemoveNote removes the note of the commit from the notes of the ref, if any
+func RemoveNote(ref string, hash string, directory string) error {
+	return retryGit(directory, "", "notes", "--ref", ref, "remove", "--ignore-missing", hash)
+}
+
+// PushNotes pushes the notes of the ref to the remote.  When the push is rejected because the remote ref moved,
+// like when another CI job pushed its notes first, the remote notes are fetched and merged, and the push retried.
+// Our notes win the conflicts, since they were computed last.
+func PushNotes(ref string, remote string, directory string) error {
+	fetched := ref + "-fetched"
+	defer func() {
+		cleanup := exec.Command("git", "update-ref", "-d", fetched)
+		cleanup.Dir = directory
+		_ = cleanup.Run()
+	}()
+	var err error
+	backoff := notesBackoff
+	for attempt := 1; attempt <= notesAttempts; attempt++ {
+		if err = runGit(directory, "", "push", "--quiet", remote, ref+":"+ref); err == nil {
+			return nil
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if fetchErr := runGit(directory, "", "fetch", "--quiet", remote, "+"+ref+":"+fetched); fetchErr != nil {
+			continue
+		}
+		merge := []string{"notes", "--ref", ref, "merge", "--quiet", "--strategy=ours", fetched}
+		if mergeErr := runGit(directory, "", merge...); mergeErr != nil {
+			return mergeErr
+		}
+	}
+
+	return fmt.Errorf("cannot push the notes %s to %s: %s", ref, remote, err)
+}
+
+// retryGit runs git, again after a while when it fails, like when another process moved the ref it updates
+func retryGit(directory string, stdin string, args ...string) error {
+	var err error
+	backoff := notesBackoff
+	for attempt := 1; attempt <= notesAttempts; attempt++ {
+		if err = runGit(directory, stdin, args...); err == nil || attempt == notesAttempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	return err
+}
+
+// runGit runs git in the directory, feeding it the stdin if any, and returns its output as the error if it fails
+func runGit(directory string, stdin string, args ...string) error {
+	c := exec.Command("git", args...)
+	c.Dir = directory
+	if !hasIdentity(directory) {
+		c.Env = append(os.Environ(), notesIdentity...)
+	}
+	if stdin != "" {
+		c.Stdin = strings.NewReader(stdin)
+	}
+	out, err := c.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// hasIdentity tells whether git knows who commits in the repository of the directory
+func hasIdentity(directory string) bool {
+	ident := exec.Command("git", "var", "GIT_COMMITTER_IDENT")
+	ident.Dir = directory
+
+	return ident.Run() == nil
+}
@@ -0,0 +1,37 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"testing"
+)
+
+func TestWriteAndRemoveNote(t *testing.T) {
+	repository := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--quiet", repository).Run())
+	commit := exec.Command("git", "-c", "user.name=Alice", "-c", "user.email=alice@example.com",
+		"commit", "--quiet", "--allow-empty", "-m", "feat: one\n\n/spend 1h")
+	commit.Dir = repository
+	require.NoError(t, commit.Run())
+	head := ReadHead(repository)
+
+	notes, err := ReadNotes("refs/notes/test", repository)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	unlock, err := LockNotes(repository)
+	require.NoError(t, err)
+	defer unlock()
+	require.NoError(t, WriteNote("refs/notes/test", head, "minutes: 60\n", repository))
+	require.NoError(t, WriteNote("refs/notes/test", head, "minutes: 90\n", repository))
+	notes, err = ReadNotes("refs/notes/test", repository)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{head: "minutes: 90\n"}, notes)
+
+	require.NoError(t, RemoveNote("refs/notes/test", head, repository))
+	require.NoError(t, RemoveNote("refs/notes/test", head, repository))
+	notes, err = ReadNotes("refs/notes/test", repository)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+}
@@ -0,0 +1,97 @@
+package reader
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestReadGitLogCommitsInOrder(t *testing.T) {
+	repository := t.TempDir()
+	git := func(env []string, args ...string) {
+		c := exec.Command("git", append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		c.Dir = repository
+		c.Env = append(c.Environ(), env...)
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	commit := func(message string, authored string, committed string) {
+		git([]string{
+			"GIT_AUTHOR_DATE=2024-03-04T" + authored + ":00Z",
+			"GIT_COMMITTER_DATE=2024-03-04T" + committed + ":00Z",
+		}, "commit", "--quiet", "--allow-empty", "-m", message)
+	}
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--initial-branch=main", repository).Run())
+
+	// Two branches interleaved in time, with c authored at the same time as d,
+	// but committed at another time, like when rebased
+	commit("a", "10:00", "10:00")
+	git(nil, "checkout", "--quiet", "-b", "feature")
+	commit("b", "11:00", "11:00")
+	commit("d", "12:00", "13:00")
+	git(nil, "checkout", "--quiet", "main")
+	commit("c", "12:00", "12:30")
+	git([]string{
+		"GIT_AUTHOR_DATE=2024-03-04T16:00:00Z",
+		"GIT_COMMITTER_DATE=2024-03-04T16:00:00Z",
+	}, "merge", "--quiet", "--no-ff", "-m", "m", "feature")
+
+	messagesOf := func(order string) string {
+		messages := make([]string, 0)
+		for _, c := range ReadGitLogCommitsInOrder(order, nil, false, "", "", repository) {
+			messages = append(messages, strings.TrimSpace(c.Message))
+		}
+		return strings.Join(messages, "")
+	}
+	byAuthorDate := messagesOf(gitime.OrderAuthorDate)
+	assert.Contains(t, []string{"mcdba", "mdcba"}, byAuthorDate)
+	assert.Equal(t, "mdcba", messagesOf(gitime.OrderCommitDate))
+	assert.Contains(t, []string{"mdbca", "mcdba"}, messagesOf(gitime.OrderTopo), "branches are not intermixed")
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, byAuthorDate, messagesOf(gitime.OrderAuthorDate), "the order is stable")
+	}
+}
+
+func TestReadGitLogCommitsInOrderOfTheSameDate(t *testing.T) {
+	repository := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--initial-branch=main", repository).Run())
+	// A linear history committed within the same second, like a scripted rebase
+	for _, message := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		c := exec.Command("git", "-c", "user.name=Alice", "-c", "user.email=alice@example.com",
+			"commit", "--quiet", "--allow-empty", "-m", message)
+		c.Dir = repository
+		c.Env = append(c.Environ(), "GIT_AUTHOR_DATE=2024-03-04T10:00:00Z", "GIT_COMMITTER_DATE=2024-03-04T10:00:00Z")
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	for _, order := range gitime.SupportedOrders {
+		messages := make([]string, 0)
+		for _, c := range ReadGitLogCommitsInOrder(order, nil, false, "", "", repository) {
+			messages = append(messages, strings.TrimSpace(c.Message))
+		}
+		assert.Equal(t, "hgfedcba", strings.Join(messages, ""), "children are listed before their parents by "+order)
+	}
+}
+
+func TestReadGitLogCommitsCountingFailures(t *testing.T) {
+	repository := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--initial-branch=main", repository).Run())
+	c := exec.Command("git", "-c", "user.name=Alice", "-c", "user.email=alice@example.com",
+		"commit", "--quiet", "--allow-empty", "-m", "a")
+	c.Dir = repository
+	require.NoError(t, c.Run())
+
+	commits, skipped, err := ReadGitLogCommitsCounting(nil, false, "", "", repository)
+	require.NoError(t, err)
+	assert.Len(t, commits, 1)
+	assert.Empty(t, skipped)
+
+	_, _, err = ReadGitLogCommitsCounting(nil, false, "HEAD", "nope", repository)
+	assert.ErrorContains(t, err, "git command unsuccessful", "unknown revisions are returned, and do not exit")
+	_, _, err = ReadGitLogCommitsCounting(nil, true, "2024-03-04", "HEAD", repository)
+	assert.ErrorContains(t, err, "unsupported mix of dates and refs")
+}
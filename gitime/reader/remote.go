@@ -0,0 +1,19 @@
+package reader
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// ReadRemoteURL returns the URL of the remote of the repository of the directory,
+// or an empty string if there is no such remote.
+func ReadRemoteURL(directory string, remote string) string {
+	getURL := exec.Command("git", "remote", "get-url", remote)
+	getURL.Dir = directory
+	out, err := getURL.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
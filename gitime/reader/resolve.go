@@ -0,0 +1,77 @@
+package reader
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Resolution sorts a list of commit hashes by how they resolve in a repository
+type Resolution struct {
+	// Resolved are the full hashes of the commits, in order, without duplicates
+	Resolved []string
+	// Commits are the full hashes of the commits, by the hashes that resolved to them
+	Commits map[string]string
+	// Ambiguous are the abbreviated hashes matching more than one object
+	Ambiguous []string
+	// Unknown are the hashes matching no commit at all
+	Unknown []string
+}
+
+// ReadStdinHashes reads the commit hashes listed in stdin, separated by spaces or newlines
+func ReadStdinHashes() []string {
+	return strings.Fields(ReadStdin())
+}
+
+// ResolveCommits resolves the (perhaps abbreviated) hashes in the repository of the directory,
+// asking git about all of them at once.  Annotated tags are peeled to their commits.
+// Each hash is asked twice : peeled to a commit, and as is, since git tells a peeled hash matching
+// several commits apart from a missing one only when it is asked as is.
+func ResolveCommits(hashes []string, directory string) (*Resolution, error) {
+	resolution := &Resolution{
+		Resolved:  make([]string, 0, len(hashes)),
+		Commits:   make(map[string]string, len(hashes)),
+		Ambiguous: make([]string, 0),
+		Unknown:   make([]string, 0),
+	}
+	if len(hashes) == 0 {
+		return resolution, nil
+	}
+
+	batch := exec.Command("git", "cat-file", "--batch-check=%(objectname) %(objecttype)")
+	batch.Dir = directory
+	inputs := make([]string, 0, 2*len(hashes))
+	for _, hash := range hashes {
+		inputs = append(inputs, hash+"^{commit}", hash)
+	}
+	batch.Stdin = strings.NewReader(strings.Join(inputs, "\n") + "\n")
+	out, err := batch.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve the commits: %s", err)
+	}
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for i := 0; scanner.Scan() && i < len(hashes); i++ {
+		object, kind, _ := strings.Cut(scanner.Text(), " ")
+		if !scanner.Scan() {
+			break
+		}
+		_, kindAsIs, _ := strings.Cut(scanner.Text(), " ")
+		switch {
+		case kind != "commit" && kindAsIs == "ambiguous":
+			resolution.Ambiguous = append(resolution.Ambiguous, hashes[i])
+		case kind != "commit":
+			resolution.Unknown = append(resolution.Unknown, hashes[i])
+		default:
+			resolution.Commits[hashes[i]] = object
+			if !seen[object] {
+				seen[object] = true
+				resolution.Resolved = append(resolution.Resolved, object)
+			}
+		}
+	}
+
+	return resolution, nil
+}
@@ -0,0 +1,80 @@
+package reader
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestResolveCommits(t *testing.T) {
+	resolution, err := ResolveCommits([]string{"HEAD", "HEAD", "0000000deadbeef"}, ".")
+	require.NoError(t, err)
+	// Duplicates are only counted once
+	require.Len(t, resolution.Resolved, 1)
+	assert.Len(t, resolution.Resolved[0], 40)
+	assert.Equal(t, map[string]string{"HEAD": resolution.Resolved[0]}, resolution.Commits)
+	assert.Empty(t, resolution.Ambiguous)
+	assert.Equal(t, []string{"0000000deadbeef"}, resolution.Unknown)
+}
+
+func TestResolveCommitsPeelsAnnotatedTags(t *testing.T) {
+	repository := t.TempDir()
+	git := func(args ...string) string {
+		c := exec.Command("git", append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		c.Dir = repository
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "-m", "feat: a\n\n/spend 1h")
+	git("tag", "--annotate", "v1.0", "-m", "v1.0")
+	commit := git("rev-parse", "HEAD")
+	tag := git("rev-parse", "v1.0")
+	require.NotEqual(t, commit, tag)
+
+	resolution, err := ResolveCommits([]string{tag, tag[:10], "v1.0", git("rev-parse", "HEAD^{tree}")}, repository)
+	require.NoError(t, err)
+	assert.Equal(t, []string{commit}, resolution.Resolved)
+	assert.Empty(t, resolution.Ambiguous)
+	assert.Len(t, resolution.Unknown, 1, "trees are not commits")
+}
+
+func TestResolveCommitsOfAmbiguousHashes(t *testing.T) {
+	repository := t.TempDir()
+	git := func(args ...string) string {
+		c := exec.Command("git", append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		c.Dir = repository
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	tree := git("write-tree")
+	// Commit until two commits share the shortest prefix git accepts, which takes a few hundred commits
+	prefix := ""
+	seen := map[string]bool{}
+	for i := 0; i < 5000 && prefix == ""; i++ {
+		hash := git("commit-tree", tree, "-m", fmt.Sprintf("commit %d", i))
+		if seen[hash[:4]] {
+			prefix = hash[:4]
+		}
+		seen[hash[:4]] = true
+	}
+	require.NotEmpty(t, prefix)
+
+	resolution, err := ResolveCommits([]string{prefix}, repository)
+	require.NoError(t, err)
+	assert.Equal(t, []string{prefix}, resolution.Ambiguous)
+	assert.Empty(t, resolution.Resolved)
+	assert.Empty(t, resolution.Commits)
+}
+
+func TestResolveCommitsOfNothing(t *testing.T) {
+	resolution, err := ResolveCommits([]string{}, ".")
+	require.NoError(t, err)
+	assert.Empty(t, resolution.Resolved)
+}
@@ -2,11 +2,77 @@ package reader
 
 import (
 	"fmt"
+	"github.com/goutte/git-spend/gitime"
 	"io"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 )
 
+// gitLogCommitRegex matches the first line of each commit in the default output of git log
+var gitLogCommitRegex = regexp.MustCompile("^commit ([0-9a-f]{7,64})")
+
+// gitLogDateLayout is the default date format of git log
+const gitLogDateLayout = "Mon Jan 2 15:04:05 2006 -0700"
+
 func ReadStdin() string {
 	stdin, _ := io.ReadAll(os.Stdin)
 	return fmt.Sprintf("%s", stdin)
 }
+
+// ReadStdinCommits reads stdin and splits it into commits if it looks like the output of git log.
+// Otherwise, the whole of stdin is considered as the message of a single commit without hash.
+func ReadStdinCommits() []*gitime.Commit {
+	return splitGitLogOutput(ReadStdin())
+}
+
+func splitGitLogOutput(log string) []*gitime.Commit {
+	commits := make([]*gitime.Commit, 0)
+	var commit *gitime.Commit
+	inHeader := false
+	for _, line := range strings.Split(log, "\n") {
+		matches := gitLogCommitRegex.FindStringSubmatch(line)
+		if matches != nil {
+			commit = &gitime.Commit{Hash: matches[1]}
+			commits = append(commits, commit)
+			inHeader = true
+			continue
+		}
+		if commit == nil {
+			commit = &gitime.Commit{}
+			commits = append(commits, commit)
+		}
+		if inHeader {
+			if readGitLogHeader(commit, line) {
+				continue
+			}
+			inHeader = false
+		}
+		commit.Message += line + "\n"
+	}
+
+	return commits
+}
+
+// readGitLogHeader reads the header line into the commit, and returns false when the line is not a header.
+func readGitLogHeader(commit *gitime.Commit, line string) bool {
+	key, value, found := strings.Cut(line, ":")
+	if !found || strings.HasPrefix(line, " ") || strings.TrimSpace(line) == "" {
+		return false
+	}
+	value = strings.TrimSpace(value)
+	switch key {
+	case "Author":
+		name, email, _ := strings.Cut(value, "<")
+		commit.AuthorName = strings.TrimSpace(name)
+		commit.AuthorEmail = strings.TrimSuffix(strings.TrimSpace(email), ">")
+	case "Date":
+		date, err := time.Parse(gitLogDateLayout, value)
+		if err == nil {
+			commit.Date = date
+		}
+	}
+
+	return true
+}
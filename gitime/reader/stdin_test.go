@@ -0,0 +1,41 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitGitLogOutput(t *testing.T) {
+	log := `commit 33ea1cbec32999178e79ce160e46f3530e7ae17f (HEAD -> main)
+Author: Alice <alice@example.com>
+Date:   Sat Mar 2 11:00:00 2024 +0100
+
+    feat: two
+
+    /spend 1d 30m
+
+commit 94e9359aaf3c1b4e4fb1e3d1f8de5fb5c1e6f2a0
+Merge: 1234567 89abcde
+Author: Bob <bob@example.com>
+Date:   Fri Mar 1 10:00:00 2024 +0100
+
+    feat: one
+`
+	commits := splitGitLogOutput(log)
+	assert.Len(t, commits, 2)
+	assert.Equal(t, "33ea1cbec32999178e79ce160e46f3530e7ae17f", commits[0].Hash)
+	assert.Equal(t, "Alice", commits[0].AuthorName)
+	assert.Equal(t, "alice@example.com", commits[0].AuthorEmail)
+	assert.Equal(t, 2024, commits[0].Date.Year())
+	assert.Contains(t, commits[0].Message, "    /spend 1d 30m")
+	assert.Equal(t, "feat: two", commits[0].Subject())
+	assert.Equal(t, "Bob", commits[1].AuthorName)
+	assert.Equal(t, "feat: one", commits[1].Subject())
+}
+
+func TestSplitGitLogOutputOfAnythingElse(t *testing.T) {
+	commits := splitGitLogOutput("feat: not a log\n\n/spend 1h\n")
+	assert.Len(t, commits, 1)
+	assert.Equal(t, "", commits[0].Hash)
+	assert.Equal(t, "feat: not a log\n\n/spend 1h\n\n", commits[0].Message)
+}
@@ -0,0 +1,131 @@
+package reader
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/tsuyoshiwada/go-gitlog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Stash is an entry of the stash, like stash@{0}
+type Stash struct {
+	Ref string
+	// Message is the message of the stash, without the "On <branch>: " git prefixes it with
+	Message string
+}
+
+// Unpushed holds the commits of a branch that are not reachable from its upstream,
+// or from any remote branch when it has no upstream
+type Unpushed struct {
+	Branch string
+	// Upstream is empty when the branch tracks no upstream, or when its upstream is gone
+	Upstream string
+	Commits  []*gitime.Commit
+}
+
+// ReadGitDirFile returns the content of a file of the git directory, like COMMIT_EDITMSG,
+// or an empty string if there is no such file
+func ReadGitDirFile(directory string, name string) string {
+	gitDir := ReadGitDir(directory)
+	if gitDir == "" {
+		return ""
+	}
+	content, err := os.ReadFile(filepath.Join(gitDir, name))
+	if err != nil {
+		return ""
+	}
+
+	return string(content)
+}
+
+// ReadStashes returns the entries of the stash, most recent first
+func ReadStashes(directory string) []*Stash {
+	list := exec.Command("git", "stash", "list", "--format=%gd%x1f%B%x1e")
+	list.Dir = directory
+	out, err := list.Output()
+	if err != nil {
+		return []*Stash{}
+	}
+
+	stashes := make([]*Stash, 0)
+	for _, record := range strings.Split(string(out), "\x1e") {
+		ref, message, found := strings.Cut(strings.TrimLeft(record, "\n"), "\x1f")
+		if !found {
+			continue
+		}
+		stashes = append(stashes, &Stash{Ref: ref, Message: trimStashPrefix(message)})
+	}
+
+	return stashes
+}
+
+// trimStashPrefix removes the "On <branch>: " or "WIP on <branch>: " prefix of the message of a stash,
+// so that a directive given to stash push -m starts its line
+func trimStashPrefix(message string) string {
+	if !strings.HasPrefix(message, "On ") && !strings.HasPrefix(message, "WIP on ") {
+		return message
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+	// Branch names cannot hold a colon, so the first one ends the prefix
+	_, rest, found := strings.Cut(subject, ": ")
+	if !found {
+		return message
+	}
+
+	return rest + message[len(subject):]
+}
+
+// ReadUnpushed returns, for each local branch, the commits of the branch that are not reachable from its upstream.
+// The commits of branches without an upstream, or whose upstream is gone, are the ones of no remote branch,
+// like git log <branch> --not --remotes.
+func ReadUnpushed(directory string) ([]*Unpushed, error) {
+	refs := exec.Command("git", "for-each-ref", "--format=%(refname:short)%09%(upstream:short)", "refs/heads")
+	refs.Dir = directory
+	out, err := refs.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	git := gitlog.New(&gitlog.Config{
+		Path: directory,
+	})
+	unpushed := make([]*Unpushed, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		branch, upstream, _ := strings.Cut(line, "\t")
+		if branch == "" {
+			continue
+		}
+		var commits []*gitlog.Commit
+		if upstream != "" {
+			commits, err = git.Log(&gitlog.RevRange{New: branch, Old: upstream}, nil)
+			if err != nil {
+				// The upstream may be gone, like after the remote branch was deleted
+				upstream = ""
+			}
+		}
+		if upstream == "" {
+			commits, err = git.Log(&revNotOnRemotes{Branch: branch}, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		entry := &Unpushed{Branch: branch, Upstream: upstream, Commits: make([]*gitime.Commit, 0, len(commits))}
+		for _, commit := range commits {
+			entry.Commits = append(entry.Commits, toCommit(commit, directory))
+		}
+		unpushed = append(unpushed, entry)
+	}
+
+	return unpushed, nil
+}
+
+// revNotOnRemotes is the RevArgs of the commits of a branch that are not on any remote branch
+type revNotOnRemotes struct {
+	Branch string
+}
+
+func (rev *revNotOnRemotes) Args() []string {
+	return []string{rev.Branch, "--not", "--remotes"}
+}
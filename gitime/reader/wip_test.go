@@ -0,0 +1,99 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newWipRepository(t *testing.T) (string, func(args ...string)) {
+	repository := filepath.Join(t.TempDir(), "wip")
+	git := func(args ...string) {
+		c := exec.Command("git", append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		c.Dir = repository
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--initial-branch=main", repository).Run())
+	git("commit", "--quiet", "--allow-empty", "-m", "init")
+
+	return repository, git
+}
+
+func TestReadStashes(t *testing.T) {
+	repository, git := newWipRepository(t)
+	assert.Empty(t, ReadStashes(repository))
+
+	stash := func(name string, args ...string) {
+		require.NoError(t, os.WriteFile(filepath.Join(repository, name), []byte(name), 0644))
+		git("add", name)
+		git(append([]string{"stash", "push", "--quiet"}, args...)...)
+	}
+	stash("a.txt")
+	stash("b.txt", "-m", "wip\n\n/spend 30m")
+	stash("c.txt", "-m", "/spend 1h")
+
+	stashes := ReadStashes(repository)
+	require.Len(t, stashes, 3)
+	assert.Equal(t, "stash@{0}", stashes[0].Ref)
+	assert.Equal(t, "/spend 1h", strings.TrimSpace(stashes[0].Message))
+	assert.Equal(t, "stash@{1}", stashes[1].Ref)
+	assert.Equal(t, "wip\n\n/spend 30m", strings.TrimSpace(stashes[1].Message))
+	assert.Equal(t, "stash@{2}", stashes[2].Ref)
+	assert.Regexp(t, "^[0-9a-f]+ init$", strings.TrimSpace(stashes[2].Message))
+}
+
+func TestTrimStashPrefix(t *testing.T) {
+	assert.Equal(t, "/spend 1h\n", trimStashPrefix("On main: /spend 1h\n"))
+	assert.Equal(t, "wip\n\n/spend 1h", trimStashPrefix("On feature/a: wip\n\n/spend 1h"))
+	assert.Equal(t, "1a2b3c4 init", trimStashPrefix("WIP on main: 1a2b3c4 init"))
+	assert.Equal(t, "a: b", trimStashPrefix("On (no branch): a: b"))
+	assert.Equal(t, "Once upon a time\n\nOn main: no", trimStashPrefix("Once upon a time\n\nOn main: no"))
+}
+
+func TestReadUnpushed(t *testing.T) {
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--bare", upstream).Run())
+	repository, git := newWipRepository(t)
+	git("remote", "add", "origin", upstream)
+	git("push", "--quiet", "--set-upstream", "origin", "main")
+	git("branch", "--quiet", "local")
+
+	unpushed, err := ReadUnpushed(repository)
+	require.NoError(t, err)
+	require.Len(t, unpushed, 2)
+	assert.Equal(t, "local", unpushed[0].Branch)
+	assert.Equal(t, "", unpushed[0].Upstream)
+	assert.Empty(t, unpushed[0].Commits, "the commits of branches without an upstream may be on other remote branches")
+	assert.Equal(t, "main", unpushed[1].Branch)
+	assert.Equal(t, "origin/main", unpushed[1].Upstream)
+	assert.Empty(t, unpushed[1].Commits)
+
+	git("commit", "--quiet", "--allow-empty", "-m", "feat: a\n\n/spend 2h")
+	git("checkout", "--quiet", "local")
+	git("commit", "--quiet", "--allow-empty", "-m", "feat: b")
+	git("checkout", "--quiet", "main")
+	unpushed, err = ReadUnpushed(repository)
+	require.NoError(t, err)
+	require.Len(t, unpushed, 2)
+	require.Len(t, unpushed[0].Commits, 1)
+	assert.Equal(t, "feat: b", unpushed[0].Commits[0].Subject())
+	require.Len(t, unpushed[1].Commits, 1)
+	assert.Equal(t, "feat: a", unpushed[1].Commits[0].Subject())
+
+	// A gone upstream is like no upstream
+	git("push", "--quiet", "origin", "--delete", "main")
+	git("fetch", "--quiet", "--prune")
+	unpushed, err = ReadUnpushed(repository)
+	require.NoError(t, err)
+	require.Len(t, unpushed, 2)
+	assert.Equal(t, "", unpushed[1].Upstream)
+	assert.Len(t, unpushed[1].Commits, 2)
+
+	_, err = ReadUnpushed(t.TempDir())
+	assert.Error(t, err)
+}
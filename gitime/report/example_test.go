@@ -0,0 +1,89 @@
+package report_test
+
+import (
+	"fmt"
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/gitime/report"
+	"time"
+)
+
+// results are the time spent by a few commits, like the Results of a gitime.Collection
+func results() []*gitime.Result {
+	commit := func(author string, day int) *gitime.Commit {
+		return &gitime.Commit{AuthorName: author, Date: time.Date(2024, 3, day, 10, 0, 0, 0, time.UTC)}
+	}
+
+	return []*gitime.Result{
+		{Commit: commit("Alice", 4), TimeSpent: &gitime.TimeSpent{Hours: 2, Minutes: 10}},
+		{Commit: commit("Bob", 4), TimeSpent: &gitime.TimeSpent{Minutes: 50}},
+		{Commit: commit("alice", 5), TimeSpent: &gitime.TimeSpent{Days: 1}},
+		{Commit: commit("Bob", 9), TimeSpent: &gitime.TimeSpent{Hours: 3}},
+	}
+}
+
+func Example() {
+	out, _ := report.New(results()).
+		GroupBy(report.Author).
+		Round(15 * time.Minute).
+		Unit(report.Hours).
+		Render(report.Markdown)
+	fmt.Print(string(out))
+	// Output:
+	// | author | hours |
+	// | --- | --- |
+	// | Alice | 10.2 |
+	// | Bob | 3.8 |
+	// | **total** | **14.0** |
+}
+
+// weekday groups the results by the day of the week they were committed on
+type weekday struct{}
+
+func (w weekday) Name() string {
+	return "weekday"
+}
+
+func (w weekday) Key(result *gitime.Result) string {
+	return result.Commit.Date.Weekday().String()
+}
+
+func (w weekday) Identity(key string) string {
+	return key
+}
+
+func Example_customGrouper() {
+	out, _ := report.New(results()).GroupBy(weekday{}).Render(report.Text)
+	fmt.Print(string(out))
+	// Output:
+	// weekday   time spent
+	// Tuesday   1 day
+	// Monday    3 hours
+	// Saturday  3 hours
+	// total     1 day 6 hours
+}
+
+// sentences renders each row of the report as a sentence
+type sentences struct{}
+
+func (s sentences) Render(r *report.Report) ([]byte, error) {
+	out := ""
+	for _, row := range r.Rows {
+		out += fmt.Sprintf(
+			"%s spent %s %s in %d commits.\n",
+			row.Key,
+			r.Formatter.Format(row.TimeSpent),
+			r.Formatter.Label(),
+			row.Commits,
+		)
+	}
+
+	return []byte(out), nil
+}
+
+func Example_customRenderer() {
+	out, _ := report.New(results()).GroupBy(report.Author).Unit(report.Minutes).Render(sentences{})
+	fmt.Print(string(out))
+	// Output:
+	// Alice spent 610 minutes in 2 commits.
+	// Bob spent 230 minutes in 2 commits.
+}
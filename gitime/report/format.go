@@ -0,0 +1,49 @@
+package report
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+)
+
+// Formatter writes the durations in the cells of a report
+type Formatter interface {
+	// Label is the title of the column of the time spent
+	Label() string
+	// Format writes the time spent of a row
+	Format(ts *gitime.TimeSpent) string
+	// FormatTotal writes the time spent of the total row
+	FormatTotal(ts *gitime.TimeSpent) string
+}
+
+// Unit is a Formatter writing durations as a single number in the unit, or as sentences
+type Unit string
+
+// Units of the durations of reports
+const (
+	// Sentence writes durations like "1 day 2 hours", under the current schedule
+	Sentence Unit = ""
+	Minutes  Unit = gitime.UnitMinutes
+	Hours    Unit = gitime.UnitHours
+	Days     Unit = gitime.UnitDays
+)
+
+func (u Unit) Label() string {
+	if u == Sentence {
+		return locale.T("GroupColumnTimeSpent")
+	}
+
+	return gitime.UnitLabel(string(u), 2.0)
+}
+
+func (u Unit) Format(ts *gitime.TimeSpent) string {
+	if u == Sentence {
+		return ts.Normalize().String()
+	}
+	value, _ := ts.Normalize().FormatInUnit(string(u))
+
+	return value
+}
+
+func (u Unit) FormatTotal(ts *gitime.TimeSpent) string {
+	return u.Format(ts)
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+	"strings"
+)
+
+// Grouper tells which group each result belongs to
+type Grouper interface {
+	// Name is the title of the column of the keys, like "author"
+	Name() string
+	// Key returns the key of the group of the result, as spelled in the result.  Empty keys are grouped together.
+	Key(result *gitime.Result) string
+	// Identity returns what the spellings of a key have in common, like the folded name of an author,
+	// so that they are the same group, under their most common spelling
+	Identity(key string) string
+}
+
+// grouper is a Grouper made of functions, which is enough for ours
+type grouper struct {
+	name     string
+	key      func(result *gitime.Result) string
+	identity func(key string) string
+}
+
+func (g *grouper) Name() string {
+	return g.name
+}
+
+func (g *grouper) Key(result *gitime.Result) string {
+	return g.key(result)
+}
+
+func (g *grouper) Identity(key string) string {
+	if g.identity == nil {
+		return key
+	}
+
+	return g.identity(key)
+}
+
+// Author groups the results by the name of the author of their commit, or else by their email.
+// Names differing only by their case (or accents, with gitime.FoldAccents) are the same author.
+var Author Grouper = &grouper{
+	name: locale.T("GroupColumnAuthor"),
+	key: func(result *gitime.Result) string {
+		if result.Commit.AuthorName != "" {
+			return result.Commit.AuthorName
+		}
+		return result.Commit.AuthorEmail
+	},
+	identity: gitime.FoldAuthorName,
+}
+
+// Repository groups the results by the name of their repository
+var Repository Grouper = &grouper{
+	name: locale.T("GroupColumnRepo"),
+	key: func(result *gitime.Result) string {
+		return result.Repository
+	},
+}
+
+// Issue groups the results by the first issue referenced by the message of their commit, in the grammar of the forge.
+// Commits referencing several issues are attributed to the first one, so that time is not counted twice.
+func Issue(forge *gitime.Forge) Grouper {
+	return &grouper{
+		name: locale.T("GroupColumnIssue"),
+		key: func(result *gitime.Result) string {
+			references := forge.References(result.Commit.Message)
+			if len(references) == 0 {
+				return ""
+			}
+			return references[0]
+		},
+	}
+}
+
+// Label groups the results by the label of their directives, as attributed by a collector grouping by label,
+// like with gitime.GroupByLabel.  Labels differing only by their case are the same.
+var Label Grouper = &grouper{
+	name:     locale.T("GroupColumnLabel"),
+	key:      (*gitime.Result).GroupKey,
+	identity: strings.ToLower,
+}
+
+// Collected groups the results by the key the collector attributed them, like with gitime.Collector.GroupBy,
+// under a column of that name
+func Collected(name string) Grouper {
+	return &grouper{
+		name: name,
+		key:  (*gitime.Result).GroupKey,
+	}
+}
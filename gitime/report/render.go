@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Renderer writes a report in some format
+type Renderer interface {
+	Render(report *Report) ([]byte, error)
+}
+
+// RendererFunc adapts a function to a Renderer
+type RendererFunc func(report *Report) ([]byte, error)
+
+func (f RendererFunc) Render(report *Report) ([]byte, error) {
+	return f(report)
+}
+
+// Text renders the report as columns aligned with spaces, for terminals
+var Text Renderer = RendererFunc(func(report *Report) ([]byte, error) {
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{report.Header()}, report.Cells()...) {
+		// The empty cells ending the total row would pad it with spaces
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	err := w.Flush()
+
+	return out.Bytes(), err
+})
+
+// CSV renders the report as comma-separated values, with a header row
+var CSV Renderer = RendererFunc(func(report *Report) ([]byte, error) {
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	err := w.Write(report.Header())
+	if err != nil {
+		return nil, err
+	}
+	err = w.WriteAll(report.Cells())
+
+	return out.Bytes(), err
+})
+
+// Markdown renders the report as a table, for merge requests and wikis.  The total row is in bold.
+var Markdown Renderer = RendererFunc(func(report *Report) ([]byte, error) {
+	var out bytes.Buffer
+	header := report.Header()
+	writeMarkdownRow(&out, header)
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeMarkdownRow(&out, separator)
+	cells := report.Cells()
+	for i, row := range cells {
+		if i == len(cells)-1 {
+			for j, cell := range row {
+				if cell != "" {
+					row[j] = "**" + cell + "**"
+				}
+			}
+		}
+		writeMarkdownRow(&out, row)
+	}
+
+	return out.Bytes(), nil
+})
+
+// writeMarkdownRow writes the cells as a row of a Markdown table, escaping their pipes
+func writeMarkdownRow(out *bytes.Buffer, cells []string) {
+	escaped := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		escaped = append(escaped, strings.ReplaceAll(cell, "|", "\\|"))
+	}
+	out.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+}
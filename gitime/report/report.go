@@ -0,0 +1,166 @@
+// Package report builds reports of the time spent, for the tools embedding git-spend :
+//
+//	out, err := report.New(collection.Results).
+//		GroupBy(report.Author).
+//		Round(15 * time.Minute).
+//		Unit(report.Hours).
+//		Render(report.Markdown)
+//
+// Groupers, rounders and renderers are interfaces, so that reports may group, round and render their own way.
+// The grouped outputs of git spend sum are built with it.
+package report
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/goutte/git-spend/locale"
+	"strconv"
+	"time"
+)
+
+// Builder configures a report, step by step, until it is built or rendered
+type Builder struct {
+	results   []*gitime.Result
+	grouper   Grouper
+	rounder   Rounder
+	formatter Formatter
+	withSpan  bool
+}
+
+// New starts a report of the results, like the Results of a gitime.Collection.
+// Unless told otherwise, the report is not grouped, not rounded, and writes durations as sentences.
+func New(results []*gitime.Result) *Builder {
+	return &Builder{
+		results:   results,
+		formatter: Sentence,
+	}
+}
+
+// GroupBy groups the results by the keys of the grouper, in rows sorted by decreasing time spent
+func (b *Builder) GroupBy(grouper Grouper) *Builder {
+	b.grouper = grouper
+
+	return b
+}
+
+// Round rounds the time spent of each row to the nearest multiple of the step
+func (b *Builder) Round(step time.Duration) *Builder {
+	return b.RoundWith(Nearest(step))
+}
+
+// RoundWith rounds the time spent of each row with the rounder
+func (b *Builder) RoundWith(rounder Rounder) *Builder {
+	b.rounder = rounder
+
+	return b
+}
+
+// Unit writes the durations as a single number in the unit, or as sentences with Sentence
+func (b *Builder) Unit(unit Unit) *Builder {
+	return b.FormatWith(unit)
+}
+
+// FormatWith writes the durations with the formatter
+func (b *Builder) FormatWith(formatter Formatter) *Builder {
+	b.formatter = formatter
+
+	return b
+}
+
+// WithSpan adds the date of the first and last activity of each row, and how many days they span
+func (b *Builder) WithSpan() *Builder {
+	b.withSpan = true
+
+	return b
+}
+
+// Build groups and rounds the results
+func (b *Builder) Build() *Report {
+	report := &Report{
+		Rows:      make([]*gitime.Group, 0),
+		Total:     &gitime.TimeSpent{},
+		WithSpan:  b.withSpan,
+		Formatter: b.formatter,
+	}
+	if b.grouper == nil {
+		for _, result := range b.results {
+			report.Total.Add(result.TimeSpent)
+		}
+		if b.rounder != nil {
+			report.Total = b.rounder.Round(report.Total)
+		}
+		return report
+	}
+
+	report.Grouping = b.grouper.Name()
+	for _, group := range gitime.GroupResults(b.results, b.grouper.Key, b.grouper.Identity) {
+		row := *group
+		if b.rounder != nil {
+			row.TimeSpent = b.rounder.Round(row.TimeSpent)
+		}
+		report.Rows = append(report.Rows, &row)
+		report.Total.Add(row.TimeSpent)
+	}
+
+	return report
+}
+
+// Render builds the report and renders it
+func (b *Builder) Render(renderer Renderer) ([]byte, error) {
+	return renderer.Render(b.Build())
+}
+
+// Report is the time spent per group, and in total, ready to be rendered
+type Report struct {
+	// Grouping is the name of what the rows are grouped by, or empty when the report is not grouped
+	Grouping string
+	// Rows are the groups, sorted by decreasing time spent
+	Rows []*gitime.Group
+	// Total is the sum of the rows, or of the results when the report is not grouped
+	Total *gitime.TimeSpent
+	// WithSpan tells whether the rows should show the dates of their first and last activity
+	WithSpan  bool
+	Formatter Formatter
+}
+
+// Header returns the titles of the columns of the cells
+func (r *Report) Header() []string {
+	header := []string{r.Grouping, r.Formatter.Label()}
+	if r.WithSpan {
+		header = append(
+			header,
+			locale.T("GroupColumnFirst"),
+			locale.T("GroupColumnLast"),
+			locale.T("GroupColumnSpan"),
+		)
+	}
+
+	return header
+}
+
+// Cells returns a row of cells per group, plus the total row whose span cells are empty
+func (r *Report) Cells() [][]string {
+	cells := make([][]string, 0, len(r.Rows)+1)
+	for _, row := range r.Rows {
+		key := row.Key
+		if key == "" {
+			key = locale.T("GroupUnknown")
+		}
+		line := []string{key, r.Formatter.Format(row.TimeSpent)}
+		if r.WithSpan {
+			line = append(
+				line,
+				row.First.Format(time.DateOnly),
+				row.Last.Format(time.DateOnly),
+				strconv.Itoa(row.SpanDays()),
+			)
+		}
+		cells = append(cells, line)
+	}
+
+	total := []string{locale.T("GroupTotal"), r.Formatter.FormatTotal(r.Total)}
+	if r.WithSpan {
+		total = append(total, "", "", "")
+	}
+
+	return append(cells, total)
+}
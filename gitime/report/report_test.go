@@ -0,0 +1,83 @@
+package report
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newResult(author string, repository string, ts *gitime.TimeSpent) *gitime.Result {
+	return &gitime.Result{
+		Commit:     &gitime.Commit{AuthorName: author, Date: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		Repository: repository,
+		TimeSpent:  ts,
+	}
+}
+
+func TestBuildGroupsAndRounds(t *testing.T) {
+	results := []*gitime.Result{
+		newResult("Alice", "api", &gitime.TimeSpent{Minutes: 50}),
+		newResult("Bob", "web", &gitime.TimeSpent{Minutes: 20}),
+		newResult("alice", "web", &gitime.TimeSpent{Minutes: 5}),
+	}
+
+	built := New(results).GroupBy(Author).Build()
+	require.Len(t, built.Rows, 2)
+	assert.Equal(t, "Alice", built.Rows[0].Key)
+	assert.Equal(t, 2, built.Rows[0].Commits)
+	assert.Equal(t, uint64(75), built.Total.ToMinutes())
+
+	built = New(results).GroupBy(Repository).RoundWith(Up(time.Hour)).Build()
+	require.Len(t, built.Rows, 2)
+	assert.Equal(t, uint64(60), built.Rows[0].TimeSpent.ToMinutes())
+	assert.Equal(t, uint64(60), built.Rows[1].TimeSpent.ToMinutes())
+	assert.Equal(t, uint64(120), built.Total.ToMinutes(), "the total is the sum of the rounded rows")
+
+	built = New(results).Round(30 * time.Minute).Build()
+	assert.Len(t, built.Rows, 0)
+	assert.Equal(t, uint64(90), built.Total.ToMinutes())
+}
+
+func TestRounders(t *testing.T) {
+	ts := &gitime.TimeSpent{Minutes: 22}
+	assert.Equal(t, uint64(15), Nearest(15*time.Minute).Round(ts).ToMinutes())
+	assert.Equal(t, uint64(30), Up(15*time.Minute).Round(ts).ToMinutes())
+	assert.Equal(t, uint64(15), Down(15*time.Minute).Round(ts).ToMinutes())
+	assert.Equal(t, uint64(22), Nearest(0).Round(ts).ToMinutes())
+}
+
+func TestRenderers(t *testing.T) {
+	results := []*gitime.Result{
+		newResult("Alice", "", &gitime.TimeSpent{Hours: 2}),
+		newResult("", "", &gitime.TimeSpent{Minutes: 30}),
+	}
+	builder := New(results).GroupBy(Author).WithSpan()
+
+	out, err := builder.Render(Text)
+	require.NoError(t, err)
+	assert.Equal(t, `author     time spent  first       last        span (days)
+Alice      2 hours     2024-03-04  2024-03-04  1
+(unknown)  30 minutes  2024-03-04  2024-03-04  1
+total      2 hours 30 minutes
+`, string(out))
+
+	out, err = builder.Unit(Minutes).Render(CSV)
+	require.NoError(t, err)
+	assert.Equal(t, `author,minutes,first,last,span (days)
+Alice,120,2024-03-04,2024-03-04,1
+(unknown),30,2024-03-04,2024-03-04,1
+total,150,,,
+`, string(out))
+
+	results[0].Commit.AuthorName = "Alice | Bob"
+	out, err = New(results).GroupBy(Author).Render(Markdown)
+	require.NoError(t, err)
+	assert.Equal(t, `| author | time spent |
+| --- | --- |
+| Alice \| Bob | 2 hours |
+| (unknown) | 30 minutes |
+| **total** | **2 hours 30 minutes** |
+`, string(out))
+}
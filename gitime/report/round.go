@@ -0,0 +1,43 @@
+package report
+
+import (
+	"github.com/goutte/git-spend/gitime"
+	"math"
+	"time"
+)
+
+// Rounder rounds the time spent of the rows of a report, like to the quarter hour for invoices
+type Rounder interface {
+	Round(ts *gitime.TimeSpent) *gitime.TimeSpent
+}
+
+// stepRounder rounds the minutes of the time spent to a multiple of the step, with the rounding function
+type stepRounder struct {
+	step  time.Duration
+	round func(float64) float64
+}
+
+func (r *stepRounder) Round(ts *gitime.TimeSpent) *gitime.TimeSpent {
+	step := r.step.Minutes()
+	if step <= 0 {
+		return ts
+	}
+	minutes := r.round(float64(ts.ToMinutes())/step) * step
+
+	return (&gitime.TimeSpent{Minutes: minutes}).Normalize()
+}
+
+// Nearest rounds to the nearest multiple of the step, halves up
+func Nearest(step time.Duration) Rounder {
+	return &stepRounder{step: step, round: math.Round}
+}
+
+// Up rounds up to a multiple of the step, like timesheets billing any started quarter hour
+func Up(step time.Duration) Rounder {
+	return &stepRounder{step: step, round: math.Ceil}
+}
+
+// Down rounds down to a multiple of the step
+func Down(step time.Duration) Rounder {
+	return &stepRounder{step: step, round: math.Floor}
+}
@@ -0,0 +1,100 @@
+package gitime
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+)
+
+// Schedule is the time modulo configuration used to convert durations into minutes
+type Schedule struct {
+	MinutesInOneHour float64
+	HoursInOneDay    float64
+	DaysInOneWeek    float64
+	WeeksInOneMonth  float64
+}
+
+// scheduleKeys are the config keys of each setting of a schedule, the first one being canonical
+var scheduleKeys = [][]string{
+	{"minutes_per_hour", "minutes_in_one_hour"},
+	{"hours_per_day", "hours_in_one_day"},
+	{"days_per_week", "days_in_one_week"},
+	{"weeks_per_month", "weeks_in_one_month"},
+}
+
+// ScheduleConfigKeys returns all the config keys of the settings of a schedule, canonical or not
+func ScheduleConfigKeys() []string {
+	keys := make([]string, 0, 2*len(scheduleKeys))
+	for _, setting := range scheduleKeys {
+		keys = append(keys, setting...)
+	}
+
+	return keys
+}
+
+// CurrentSchedule returns the schedule currently used by the conversions
+func CurrentSchedule() Schedule {
+	return Schedule{
+		MinutesInOneHour: MinutesInOneHour,
+		HoursInOneDay:    HoursInOneDay,
+		DaysInOneWeek:    DaysInOneWeek,
+		WeeksInOneMonth:  WeeksInOneMonth,
+	}
+}
+
+// UseSchedule makes the conversions use the schedule, until another one is used
+func UseSchedule(schedule Schedule) {
+	MinutesInOneHour = schedule.MinutesInOneHour
+	HoursInOneDay = schedule.HoursInOneDay
+	DaysInOneWeek = schedule.DaysInOneWeek
+	WeeksInOneMonth = schedule.WeeksInOneMonth
+	refreshCompoundConversions()
+}
+
+// ScheduleFromConfig returns the fallback schedule, overridden by the settings of the config.
+// Settings given through the environment keep precedence over the config.
+func ScheduleFromConfig(config *viper.Viper, fallback Schedule) Schedule {
+	schedule := fallback
+	settings := schedule.settings()
+	for i, keys := range scheduleKeys {
+		if isSetInEnvironment(keys) {
+			continue
+		}
+		for _, key := range keys {
+			if config.IsSet(key) {
+				*settings[i] = config.GetFloat64(key)
+				break
+			}
+		}
+	}
+
+	return schedule
+}
+
+// Differences lists the settings on which both schedules disagree, like "hours_per_day: 7 ≠ 8"
+func (s Schedule) Differences(other Schedule) []string {
+	differences := make([]string, 0)
+	mine, theirs := s.settings(), other.settings()
+	for i, keys := range scheduleKeys {
+		if *mine[i] != *theirs[i] {
+			differences = append(differences, fmt.Sprintf("%s: %g ≠ %g", keys[0], *mine[i], *theirs[i]))
+		}
+	}
+
+	return differences
+}
+
+func (s *Schedule) settings() []*float64 {
+	return []*float64{&s.MinutesInOneHour, &s.HoursInOneDay, &s.DaysInOneWeek, &s.WeeksInOneMonth}
+}
+
+func isSetInEnvironment(keys []string) bool {
+	for _, key := range keys {
+		if _, found := os.LookupEnv("GIT_SPEND_" + strings.ToUpper(key)); found {
+			return true
+		}
+	}
+
+	return false
+}
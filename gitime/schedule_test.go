@@ -0,0 +1,61 @@
+package gitime
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScheduleFromConfig(t *testing.T) {
+	config := viper.New()
+	config.Set("hours_in_one_day", 7)
+	config.Set("days_per_week", 4)
+
+	schedule := ScheduleFromConfig(config, CurrentSchedule())
+	assert.Equal(t, 60.0, schedule.MinutesInOneHour)
+	assert.Equal(t, 7.0, schedule.HoursInOneDay)
+	assert.Equal(t, 4.0, schedule.DaysInOneWeek)
+	assert.Equal(t, 4.0, schedule.WeeksInOneMonth)
+}
+
+func TestScheduleFromConfigPrefersEnvironment(t *testing.T) {
+	t.Setenv("GIT_SPEND_HOURS_PER_DAY", "6")
+	config := viper.New()
+	config.Set("hours_per_day", 7)
+
+	schedule := ScheduleFromConfig(config, CurrentSchedule())
+	assert.Equal(t, DefaultHoursInOneDay, schedule.HoursInOneDay)
+}
+
+func TestSchedule_Differences(t *testing.T) {
+	a := CurrentSchedule()
+	b := CurrentSchedule()
+	assert.Empty(t, a.Differences(b))
+
+	b.HoursInOneDay = 7
+	assert.Equal(t, []string{"hours_per_day: 8 ≠ 7"}, a.Differences(b))
+}
+
+func TestCollection_MergeInMinutes(t *testing.T) {
+	defer UseSchedule(CurrentSchedule())
+	commits := []*Commit{{AuthorName: "Alice", Message: "/spend 1d"}}
+	collector := &Collector{GroupBy: GroupByAuthor}
+
+	seven := CurrentSchedule()
+	seven.HoursInOneDay = 7
+	UseSchedule(seven)
+	total := collector.Collect(commits).InMinutes()
+
+	UseSchedule(Schedule{
+		MinutesInOneHour: DefaultMinutesInOneHour,
+		HoursInOneDay:    DefaultHoursInOneDay,
+		DaysInOneWeek:    DefaultDaysInOneWeek,
+		WeeksInOneMonth:  DefaultWeeksInOneMonth,
+	})
+	total.Merge(collector.Collect(commits).InMinutes())
+
+	assert.Equal(t, uint64(7*60+8*60), total.TimeSpent.ToMinutes())
+	assert.Len(t, total.Groups, 1)
+	assert.Equal(t, 2, total.Groups[0].Commits)
+	assert.Equal(t, uint64(7*60+8*60), total.Groups[0].TimeSpent.ToMinutes())
+}
@@ -0,0 +1,86 @@
+package gitime
+
+import (
+	"sort"
+	"time"
+)
+
+// Session is a work session reconstructed from the time spent in a commit, for clients who want start and end times.
+// Sessions are synthetic : commits only say how long, and when the work was committed.
+type Session struct {
+	AuthorName string
+	// Commit is the commit holding the time spent of the session, whose subject describes it
+	Commit  *Commit
+	Start   time.Time
+	End     time.Time
+	Minutes uint64
+	// Unresolved tells that the day of the session logs more time than a day holds, so its sessions cannot all fit
+	Unresolved bool
+}
+
+// ReconstructSessions lays the time spent in the commits back-to-back, per author and per day of their BucketDate.
+// Each session ends when its commit was made, unless the next session of the day already started,
+// in which case it is shifted earlier so that sessions never overlap, and may start the day before.
+// The sessions of a day logging more than 24 hours are flagged as unresolved, but never squeezed.
+// The corrections are applied, and commits without time spent have no session.
+func ReconstructSessions(commits []*Commit, corrections []*Correction) []*Session {
+	days := make(map[string][]*Session)
+	keys := make([]string, 0)
+	for _, commit := range commits {
+		ts := CollectTimeSpent(commit.Message)
+		if correction := findCorrection(corrections, commit.Hash); correction != nil {
+			ts = correction.Apply(ts)
+		}
+		minutes := ts.ToMinutes()
+		if minutes == 0 {
+			continue
+		}
+		author := commit.AuthorName
+		if author == "" {
+			author = commit.AuthorEmail
+		}
+		key := FoldAuthorName(author) + "\x00" + commit.BucketDate().Format(time.DateOnly)
+		if _, exists := days[key]; !exists {
+			keys = append(keys, key)
+		}
+		days[key] = append(days[key], &Session{
+			AuthorName: author,
+			Commit:     commit,
+			End:        commit.Date,
+			Minutes:    minutes,
+		})
+	}
+
+	sessions := make([]*Session, 0)
+	for _, key := range keys {
+		sessions = append(sessions, layDay(days[key])...)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Start.Before(sessions[j].Start)
+	})
+
+	return sessions
+}
+
+// layDay sets the start of the sessions of a single day of a single author, from the latest one backwards
+func layDay(sessions []*Session) []*Session {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].End.Before(sessions[j].End)
+	})
+	total := uint64(0)
+	for i := len(sessions) - 1; i >= 0; i-- {
+		session := sessions[i]
+		if i < len(sessions)-1 && sessions[i+1].Start.Before(session.End) {
+			session.End = sessions[i+1].Start
+		}
+		session.Start = session.End.Add(-time.Duration(session.Minutes) * time.Minute)
+		total += session.Minutes
+	}
+	if total > 24*60 {
+		for _, session := range sessions {
+			session.Unresolved = true
+		}
+	}
+
+	return sessions
+}
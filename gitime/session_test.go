@@ -0,0 +1,105 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func at(hour int, minute int) time.Time {
+	return time.Date(2024, 3, 25, hour, minute, 0, 0, time.UTC)
+}
+
+func TestReconstructSessions(t *testing.T) {
+	sessions := ReconstructSessions([]*Commit{
+		{Hash: "c", AuthorName: "Alice", Date: at(15, 0), Message: "feat: c\n\n/spend 1h"},
+		{Hash: "a", AuthorName: "Alice", Date: at(10, 0), Message: "feat: a\n\n/spend 30m"},
+		{Hash: "b", AuthorName: "Alice", Date: at(14, 30), Message: "feat: b\n\n/spend 2h"},
+		{Hash: "n", AuthorName: "Alice", Date: at(16, 0), Message: "chore: nothing spent"},
+		{Hash: "d", AuthorName: "Bob", Date: at(14, 45), Message: "feat: d\n\n/spend 15m"},
+	}, nil)
+
+	expected := []struct {
+		hash  string
+		start time.Time
+		end   time.Time
+	}{
+		{"a", at(9, 30), at(10, 0)},
+		// Shifted earlier, so that it ends when the next session starts
+		{"b", at(12, 0), at(14, 0)},
+		{"d", at(14, 30), at(14, 45)},
+		{"c", at(14, 0), at(15, 0)},
+	}
+	if assert.Len(t, sessions, len(expected)) {
+		byHash := map[string]*Session{}
+		for _, session := range sessions {
+			byHash[session.Commit.Hash] = session
+			assert.False(t, session.Unresolved)
+		}
+		for _, e := range expected {
+			assert.Equal(t, e.start, byHash[e.hash].Start, e.hash)
+			assert.Equal(t, e.end, byHash[e.hash].End, e.hash)
+		}
+		assert.Equal(t, "a", sessions[0].Commit.Hash, "sessions are sorted by start")
+		assert.Equal(t, "feat: b", byHash["b"].Commit.Subject())
+	}
+}
+
+func TestReconstructSessionsStartingTheDayBefore(t *testing.T) {
+	sessions := ReconstructSessions([]*Commit{
+		{Hash: "a", AuthorName: "Alice", Date: at(1, 0), Message: "/spend 2h"},
+		{Hash: "b", AuthorName: "Alice", Date: at(3, 0), Message: "/spend 2h"},
+	}, nil)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, at(0, 0).Add(-time.Hour), sessions[0].Start)
+		assert.False(t, sessions[0].Unresolved, "a day of 4 hours fits, even if it starts the day before")
+		assert.False(t, sessions[1].Unresolved)
+	}
+}
+
+func TestReconstructSessionsUnresolved(t *testing.T) {
+	sessions := ReconstructSessions([]*Commit{
+		{Hash: "a", AuthorName: "Alice", Date: at(2, 0), Message: "/spend 90m"},
+		{Hash: "b", AuthorName: "Alice", Date: at(23, 0), Message: "/spend 23h"},
+		{Hash: "c", AuthorName: "Bob", Date: at(23, 0), Message: "/spend 1h"},
+	}, nil)
+	if assert.Len(t, sessions, 3) {
+		assert.Equal(t, "a", sessions[0].Commit.Hash)
+		assert.True(t, sessions[0].Unresolved)
+		assert.Equal(t, at(0, 0).Add(-90*time.Minute), sessions[0].Start, "not squeezed")
+		assert.True(t, sessions[1].Unresolved, "all the sessions of a day over 24 hours are unresolved")
+		assert.False(t, sessions[2].Unresolved)
+	}
+}
+
+func TestReconstructSessionsInTheConfiguredTimezone(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	location := Location
+	Location = paris
+	t.Cleanup(func() { Location = location })
+
+	// Both are committed on March 26 in Paris, but a is committed on March 25 in UTC
+	sessions := ReconstructSessions([]*Commit{
+		{Hash: "a", AuthorName: "Alice", Date: at(23, 30), Message: "/spend 1h"},
+		{Hash: "b", AuthorName: "Alice", Date: at(23, 30).Add(45 * time.Minute), Message: "/spend 1h"},
+	}, nil)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, "a", sessions[0].Commit.Hash)
+		assert.Equal(t, at(23, 15), sessions[0].End, "laid in the same day as b, so that they do not overlap")
+		assert.Equal(t, at(22, 15), sessions[0].Start)
+	}
+}
+
+func TestReconstructSessionsCorrected(t *testing.T) {
+	corrections, err := ParseCorrections(map[string]string{"abcdef0": "45m"})
+	assert.NoError(t, err)
+	sessions := ReconstructSessions([]*Commit{
+		{Hash: "abcdef0123", AuthorName: "Alice", Date: at(10, 0), Message: "/spend 3d"},
+	}, corrections)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, uint64(45), sessions[0].Minutes)
+		assert.Equal(t, at(9, 15), sessions[0].Start)
+	}
+}
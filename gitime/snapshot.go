@@ -0,0 +1,180 @@
+package gitime
+
+import "time"
+
+// SnapshotSchemaVersion is bumped whenever the JSON of snapshots changes in a backward-incompatible way
+const SnapshotSchemaVersion = 1
+
+// LedgerEntry is the time spent in a single commit
+type LedgerEntry struct {
+	Hash   string `json:"hash"`
+	Author string `json:"author"`
+	// AuthorEmail is the email of the author, so that identities may be migrated by domain.
+	// Snapshots written before it was recorded do not have it.
+	AuthorEmail string    `json:"author_email,omitempty"`
+	Date        time.Time `json:"date"`
+	Minutes     uint64    `json:"minutes"`
+	// Corrected is true when the minutes were corrected, see Correction
+	Corrected       bool   `json:"corrected,omitempty"`
+	OriginalMinutes uint64 `json:"original_minutes,omitempty"`
+	// Ranges are the directives of the commit that spent ranges of time, and were collapsed
+	Ranges []*LedgerRange `json:"ranges,omitempty"`
+}
+
+// LedgerRange is a range of time spent by a directive, like "/spend 1-2h", and how it was collapsed
+type LedgerRange struct {
+	Low    uint64 `json:"low_minutes"`
+	High   uint64 `json:"high_minutes"`
+	Policy string `json:"policy"`
+}
+
+// SnapshotFilters are the filters used to read the commits of a snapshot, so that they may be used again
+type SnapshotFilters struct {
+	Authors  []string `json:"authors"`
+	NoMerges bool     `json:"no_merges"`
+	// Since and Until are resolved when the snapshot is written, into dates with their offset or full hashes,
+	// so that relative bounds like HEAD~3 read the same commits when verified
+	Since string `json:"since"`
+	Until string `json:"until"`
+	// ExcludedAuthors are the authors whose commits were skipped, like the members of the team with --not-in-team
+	ExcludedAuthors []string `json:"excluded_authors,omitempty"`
+	// FoldAccents and CaseSensitiveEmails are how the authors were matched
+	FoldAccents         bool `json:"fold_accents,omitempty"`
+	CaseSensitiveEmails bool `json:"case_sensitive_emails,omitempty"`
+	// WordNumbers is the language of the number words read in directives, if any
+	WordNumbers string `json:"word_numbers,omitempty"`
+	// RangePolicy is how ranges were collapsed, if not RangeMidpoint
+	RangePolicy string `json:"range_policy,omitempty"`
+	// AmbiguousM is how the bare "m" unit was read, if not AmbiguousMinutes
+	AmbiguousM string `json:"ambiguous_m,omitempty"`
+	// Order is the order the commits were listed in, like OrderTopo, so that checks list them the same way
+	Order string `json:"order,omitempty"`
+}
+
+// Snapshot freezes the time spent in each commit, so that we may later detect whether history was rewritten
+type Snapshot struct {
+	SchemaVersion int             `json:"schema_version"`
+	TakenAt       time.Time       `json:"taken_at"`
+	Head          string          `json:"head"`
+	Filters       SnapshotFilters `json:"filters"`
+	Minutes       uint64          `json:"minutes"`
+	Ledger        []*LedgerEntry  `json:"ledger"`
+	// Hashes are the hashes of all the commits read, including the ones that spent no time.
+	// Snapshots written before they were recorded do not have them.
+	Hashes []string `json:"hashes,omitempty"`
+}
+
+// Kinds of drift between a snapshot and the current history
+const (
+	DriftChanged     = "changed"
+	DriftDisappeared = "disappeared"
+	DriftAppeared    = "appeared"
+)
+
+// Drift is a commit whose time spent is not the same as in the snapshot
+type Drift struct {
+	Kind string `json:"kind"`
+	// Before is the entry of the snapshot, or nil if the commit appeared
+	Before *LedgerEntry `json:"before"`
+	// After is the entry of the current history, or nil if the commit disappeared
+	After *LedgerEntry `json:"after"`
+}
+
+// NewLedger returns the entries of the commits that spend some time, or whose time spent was corrected
+func NewLedger(commits []*Commit, corrections []*Correction) []*LedgerEntry {
+	ledger := make([]*LedgerEntry, 0)
+	for _, commit := range commits {
+		ts := &TimeSpent{}
+		ranges := make([]*LedgerRange, 0)
+		for _, directive := range CollectDirectives(commit.Message) {
+			ts.Add(directive.TimeSpent)
+			if directive.Range != nil {
+				ranges = append(ranges, &LedgerRange{
+					Low:    directive.Range.Low.ToMinutes(),
+					High:   directive.Range.High.ToMinutes(),
+					Policy: directive.Range.Policy,
+				})
+			}
+		}
+		correction := findCorrection(corrections, commit.Hash)
+		if ts.IsZero() && correction == nil {
+			continue
+		}
+		author := commit.AuthorName
+		if author == "" {
+			author = commit.AuthorEmail
+		}
+		entry := &LedgerEntry{
+			Hash:        commit.Hash,
+			Author:      author,
+			AuthorEmail: commit.AuthorEmail,
+			Date:        commit.Date,
+			Minutes:     ts.ToMinutes(),
+		}
+		if len(ranges) > 0 {
+			entry.Ranges = ranges
+		}
+		if correction != nil {
+			entry.Corrected = true
+			entry.OriginalMinutes = entry.Minutes
+			entry.Minutes = correction.Apply(ts).ToMinutes()
+		}
+		ledger = append(ledger, entry)
+	}
+
+	return ledger
+}
+
+// NewSnapshot returns a snapshot of the ledger of the commits of the hashes, taken now
+func NewSnapshot(head string, filters SnapshotFilters, ledger []*LedgerEntry, hashes []string, now time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		SchemaVersion: SnapshotSchemaVersion,
+		TakenAt:       now,
+		Head:          head,
+		Filters:       filters,
+		Ledger:        ledger,
+		Hashes:        hashes,
+	}
+	for _, entry := range ledger {
+		snapshot.Minutes += entry.Minutes
+	}
+
+	return snapshot
+}
+
+// Verify compares the snapshot with the current ledger, and returns the drifts, if any.
+// A commit missing from the snapshot only appeared if it was already read, or reachable from the Head
+// of the snapshot, as told by isReachable.  Other commits are new work, whatever their date, and not a drift.
+func (s *Snapshot) Verify(ledger []*LedgerEntry, isReachable func(hash string) bool) []*Drift {
+	drifts := make([]*Drift, 0)
+	current := make(map[string]*LedgerEntry)
+	for _, entry := range ledger {
+		current[entry.Hash] = entry
+	}
+	read := make(map[string]bool)
+	for _, hash := range s.Hashes {
+		read[hash] = true
+	}
+
+	for _, before := range s.Ledger {
+		after, found := current[before.Hash]
+		if !found {
+			drifts = append(drifts, &Drift{Kind: DriftDisappeared, Before: before})
+			continue
+		}
+		if after.Minutes != before.Minutes {
+			drifts = append(drifts, &Drift{Kind: DriftChanged, Before: before, After: after})
+		}
+		delete(current, before.Hash)
+	}
+	for _, after := range ledger {
+		if _, found := current[after.Hash]; !found {
+			continue
+		}
+		if read[after.Hash] || isReachable(after.Hash) {
+			drifts = append(drifts, &Drift{Kind: DriftAppeared, After: after})
+		}
+	}
+
+	return drifts
+}
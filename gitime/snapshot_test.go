@@ -0,0 +1,74 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewLedger(t *testing.T) {
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	ledger := NewLedger([]*Commit{
+		{Hash: "aaa", AuthorName: "Alice", Date: date, Message: "/spend 1h"},
+		{Hash: "bbb", AuthorName: "Bob", Date: date, Message: "no time"},
+		{Hash: "ccc", AuthorEmail: "eve@example.com", Date: date, Message: "/spend 2h"},
+	}, nil)
+	require.Len(t, ledger, 2)
+	assert.Equal(t, &LedgerEntry{Hash: "aaa", Author: "Alice", Date: date, Minutes: 60}, ledger[0])
+	assert.Equal(t, "eve@example.com", ledger[1].Author)
+	assert.Equal(t, "eve@example.com", ledger[1].AuthorEmail)
+
+	snapshot := NewSnapshot("ccc", SnapshotFilters{}, ledger, []string{"aaa", "bbb", "ccc"}, date)
+	assert.Equal(t, SnapshotSchemaVersion, snapshot.SchemaVersion)
+	assert.Equal(t, uint64(180), snapshot.Minutes)
+}
+
+func TestNewLedgerWithCorrections(t *testing.T) {
+	correction, err := ParseCorrection("bbb0123", "+30m")
+	require.NoError(t, err)
+	ledger := NewLedger([]*Commit{
+		{Hash: "aaa", Message: "/spend 1h"},
+		{Hash: "bbb0123", Message: "no time"},
+	}, []*Correction{correction})
+	require.Len(t, ledger, 2)
+	assert.False(t, ledger[0].Corrected)
+	assert.True(t, ledger[1].Corrected)
+	assert.Equal(t, uint64(0), ledger[1].OriginalMinutes)
+	assert.Equal(t, uint64(30), ledger[1].Minutes)
+}
+
+func TestSnapshot_Verify(t *testing.T) {
+	takenAt := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	before := takenAt.Add(-time.Hour)
+	snapshot := NewSnapshot("head", SnapshotFilters{}, []*LedgerEntry{
+		{Hash: "same", Date: before, Minutes: 60},
+		{Hash: "changed", Date: before, Minutes: 60},
+		{Hash: "gone", Date: before, Minutes: 60},
+	}, []string{"same", "changed", "gone", "noted"}, takenAt)
+	// Like the commits of the history of the head of the snapshot, that were not in its range
+	reachable := map[string]bool{"rebased": true}
+	isReachable := func(hash string) bool {
+		return reachable[hash]
+	}
+
+	assert.Empty(t, snapshot.Verify(snapshot.Ledger, isReachable))
+
+	drifts := snapshot.Verify([]*LedgerEntry{
+		{Hash: "same", Date: before, Minutes: 60},
+		{Hash: "changed", Date: before, Minutes: 90},
+		{Hash: "noted", Date: before, Minutes: 30},
+		{Hash: "rebased", Date: takenAt.Add(time.Hour), Minutes: 30},
+		{Hash: "backdated", Date: before, Minutes: 30},
+		{Hash: "later", Date: takenAt.Add(time.Hour), Minutes: 30},
+	}, isReachable)
+	require.Len(t, drifts, 4)
+	assert.Equal(t, DriftChanged, drifts[0].Kind)
+	assert.Equal(t, uint64(90), drifts[0].After.Minutes)
+	assert.Equal(t, DriftDisappeared, drifts[1].Kind)
+	assert.Equal(t, "gone", drifts[1].Before.Hash)
+	assert.Equal(t, DriftAppeared, drifts[2].Kind)
+	assert.Equal(t, "noted", drifts[2].After.Hash, "commits read that spent no time appeared")
+	assert.Equal(t, DriftAppeared, drifts[3].Kind)
+	assert.Equal(t, "rebased", drifts[3].After.Hash, "commits reachable from the head appeared, whatever their date")
+}
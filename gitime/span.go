@@ -0,0 +1,75 @@
+package gitime
+
+import "regexp"
+
+// Span is a range of bytes of a message, from Start (included) to End (excluded)
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Of returns the text of the message covered by the span
+func (s Span) Of(message string) string {
+	return message[s.Start:s.End]
+}
+
+// shift returns the span moved forward by offset bytes
+func (s Span) shift(offset int) Span {
+	return Span{Start: s.Start + offset, End: s.End + offset}
+}
+
+// Spans locates the parts of a directive within the original message, as byte offsets,
+// so that editors may highlight exactly what was understood.
+type Spans struct {
+	// Directive covers the whole (trimmed) line of the directive
+	Directive Span `json:"directive"`
+	// Keyword covers /spend or /spent
+	Keyword Span `json:"keyword"`
+	// Tokens cover each value and its unit, like "1h" and "30" in "/spend 1h30"
+	Tokens []Span `json:"tokens"`
+	// Date covers the optional date suffix, or is nil
+	Date *Span `json:"date,omitempty"`
+	// Label covers the optional label, brackets included, or is nil
+	Label *Span `json:"label,omitempty"`
+	// Note covers the optional note, or is nil
+	Note *Span `json:"note,omitempty"`
+}
+
+// shift returns the spans moved forward by offset bytes
+func (s *Spans) shift(offset int) *Spans {
+	shifted := &Spans{
+		Directive: s.Directive.shift(offset),
+		Keyword:   s.Keyword.shift(offset),
+		Tokens:    make([]Span, 0, len(s.Tokens)),
+	}
+	for _, token := range s.Tokens {
+		shifted.Tokens = append(shifted.Tokens, token.shift(offset))
+	}
+	if s.Date != nil {
+		date := s.Date.shift(offset)
+		shifted.Date = &date
+	}
+	if s.Label != nil {
+		label := s.Label.shift(offset)
+		shifted.Label = &label
+	}
+	if s.Note != nil {
+		note := s.Note.shift(offset)
+		shifted.Note = &note
+	}
+
+	return shifted
+}
+
+// spanComponents are the time components of the grammar, in the order they are written
+var spanComponents = []string{"months", "weeks", "days", "hours", "minutes"}
+
+// submatchSpan returns the span of the named group, or nil if it did not participate in the match
+func submatchSpan(indices []int, r *regexp.Regexp, name string) *Span {
+	i := r.SubexpIndex(name)
+	if i == -1 || indices[2*i] == -1 {
+		return nil
+	}
+
+	return &Span{Start: indices[2*i], End: indices[2*i+1]}
+}
@@ -0,0 +1,50 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCollectDirectivesWithSpans(t *testing.T) {
+	message := "fix: pâtisserie ☕\r\n\n  /spend 1 hour 30 2024-03-01 café ☕ crème\n/spent: 2d"
+	directives := CollectDirectivesWithSpans(message)
+	require.Len(t, directives, 2)
+
+	spans := directives[0].Spans
+	require.NotNil(t, spans)
+	assert.Equal(t, "/spend 1 hour 30 2024-03-01 café ☕ crème", spans.Directive.Of(message))
+	assert.Equal(t, "/spend", spans.Keyword.Of(message))
+	require.Len(t, spans.Tokens, 2)
+	assert.Equal(t, "1 hour", spans.Tokens[0].Of(message))
+	assert.Equal(t, "30", spans.Tokens[1].Of(message))
+	require.NotNil(t, spans.Date)
+	assert.Equal(t, "2024-03-01", spans.Date.Of(message))
+	require.NotNil(t, spans.Note)
+	assert.Equal(t, "café ☕ crème", spans.Note.Of(message))
+
+	spans = directives[1].Spans
+	assert.Equal(t, "/spent", spans.Keyword.Of(message))
+	require.Len(t, spans.Tokens, 1)
+	assert.Equal(t, "2d", spans.Tokens[0].Of(message))
+	assert.Nil(t, spans.Date)
+	assert.Nil(t, spans.Note)
+}
+
+func TestCollectDirectivesWithLabelSpan(t *testing.T) {
+	message := "/spend 2h [oncall] paged at 3am"
+	directives := CollectDirectivesWithSpans(message)
+	require.Len(t, directives, 1)
+
+	spans := directives[0].Spans
+	require.NotNil(t, spans.Label)
+	assert.Equal(t, "[oncall]", spans.Label.Of(message))
+	require.NotNil(t, spans.Note)
+	assert.Equal(t, "paged at 3am", spans.Note.Of(message))
+}
+
+func TestCollectDirectivesWithoutSpans(t *testing.T) {
+	directives := CollectDirectives("/spend 1h")
+	require.Len(t, directives, 1)
+	assert.Nil(t, directives[0].Spans)
+}
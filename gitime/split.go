@@ -0,0 +1,145 @@
+package gitime
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Ways to apportion the time spent by a commit across the directories or files it changes
+const (
+	SplitLines = "lines"
+	SplitFiles = "files"
+	SplitEven  = "even"
+)
+
+// SupportedSplits lists the ways accepted by Collector.Split
+var SupportedSplits = []string{SplitLines, SplitFiles, SplitEven}
+
+// FileChange is a file changed by a commit, as told by git diff --numstat
+type FileChange struct {
+	Path string
+	// Lines is how many lines were added or deleted, and zero for binary files
+	Lines int
+}
+
+// share is the part of the time spent by a commit that goes to a group
+type share struct {
+	Key    string
+	Weight float64
+}
+
+// isSplitGrouping tells whether the grouping attributes a commit to several groups, from its changes
+func isSplitGrouping(groupBy string) bool {
+	return groupBy == GroupByDirectory || groupBy == GroupByFile
+}
+
+// shares returns the groups of the changes of the commit, with their weight under the split.
+// Weights of lines fall back to files when the commit only changes binary files.
+func shares(changes []*FileChange, groupBy string, split string) []share {
+	weights := make(map[string]float64)
+	keys := make([]string, 0)
+	lines := 0
+	for _, change := range changes {
+		lines += change.Lines
+	}
+	for _, change := range changes {
+		key := change.Path
+		if groupBy == GroupByDirectory {
+			key = topDirectory(change.Path)
+		}
+		if _, exists := weights[key]; !exists {
+			keys = append(keys, key)
+		}
+		switch {
+		case split == SplitEven:
+			weights[key] = 1
+		case split == SplitLines && lines > 0:
+			weights[key] += float64(change.Lines)
+		default:
+			weights[key]++
+		}
+	}
+	sort.Strings(keys)
+
+	out := make([]share, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, share{Key: key, Weight: weights[key]})
+	}
+
+	return out
+}
+
+// topDirectory returns the top-level directory of the path, or "." for the files at the root
+func topDirectory(filePath string) string {
+	directory, _, found := strings.Cut(path.Clean(filePath), "/")
+	if !found {
+		return "."
+	}
+
+	return directory
+}
+
+// apportion splits the minutes across the shares, proportionally to their weight, in whole minutes that always
+// sum back to the minutes.  The remainder goes to the largest fractions, and then to the first shares.
+func apportion(minutes uint64, shares []share) []uint64 {
+	parts := make([]uint64, len(shares))
+	total := 0.0
+	for _, s := range shares {
+		total += s.Weight
+	}
+	if len(shares) == 0 || total == 0 {
+		return parts
+	}
+
+	fractions := make([]float64, len(shares))
+	given := uint64(0)
+	for i, s := range shares {
+		exact := float64(minutes) * s.Weight / total
+		parts[i] = uint64(exact)
+		fractions[i] = exact - float64(parts[i])
+		given += parts[i]
+	}
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]] > fractions[order[b]]
+	})
+	for i := 0; given < minutes; i++ {
+		parts[order[i%len(order)]]++
+		given++
+	}
+
+	return parts
+}
+
+// shareResults apportions the time spent by the commit across the groups of its changes, in minutes.
+// Commits without any change, like those read from stdin, go to the group without key,
+// and so do the commits whose changes cannot be read, along with the error of reading them.
+func (c *Collector) shareResults(commit *Commit, ts *TimeSpent) ([]*Result, error) {
+	var changes []*FileChange
+	var err error
+	if c.Changes != nil {
+		changes, err = c.Changes(commit, c.Split == SplitLines)
+	}
+	commitShares := shares(changes, c.GroupBy, c.Split)
+	if len(commitShares) == 0 {
+		return []*Result{{Commit: commit, Repository: c.Repository, TimeSpent: ts}}, err
+	}
+	results := make([]*Result, 0, len(commitShares))
+	for i, minutes := range apportion(ts.ToMinutes(), commitShares) {
+		if minutes == 0 {
+			continue
+		}
+		results = append(results, &Result{
+			Commit:     commit,
+			Repository: c.Repository,
+			Key:        commitShares[i].Key,
+			TimeSpent:  &TimeSpent{Minutes: float64(minutes)},
+		})
+	}
+
+	return results, nil
+}
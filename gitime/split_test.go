@@ -0,0 +1,94 @@
+package gitime
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestApportionSumsBackToTheMinutes(t *testing.T) {
+	even := []share{{Key: "a", Weight: 1}, {Key: "b", Weight: 1}, {Key: "c", Weight: 1}}
+	assert.Equal(t, []uint64{4, 3, 3}, apportion(10, even))
+	// The remainder goes to the largest fractions first
+	assert.Equal(t, []uint64{1, 6}, apportion(7, []share{{Key: "a", Weight: 1}, {Key: "b", Weight: 4}}))
+	assert.Equal(t, []uint64{0, 0}, apportion(0, even[:2]))
+	assert.Empty(t, apportion(10, []share{}))
+
+	for minutes := uint64(0); minutes < 500; minutes += 7 {
+		total := uint64(0)
+		for _, part := range apportion(minutes, []share{{Weight: 40}, {Weight: 1}, {Weight: 3}}) {
+			total += part
+		}
+		assert.Equal(t, minutes, total)
+	}
+}
+
+func TestShares(t *testing.T) {
+	changes := []*FileChange{
+		{Path: "web/app.js", Lines: 10},
+		{Path: "api/server.go", Lines: 20},
+		{Path: "api/routes.go", Lines: 10},
+		{Path: "README.md", Lines: 0},
+	}
+	assert.Equal(t, []share{{".", 0}, {"api", 30}, {"web", 10}}, shares(changes, GroupByDirectory, SplitLines))
+	assert.Equal(t, []share{{".", 1}, {"api", 2}, {"web", 1}}, shares(changes, GroupByDirectory, SplitFiles))
+	assert.Equal(t, []share{{".", 1}, {"api", 1}, {"web", 1}}, shares(changes, GroupByDirectory, SplitEven))
+	assert.Len(t, shares(changes, GroupByFile, SplitLines), 4)
+
+	binaries := []*FileChange{{Path: "a.png"}, {Path: "img/b.png"}}
+	assert.Equal(t, []share{{".", 1}, {"img", 1}}, shares(binaries, GroupByDirectory, SplitLines))
+}
+
+func TestCollector_CollectGroupedByDirectory(t *testing.T) {
+	changes := map[string][]*FileChange{
+		"1": {{Path: "api/a.go", Lines: 30}, {Path: "web/b.js", Lines: 10}},
+		"2": {{Path: "web/c.js", Lines: 1}},
+	}
+	commits := []*Commit{
+		{Hash: "1", Message: "/spend 1h"},
+		{Hash: "2", Message: "/spend 10m"},
+		{Hash: "3", Message: "/spend 5m"},
+	}
+	collector := &Collector{
+		GroupBy: GroupByDirectory,
+		Split:   SplitLines,
+		Changes: func(commit *Commit, lines bool) ([]*FileChange, error) {
+			assert.True(t, lines, "lines are counted to split by lines")
+			return changes[commit.Hash], nil
+		},
+	}
+
+	collection := collector.Collect(commits)
+	require.Len(t, collection.Groups, 3)
+	minutes := make(map[string]uint64)
+	for _, group := range collection.Groups {
+		minutes[group.Key] = group.TimeSpent.ToMinutes()
+	}
+	assert.Equal(t, map[string]uint64{"": 5, "api": 45, "web": 25}, minutes)
+	assert.Equal(t, uint64(75), collection.TimeSpent.ToMinutes())
+}
+
+func TestCollector_CollectGroupedByFileOfUnreadableChanges(t *testing.T) {
+	collector := &Collector{
+		GroupBy: GroupByFile,
+		Split:   SplitFiles,
+		Changes: func(commit *Commit, lines bool) ([]*FileChange, error) {
+			assert.False(t, lines, "lines are not counted to split by files")
+			if commit.Hash == "2" {
+				return nil, errors.New("could not fetch the blobs")
+			}
+			return []*FileChange{{Path: "a.go"}}, nil
+		},
+	}
+
+	collection := collector.Collect([]*Commit{
+		{Hash: "1", Message: "/spend 1h"},
+		{Hash: "2", Message: "/spend 10m"},
+	})
+	require.Len(t, collection.Warnings, 1)
+	assert.Equal(t, CodeChangesUnreadable, collection.Warnings[0].Code)
+	assert.Equal(t, "2", collection.Warnings[0].Hash)
+	assert.Contains(t, collection.Warnings[0].Message, "could not fetch the blobs")
+	assert.Equal(t, uint64(70), collection.TimeSpent.ToMinutes())
+}
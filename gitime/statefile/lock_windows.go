@@ -0,0 +1,30 @@
+//go:build windows
+
+package statefile
+
+import (
+	"errors"
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// tryLock takes an exclusive lock on the first byte of the file without waiting, and tells whether it got it
+func tryLock(file *os.File) (bool, error) {
+	err := windows.LockFileEx(
+		windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0,
+		1,
+		0,
+		&windows.Overlapped{},
+	)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func unlock(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
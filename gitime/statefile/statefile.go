@@ -0,0 +1,124 @@
+// Package statefile guards the files git-spend mutates, like the run log or the snapshots,
+// against concurrent invocations (say, a CI job and a shell) :
+// writers hold an advisory lock on a sibling lock file, and replace the files atomically,
+// so that a killed process never leaves a truncated file behind.
+package statefile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTimeout is how long to wait for another process to release a lock
+const DefaultTimeout = 10 * time.Second
+
+// LockSuffix is appended to the path of a file to get the path of its lock file
+const LockSuffix = ".lock"
+
+// pollInterval is how often a held lock is tried again
+const pollInterval = 50 * time.Millisecond
+
+// ErrLocked is returned when another process held the lock for longer than the timeout
+var ErrLocked = errors.New("another git-spend process holds the lock")
+
+// Lock is an advisory lock on a file, held until Unlock
+type Lock struct {
+	file *os.File
+}
+
+// Acquire locks the file at path, waiting at most timeout for other processes to release it.
+// The file itself is left untouched; the lock is held on a sibling lock file, created if needed.
+// Locks are released by the operating system when a process dies, so they are never stale.
+func Acquire(path string, timeout time.Duration) (*Lock, error) {
+	lockPath := path + LockSuffix
+	err := os.MkdirAll(filepath.Dir(lockPath), 0755)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		if locked {
+			return &Lock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = file.Close()
+			return nil, fmt.Errorf("%w on %s", ErrLocked, path)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// WriteAtomic replaces the content of the file at path all at once :
+// readers see either the previous content or the new one, never a part of it.
+// The content is written to a temporary file in the same directory, and then renamed over the file.
+func WriteAtomic(path string, content []byte, perm os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(temp.Name()) }()
+
+	_, err = temp.Write(content)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+// Update locks the file at path, and replaces its content atomically with what update returns.
+// The current content is given to update, and is nil if the file does not exist yet.
+func Update(path string, perm os.FileMode, timeout time.Duration, update func(content []byte) ([]byte, error)) error {
+	lock, err := Acquire(path, timeout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	updated, err := update(content)
+	if err != nil {
+		return err
+	}
+
+	return WriteAtomic(path, updated, perm)
+}
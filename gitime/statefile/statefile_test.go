@@ -0,0 +1,131 @@
+package statefile
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// helperEnv tells the test binary to act as one of the concurrent processes of the stress test
+const helperEnv = "GIT_SPEND_STATEFILE_HELPER"
+
+const (
+	stressProcesses = 6
+	stressUpdates   = 20
+)
+
+// state is the file the processes of the stress test update concurrently
+type state struct {
+	Count   int      `json:"count"`
+	Writers []string `json:"writers"`
+}
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(helperEnv); path != "" {
+		if err := incrementState(path, os.Getenv(helperEnv+"_NAME")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// incrementState updates the state a few times, the way any git-spend process would update its state files
+func incrementState(path string, name string) error {
+	for i := 0; i < stressUpdates; i++ {
+		err := Update(path, 0644, DefaultTimeout, func(content []byte) ([]byte, error) {
+			s := &state{}
+			if content != nil {
+				if err := json.Unmarshal(content, s); err != nil {
+					return nil, err
+				}
+			}
+			s.Count++
+			s.Writers = append(s.Writers, name)
+			return json.MarshalIndent(s, "", "  ")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestConcurrentProcessesNeitherCorruptNorLoseUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	var wg sync.WaitGroup
+	failures := make(chan string, stressProcesses)
+	for i := 0; i < stressProcesses; i++ {
+		process := exec.Command(os.Args[0], "-test.run=^$")
+		process.Env = append(os.Environ(), helperEnv+"="+path, helperEnv+"_NAME="+strconv.Itoa(i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out, err := process.CombinedOutput(); err != nil {
+				failures <- fmt.Sprintf("%v: %s", err, out)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("the processes are deadlocked")
+	}
+	close(failures)
+	for failure := range failures {
+		t.Error(failure)
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	s := &state{}
+	require.NoError(t, json.Unmarshal(content, s))
+	assert.Equal(t, stressProcesses*stressUpdates, s.Count)
+	assert.Len(t, s.Writers, stressProcesses*stressUpdates)
+
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestAcquireTimesOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	lock, err := Acquire(path, time.Second)
+	require.NoError(t, err)
+
+	// Locks are held per open file, so a second acquisition contends even within the same process
+	_, err = Acquire(path, 100*time.Millisecond)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.ErrorContains(t, err, path)
+
+	require.NoError(t, lock.Unlock())
+	lock, err = Acquire(path, 100*time.Millisecond)
+	require.NoError(t, err)
+	assert.NoError(t, lock.Unlock())
+}
+
+func TestWriteAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "badge.svg")
+	require.NoError(t, WriteAtomic(path, []byte("first"), 0644))
+	require.NoError(t, WriteAtomic(path, []byte("second"), 0600))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
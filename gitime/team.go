@@ -0,0 +1,110 @@
+package gitime
+
+import (
+	"strings"
+)
+
+// MailmapEntry is a line of a mailmap file, mapping the identity of commits to the proper identity of a person
+type MailmapEntry struct {
+	ProperName  string
+	ProperEmail string
+	CommitName  string
+	CommitEmail string
+}
+
+// identities returns the names and emails of the entry that are set
+func (e *MailmapEntry) identities() []string {
+	identities := make([]string, 0, 4)
+	for _, identity := range []string{e.ProperName, e.ProperEmail, e.CommitName, e.CommitEmail} {
+		if identity != "" {
+			identities = append(identities, identity)
+		}
+	}
+
+	return identities
+}
+
+// TeamMember is a person listed in a team file
+type TeamMember struct {
+	// Entry is the line of the team file, like "Alice" or "Alice <alice@example.com>"
+	Entry string
+	// Identities are the names and emails that designate the member, the mailmap included
+	Identities []string
+}
+
+// ParseTeam reads the members of a team file, one name or email (or both, like in "Alice <alice@example.com>")
+// per line.  Comments start with #, and list markers like "- " are ignored, so that a TEAM.md may do.
+func ParseTeam(content string) []*TeamMember {
+	members := make([]*TeamMember, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r", "\n"), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*+"))
+		if line == "" {
+			continue
+		}
+		member := &TeamMember{Entry: line, Identities: make([]string, 0)}
+		name, email, hasEmail := strings.Cut(line, "<")
+		if hasEmail {
+			member.addIdentity(strings.TrimSpace(name))
+			member.addIdentity(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(email), ">")))
+		} else {
+			member.addIdentity(line)
+		}
+		members = append(members, member)
+	}
+
+	return members
+}
+
+// ResolveTeam adds to the identities of the members all the names and emails the mailmap ties to them
+func ResolveTeam(members []*TeamMember, mailmap []*MailmapEntry) {
+	for _, member := range members {
+		for grown := true; grown; {
+			grown = false
+			for _, entry := range mailmap {
+				if !member.hasAnyIdentity(entry.identities()) {
+					continue
+				}
+				for _, identity := range entry.identities() {
+					grown = member.addIdentity(identity) || grown
+				}
+			}
+		}
+	}
+}
+
+// TeamIdentities returns the identities of all the members, without duplicates
+func TeamIdentities(members []*TeamMember) []string {
+	all := &TeamMember{Identities: make([]string, 0)}
+	for _, member := range members {
+		for _, identity := range member.Identities {
+			all.addIdentity(identity)
+		}
+	}
+
+	return all.Identities
+}
+
+// addIdentity adds the name or email to the identities of the member, unless it is empty or already there
+func (m *TeamMember) addIdentity(identity string) bool {
+	if identity == "" || m.hasAnyIdentity([]string{identity}) {
+		return false
+	}
+	m.Identities = append(m.Identities, identity)
+
+	return true
+}
+
+// hasAnyIdentity tells whether any of the names or emails designates the member
+func (m *TeamMember) hasAnyIdentity(identities []string) bool {
+	for _, identity := range identities {
+		for _, own := range m.Identities {
+			if FoldAuthorEmail(own) == FoldAuthorEmail(identity) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseTeam(t *testing.T) {
+	team := `# Team
+- Alice Doe <alice@example.com>
+bob@example.com   # contractor until May
+
+* Carol
+`
+	members := ParseTeam(team)
+	require.Len(t, members, 3)
+	assert.Equal(t, "Alice Doe <alice@example.com>", members[0].Entry)
+	assert.Equal(t, []string{"Alice Doe", "alice@example.com"}, members[0].Identities)
+	assert.Equal(t, []string{"bob@example.com"}, members[1].Identities)
+	assert.Equal(t, []string{"Carol"}, members[2].Identities)
+}
+
+func TestResolveTeam(t *testing.T) {
+	members := ParseTeam("bob@example.com\nCarol\n")
+	ResolveTeam(members, []*MailmapEntry{
+		{ProperEmail: "bob@example.com", CommitEmail: "bob@old.example.com"},
+		{ProperName: "Bobby", CommitEmail: "BOB@old.example.com"},
+		{ProperName: "Dave", CommitEmail: "dave@example.com"},
+	})
+	assert.Equal(t, []string{"bob@example.com", "bob@old.example.com", "Bobby"}, members[0].Identities)
+	assert.Equal(t, []string{"Carol"}, members[1].Identities)
+	assert.Equal(t, []string{"bob@example.com", "bob@old.example.com", "Bobby", "Carol"}, TeamIdentities(members))
+
+	assert.True(t, (&Commit{AuthorName: "Bobby", AuthorEmail: "b@elsewhere.com"}).IsAuthoredBy(members[0].Identities[2]))
+}
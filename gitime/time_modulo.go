@@ -41,6 +41,7 @@ func UpdateTimeModuloConfiguration() {
 	DaysInOneWeek = getConfigFloat([]string{"days_per_week", "days_in_one_week"}, DefaultDaysInOneWeek)
 	WeeksInOneMonth = getConfigFloat([]string{"weeks_per_month", "weeks_in_one_month"}, DefaultWeeksInOneMonth)
 	refreshCompoundConversions()
+	OverflowThreshold = getConfigFloat([]string{"overflow_threshold"}, DefaultOverflowThreshold)
 }
 
 func getConfigFloat(keys []string, defaultValue float64) float64 {
@@ -70,6 +71,7 @@ func init() {
 	viper.SetDefault("days_per_week", DefaultDaysInOneWeek)
 	viper.SetDefault("weeks_in_one_month", DefaultWeeksInOneMonth)
 	viper.SetDefault("weeks_per_month", DefaultWeeksInOneMonth)
+	viper.SetDefault("overflow_threshold", DefaultOverflowThreshold)
 	//viper.RegisterAlias("minutes_per_hour", "minutes_in_one_hour")
 	//viper.RegisterAlias("hours_per_day", "hours_in_one_day")
 	//viper.RegisterAlias("days_per_week", "days_in_one_week")
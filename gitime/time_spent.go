@@ -4,58 +4,180 @@ import (
 	"fmt"
 	"github.com/goutte/git-spend/locale"
 	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Units in which a TimeSpent may be displayed as a single number, see FormatInUnit.
+const (
+	UnitMinutes = "minutes"
+	UnitHours   = "hours"
+	UnitDays    = "days"
+)
+
+// SupportedUnits lists the units accepted by FormatInUnit
+var SupportedUnits = []string{UnitMinutes, UnitHours, UnitDays}
+
+// MaxMinutes is the longest duration, in minutes, that is still counted exactly (some 17 billion years).
+// Longer durations would overflow when converted to whole units.
+const MaxMinutes = 1 << 53
+
+// DefaultOverflowThreshold is how many of the next unit a unit may hold before a time spent is shown normalized.
+// With 60 minutes per hour, "600 minutes" is shown as is, but 601 minutes are shown as "1 day 2 hours 1 minute".
+const DefaultOverflowThreshold = 10.0
+
+var (
+	// OverflowThreshold is the threshold of Overflows, and zero (or less) never overflows
+	OverflowThreshold = DefaultOverflowThreshold
+	// ShowRaw shows the time spent as it was written or summed, even when it overflows
+	ShowRaw = false
 )
 
 type TimeSpent struct {
-	Months  float64
-	Weeks   float64
-	Days    float64
-	Hours   float64
-	Minutes float64
+	Months  float64 `json:"months"`
+	Weeks   float64 `json:"weeks"`
+	Days    float64 `json:"days"`
+	Hours   float64 `json:"hours"`
+	Minutes float64 `json:"minutes"`
 }
 
 func (ts *TimeSpent) String() string {
-	s := ""
+	return strings.Join(ts.Components(), " ")
+}
+
+// Overflows tells whether a unit holds strictly more than OverflowThreshold of the next unit,
+// like minutes over 600 under the default schedule.  Months never overflow.
+func (ts *TimeSpent) Overflows() bool {
+	if OverflowThreshold <= 0 {
+		return false
+	}
 
+	return ts.Minutes > OverflowThreshold*MinutesInOneHour ||
+		ts.Hours > OverflowThreshold*HoursInOneDay ||
+		ts.Days > OverflowThreshold*DaysInOneWeek ||
+		ts.Weeks > OverflowThreshold*WeeksInOneMonth
+}
+
+// displayed returns the time spent as it is shown : a normalized copy when it overflows (unless ShowRaw),
+// or else itself
+func (ts *TimeSpent) displayed() *TimeSpent {
+	if ShowRaw || !ts.Overflows() {
+		return ts
+	}
+	normalized := *ts
+
+	return normalized.Normalize()
+}
+
+// Components returns each unit group of the sentence, like "1 week" and "3 hours".
+// A time spent that overflows is shown normalized, see Overflows.
+func (ts *TimeSpent) Components() []string {
+	ts = ts.displayed()
+	components := make([]string, 0, 5)
 	if ts.Months > 0.0 {
-		s += ts.monthsToString()
+		components = append(components, ts.monthsToString())
 	}
 	if ts.Weeks > 0.0 {
-		if s != "" {
-			s += " "
-		}
-		s += ts.weeksToString()
+		components = append(components, ts.weeksToString())
 	}
 	if ts.Days > 0.0 {
-		if s != "" {
-			s += " "
-		}
-		s += ts.daysToString()
+		components = append(components, ts.daysToString())
 	}
 	if ts.Hours > 0.0 {
-		if s != "" {
-			s += " "
+		components = append(components, ts.hoursToString())
+	}
+	if ts.Minutes >= 0.1 {
+		components = append(components, ts.minutesToString())
+	}
+
+	return components
+}
+
+// CompactComponents returns each unit group abbreviated the way directives may be written, like "1w" and "3h".
+// A time spent that overflows is shown normalized, like with Components.
+func (ts *TimeSpent) CompactComponents() []string {
+	ts = ts.displayed()
+	components := make([]string, 0, 5)
+	add := func(value float64, spelling unitSpelling) {
+		if value <= 0.0 {
+			return
+		}
+		if _, fracPart := math.Modf(value); fracPart == 0.0 {
+			components = append(components, fmt.Sprintf("%d%s", int64(value), spelling.Abbreviation))
+		} else {
+			components = append(components, fmt.Sprintf("%.1f%s", value, spelling.Abbreviation))
 		}
-		s += ts.hoursToString()
 	}
+	add(ts.Months, monthsSpelling)
+	add(ts.Weeks, weeksSpelling)
+	add(ts.Days, daysSpelling)
+	add(ts.Hours, hoursSpelling)
 	if ts.Minutes >= 0.1 {
-		if s != "" {
-			s += " "
+		add(ts.Minutes, unitSpelling{Pattern: minutesSpelling.Pattern, Abbreviation: minutesAbbreviation()})
+	}
+
+	return components
+}
+
+// WrapComponents joins the components with spaces, breaking lines between them (never inside)
+// so that lines are not wider than maxWidth characters, when possible.  A maxWidth of zero never breaks.
+func WrapComponents(components []string, maxWidth int) string {
+	out := ""
+	width := 0
+	for _, component := range components {
+		componentWidth := utf8.RuneCountInString(component)
+		if width > 0 && maxWidth > 0 && width+1+componentWidth > maxWidth {
+			out += "\n"
+			width = 0
+		} else if width > 0 {
+			out += " "
+			width++
 		}
-		s += ts.minutesToString()
+		out += component
+		width += componentWidth
 	}
 
-	return s
+	return out
+}
+
+// IsZero is true when no time at all was spent
+func (ts *TimeSpent) IsZero() bool {
+	return ts.Months == 0.0 && ts.Weeks == 0.0 && ts.Days == 0.0 && ts.Hours == 0.0 && ts.Minutes == 0.0
+}
+
+// Validate tells whether the time spent is a duration that can be counted : finite, not negative,
+// and no longer than MaxMinutes
+func (ts *TimeSpent) Validate() error {
+	minutes := ts.toExactMinutes()
+	switch {
+	case math.IsNaN(minutes) || math.IsInf(minutes, 0):
+		return fmt.Errorf(locale.Tf("DurationNotFinite", minutes))
+	case minutes < 0:
+		return fmt.Errorf(locale.Tf("DurationNegative", minutes))
+	case minutes > MaxMinutes:
+		return fmt.Errorf(locale.Tf("DurationTooLong", minutes, float64(MaxMinutes)))
+	}
+
+	return nil
 }
 
 func (ts *TimeSpent) ToMinutes() uint64 {
+	return uint64(math.Round(ts.toExactMinutes()))
+}
+
+// InMinutes returns the same duration expressed only in minutes, under the current schedule
+func (ts *TimeSpent) InMinutes() *TimeSpent {
+	return &TimeSpent{Minutes: ts.toExactMinutes()}
+}
+
+func (ts *TimeSpent) toExactMinutes() float64 {
 	minutes := ts.Minutes
 	minutes += ts.Hours * MinutesInOneHour
 	minutes += ts.Days * MinutesInOneDay
 	minutes += ts.Weeks * MinutesInOneWeek
 	minutes += ts.Months * MinutesInOneMonth
 
-	return uint64(math.Round(minutes))
+	return minutes
 }
 
 func (ts *TimeSpent) ToHours() uint64 {
@@ -82,6 +204,47 @@ func (ts *TimeSpent) ToMonths() uint64 {
 	return uint64(hours)
 }
 
+// FormatInUnit returns the time spent as a single comparable number in the specified unit,
+// converted using the time modulo configuration.  Minutes are rounded to integers,
+// whereas hours and days are formatted with one decimal place.
+func (ts *TimeSpent) FormatInUnit(unit string) (string, error) {
+	minutes := ts.ToMinutes()
+	switch unit {
+	case UnitMinutes:
+		return fmt.Sprintf("%d", minutes), nil
+	case UnitHours:
+		return fmt.Sprintf("%.1f", float64(minutes)/MinutesInOneHour), nil
+	case UnitDays:
+		return fmt.Sprintf("%.1f", float64(minutes)/MinutesInOneDay), nil
+	}
+
+	return "", fmt.Errorf(locale.Tf("UnitUnsupported", unit, strings.Join(SupportedUnits, ", ")))
+}
+
+// UnitLabel returns the localized name of the unit, pluralized according to value
+func UnitLabel(unit string, value float64) string {
+	plural := value >= 2.0
+	switch unit {
+	case UnitMinutes:
+		if plural {
+			return locale.T("UnitMinutePlural")
+		}
+		return locale.T("UnitMinuteSingular")
+	case UnitHours:
+		if plural {
+			return locale.T("UnitHourPlural")
+		}
+		return locale.T("UnitHourSingular")
+	case UnitDays:
+		if plural {
+			return locale.T("UnitDayPlural")
+		}
+		return locale.T("UnitDaySingular")
+	}
+
+	return unit
+}
+
 func (ts *TimeSpent) Add(other *TimeSpent) *TimeSpent {
 	ts.Minutes += other.Minutes
 	ts.Hours += other.Hours
@@ -0,0 +1,116 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTimeSpent_Validate(t *testing.T) {
+	assert.NoError(t, (&TimeSpent{}).Validate())
+	assert.NoError(t, (&TimeSpent{Months: 2, Minutes: 30}).Validate())
+	assert.NoError(t, (&TimeSpent{Minutes: MaxMinutes}).Validate())
+	for _, ts := range []*TimeSpent{
+		{Minutes: math.NaN()},
+		{Minutes: math.Inf(1)},
+		{Hours: math.Inf(-1)},
+		{Minutes: -1},
+		{Minutes: 1e30},
+		{Months: 1e20},
+		{Minutes: MaxMinutes + 1024},
+	} {
+		assert.Error(t, ts.Validate(), "%+v", ts)
+	}
+}
+
+func TestTimeSpent_FormatInUnit(t *testing.T) {
+	tests := []struct {
+		name     string
+		ts       *TimeSpent
+		unit     string
+		expected string
+	}{
+		{"minutes", &TimeSpent{Hours: 2, Minutes: 30}, UnitMinutes, "150"},
+		{"hours", &TimeSpent{Hours: 2, Minutes: 30}, UnitHours, "2.5"},
+		{"hours are rounded to one decimal", &TimeSpent{Minutes: 100}, UnitHours, "1.7"},
+		{"days use the schedule", &TimeSpent{Weeks: 1, Hours: 3}, UnitDays, "5.4"},
+		{"nothing", &TimeSpent{}, UnitHours, "0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ts.FormatInUnit(tt.unit)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestTimeSpent_FormatInUnitUnsupported(t *testing.T) {
+	_, err := (&TimeSpent{Hours: 1}).FormatInUnit("fortnights")
+	assert.Error(t, err)
+}
+
+func TestTimeSpent_CompactComponentsAreParsable(t *testing.T) {
+	ts := &TimeSpent{Months: 1, Weeks: 2, Days: 3, Hours: 4.5, Minutes: 20}
+	components := ts.CompactComponents()
+	assert.Equal(t, []string{"1mo", "2w", "3d", "4.5h", "20m"}, components)
+
+	parsed, err := ParseTimeSpent(strings.Join(components, " "))
+	assert.NoError(t, err)
+	assert.Equal(t, ts, parsed)
+}
+
+func TestTimeSpent_FormattingRoundTrips(t *testing.T) {
+	for minutes := 1.0; minutes < 20000; minutes += 37 {
+		ts := (&TimeSpent{Minutes: minutes}).Normalize()
+		for _, formatted := range []string{ts.String(), strings.Join(ts.CompactComponents(), " ")} {
+			parsed, err := ParseTimeSpent(formatted)
+			if assert.NoError(t, err, formatted) {
+				assert.Equal(t, uint64(minutes), parsed.ToMinutes(), formatted)
+			}
+		}
+	}
+}
+
+func TestWrapComponents(t *testing.T) {
+	components := (&TimeSpent{Weeks: 1, Days: 2, Hours: 3, Minutes: 20}).Components()
+	assert.Equal(t, "1 week 2 days 3 hours 20 minutes", WrapComponents(components, 0))
+	assert.Equal(t, "1 week 2 days 3 hours 20 minutes", WrapComponents(components, 80))
+	assert.Equal(t, "1 week 2 days\n3 hours\n20 minutes", WrapComponents(components, 15))
+	assert.Equal(t, "1 week\n2 days\n3 hours\n20 minutes", WrapComponents(components, 3))
+	assert.Equal(t, "", WrapComponents([]string{}, 10))
+}
+
+func TestTimeSpent_StringOverflows(t *testing.T) {
+	tests := []struct {
+		name     string
+		ts       *TimeSpent
+		expected string
+	}{
+		{"minutes at the threshold", &TimeSpent{Minutes: 600}, "600 minutes"},
+		{"minutes over the threshold", &TimeSpent{Minutes: 601}, "1 day 2 hours 1 minute"},
+		{"a fraction over the threshold", &TimeSpent{Minutes: 600.4}, "1 day 2 hours 0.4 minute"},
+		{"hours at the threshold", &TimeSpent{Hours: 80}, "80 hours"},
+		{"hours over the threshold", &TimeSpent{Hours: 81}, "2 weeks 1 hour"},
+		{"days at the threshold", &TimeSpent{Days: 50, Minutes: 600}, "50 days 600 minutes"},
+		{"days over the threshold", &TimeSpent{Days: 51}, "2 months 2 weeks 1 day"},
+		{"weeks over the threshold", &TimeSpent{Weeks: 41}, "10 months 1 week"},
+		{"months never overflow", &TimeSpent{Months: 1000}, "1000 months"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.ts.String())
+		})
+	}
+
+	raw := &TimeSpent{Minutes: 2347}
+	assert.Equal(t, "4d 7h 7m", strings.Join(raw.CompactComponents(), " "))
+	assert.Equal(t, float64(2347), raw.Minutes, "the time spent is not normalized in place")
+	ShowRaw = true
+	assert.Equal(t, "2347 minutes", raw.String())
+	ShowRaw = false
+	OverflowThreshold = 0
+	assert.Equal(t, "2347 minutes", raw.String())
+	OverflowThreshold = DefaultOverflowThreshold
+}
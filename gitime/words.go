@@ -0,0 +1,192 @@
+package gitime
+
+import (
+	"regexp"
+	"strings"
+)
+
+// NumberWords is a vocabulary of number words, to read directives like "/spend two hours" or "/spend half a day".
+// Units are spelled like in the rest of the grammar.
+type NumberWords struct {
+	// Numbers are the words of whole numbers, including the articles meaning one, like "an" in "an hour"
+	Numbers map[string]float64
+	// Fractions are the words of fractions, like "half"
+	Fractions map[string]float64
+	// And adds a fraction to what precedes it, like in "two and a half hours" or "an hour and a half"
+	And string
+	// Of joins a fraction to its unit, like in "a quarter of an hour"
+	Of string
+}
+
+// EnglishNumberWords is the vocabulary of number words in English
+var EnglishNumberWords = &NumberWords{
+	Numbers: map[string]float64{
+		"a": 1, "an": 1,
+		"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+		"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	},
+	Fractions: map[string]float64{
+		"half":    0.5,
+		"quarter": 0.25,
+	},
+	And: "and",
+	Of:  "of",
+}
+
+// NumberWordsByLanguage are the vocabularies of number words, by language of the commit messages
+var NumberWordsByLanguage = map[string]*NumberWords{
+	"en": EnglishNumberWords,
+}
+
+// SupportedNumberWordsLanguages are the languages that have a vocabulary of number words
+var SupportedNumberWordsLanguages = []string{"en"}
+
+// WordNumbers is the vocabulary of number words read in directives, or nil to only read digits (the default).
+// Directives are first read with digits, and only directives without any time spent are read again with words.
+var WordNumbers *NumberWords = nil
+
+// unitWords match the spellings of the units as whole words, in the order of spanComponents
+var unitWords = []*regexp.Regexp{
+	regexp.MustCompile("^(?:" + monthsSpelling.Pattern + ")$"),
+	regexp.MustCompile("^(?:" + weeksSpelling.Pattern + ")$"),
+	regexp.MustCompile("^(?:" + daysSpelling.Pattern + ")$"),
+	regexp.MustCompile("^(?:" + hoursSpelling.Pattern + ")$"),
+	regexp.MustCompile("^(?:" + minutesSpelling.Pattern + ")$"),
+}
+
+var keywordOnlyRegex = regexp.MustCompile(commandRegex)
+var wordRegex = regexp.MustCompile(`[^\s,]+`)
+
+// word is a word of a directive, with its position in the line
+type word struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// extractDirectiveUsingWords reads the (trimmed) line like "/spend an hour and a half" with the vocabulary,
+// or returns nil when the line does not start with the keyword followed by quantities of units in words.
+// Anything after the last unit is read as the date and the note, like with digits.
+func extractDirectiveUsingWords(line string, vocabulary *NumberWords) *Directive {
+	keyword := keywordOnlyRegex.FindStringSubmatchIndex(line)
+	if keyword == nil {
+		return nil
+	}
+	words := make([]word, 0)
+	for _, indices := range wordRegex.FindAllStringIndex(line[keyword[1]:], -1) {
+		start, end := keyword[1]+indices[0], keyword[1]+indices[1]
+		words = append(words, word{Text: strings.ToLower(line[start:end]), Start: start, End: end})
+	}
+
+	components := make([]float64, len(spanComponents))
+	tokens := make([]Span, 0)
+	ambiguousM := ""
+	end := keyword[1]
+	i := 0
+	for i < len(words) {
+		next := i
+		if len(tokens) > 0 {
+			if words[next].Text != vocabulary.And {
+				break
+			}
+			next++
+		}
+		quantity, next := vocabulary.readQuantity(words, next)
+		if quantity == 0 || next >= len(words) {
+			break
+		}
+		component := unitComponent(words[next].Text)
+		if component < 0 {
+			break
+		}
+		if words[next].Text == bareM {
+			ambiguousM = bareMReading()
+		}
+		next++
+		// Like "an hour and a half"
+		if next+1 < len(words) && words[next].Text == vocabulary.And {
+			fraction, afterFraction := vocabulary.readQuantity(words, next+1)
+			if fraction > 0 && fraction < 1 && (afterFraction >= len(words) || unitComponent(words[afterFraction].Text) < 0) {
+				quantity += fraction
+				next = afterFraction
+			}
+		}
+		components[component] += quantity
+		tokens = append(tokens, Span{Start: words[i].Start, End: words[next-1].End})
+		if len(tokens) > 1 {
+			// The joining word is not part of the quantity
+			tokens[len(tokens)-1].Start = words[i+1].Start
+		}
+		end = words[next-1].End
+		i = next
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	spans := &Spans{
+		Directive: Span{Start: 0, End: len(line)},
+		Keyword:   Span{Start: keyword[2], End: keyword[3]},
+		Tokens:    tokens,
+	}
+	date, label, note := readDirectiveSuffix(line, end, spans)
+
+	return &Directive{
+		Line:       line,
+		TimeSpent:  timeSpentFromComponents(components),
+		Date:       date,
+		Label:      label,
+		Note:       note,
+		AmbiguousM: ambiguousM,
+		Spans:      spans,
+	}
+}
+
+// readQuantity reads the words of a quantity from the ith word, like "two", "half a" or "two and a half",
+// and returns it with the index of the first word after it, or zero if there is no quantity there.
+func (v *NumberWords) readQuantity(words []word, i int) (float64, int) {
+	quantity := 1.0
+	numbers, fractions := 0, 0
+	for ; i < len(words); i++ {
+		if number, isNumber := v.Numbers[words[i].Text]; isNumber && numbers == 0 {
+			quantity *= number
+			numbers++
+		} else if fraction, isFraction := v.Fractions[words[i].Text]; isFraction && fractions == 0 {
+			quantity *= fraction
+			fractions++
+			// The article of "half an hour" is not a second number
+			numbers = 0
+		} else if words[i].Text == v.Of && fractions > 0 {
+			continue
+		} else {
+			break
+		}
+	}
+	if numbers+fractions == 0 {
+		return 0, i
+	}
+	// Like "two and a half hours"
+	if fractions == 0 && i+2 < len(words) && words[i].Text == v.And {
+		fraction, next := v.readQuantity(words, i+1)
+		if fraction > 0 && fraction < 1 && next < len(words) && unitComponent(words[next].Text) >= 0 {
+			return quantity + fraction, next
+		}
+	}
+
+	return quantity, i
+}
+
+// unitComponent returns the index in spanComponents of the unit spelled by the word, or -1.
+// The bare "m" is read according to AmbiguousM.
+func unitComponent(text string) int {
+	if text == bareM && bareMReading() == AmbiguousMonths {
+		return 0
+	}
+	for i, unit := range unitWords {
+		if unit.MatchString(text) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,59 @@
+package gitime
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectTimeSpentWithWordNumbers(t *testing.T) {
+	defer func() { WordNumbers = nil }()
+	tests := []struct {
+		message  string
+		expected uint64
+	}{
+		{"/spend two hours", 120},
+		{"/spend an hour", 60},
+		{"/spend half a day", 240},
+		{"/spend half an hour", 30},
+		{"/spend a quarter of an hour", 15},
+		{"/spend an hour and a half", 90},
+		{"/spend two and a half hours", 150},
+		{"/spend a day and a half", 720},
+		{"/spend an hour and a half, and ten minutes", 100},
+		{"/spend twelve minutes", 12},
+		{"/spend Two Hours", 120},
+		{"/spent: three days", 3 * 480},
+		// Digits are read first, and words only when they spend nothing
+		{"/spend 1h and a half", 60},
+		// Outside the vocabulary
+		{"/spend a while", 0},
+		{"/spend thirteen hours", 0},
+		{"/spend two", 0},
+		{"/spend halfway", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			WordNumbers = EnglishNumberWords
+			assert.Equal(t, tt.expected, CollectTimeSpent(tt.message).ToMinutes())
+		})
+	}
+}
+
+func TestCollectDirectivesWithWordNumbersKeepsTheNote(t *testing.T) {
+	defer func() { WordNumbers = nil }()
+	WordNumbers = EnglishNumberWords
+	directives := CollectDirectivesWithSpans("/spend an hour and a half 2024-03-01 reviewing")
+	if assert.Len(t, directives, 1) {
+		directive := directives[0]
+		assert.Equal(t, 1.5, directive.TimeSpent.Hours)
+		assert.Equal(t, "reviewing", directive.Note)
+		assert.NotNil(t, directive.Date)
+		token := directive.Spans.Tokens[0]
+		assert.Equal(t, "an hour and a half", directive.Line[token.Start:token.End])
+		assert.Equal(t, "2024-03-01", directive.Line[directive.Spans.Date.Start:directive.Spans.Date.End])
+	}
+}
+
+func TestCollectTimeSpentIgnoresWordNumbersByDefault(t *testing.T) {
+	assert.Equal(t, uint64(0), CollectTimeSpent("/spend two hours").ToMinutes())
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/goutte/git-spend/locale/guesser"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"golang.org/x/text/language"
+	"io/fs"
 )
 
 // defaultLanguage should be language.Esperanto 💡 ("eo")
@@ -19,6 +20,11 @@ var extension = "toml"
 //go:embed *.toml
 var localeFS embed.FS
 
+// Assets returns the embedded translation files
+func Assets() fs.FS {
+	return localeFS
+}
+
 // Localizer can be used to fetch localized messages
 var Localizer *i18n.Localizer
 
@@ -83,11 +83,36 @@ TMP_FIXTURE_DIR="/tmp/git-spend-fixture"
   assert_output "0"
 }
 
+@test "git-spend sum --unit minutes" {
+  run "${git_spend}" sum --unit minutes
+  assert_success
+  assert_output "2580 minutes (1 week 3 hours)"
+}
+
+@test "git-spend sum --unit hours" {
+  run "${git_spend}" sum --unit hours
+  assert_success
+  assert_output "43.0 hours (1 week 3 hours)"
+}
+
+@test "git-spend sum --unit days" {
+  run "${git_spend}" sum --unit days
+  assert_success
+  assert_output "5.4 days (1 week 3 hours)"
+}
+
+@test "git-spend sum --unit <unsupported> should fail" {
+  run "${git_spend}" sum --unit fortnights
+  assert_failure
+}
+
 @test "git-spend sum unit formats are mutually exclusive" {
   run "${git_spend}" sum --months --days
   assert_failure
   run "${git_spend}" sum --hours --minutes --weeks
   assert_failure
+  run "${git_spend}" sum --unit hours --hours
+  assert_failure
 }
 
 @test "git-spend sum --author Goutte" {
@@ -102,6 +127,30 @@ TMP_FIXTURE_DIR="/tmp/git-spend-fixture"
   assert_output "1 week 3 hours"
 }
 
+@test "git-spend sum --author ignores the case of names" {
+  run "${git_spend}" sum --author GOUTTE
+  assert_success
+  assert_output "1 week 3 hours"
+}
+
+@test "git-spend sum --author ignores the case of email domains" {
+  run "${git_spend}" sum --author antoine@GOUTENOIR.com
+  assert_success
+  assert_output "1 week 3 hours"
+}
+
+@test "git-spend sum --author --case-sensitive-emails" {
+  run "${git_spend}" sum --author ANTOINE@goutenoir.com --case-sensitive-emails
+  assert_success
+  assert_output --partial "No time-tracking /spend directives found in commits"
+}
+
+@test "git-spend sum --author --fold-accents" {
+  run "${git_spend}" sum --author Gòutté --fold-accents
+  assert_success
+  assert_output "1 week 3 hours"
+}
+
 @test "git-spend sum --author notfound (should fail)" {
   run "${git_spend}" sum --author notfound
   # shouldn't we fail, here?   TBD
@@ -225,6 +274,30 @@ TMP_FIXTURE_DIR="/tmp/git-spend-fixture"
   assert_output "2 hours 15 minutes"
 }
 
+@test "git-spend sum --last week --verbose" {
+  run "${git_spend}" sum --last week --verbose
+  assert_success
+  assert_output --partial "window: "
+  # the fixture commits are way older than last week
+  assert_output --partial "No time-tracking /spend directives found in commits"
+}
+
+@test "git-spend sum --this month --format json" {
+  run "${git_spend}" sum --this month --format json
+  assert_success
+  assert_output --partial '"window": {'
+}
+
+@test "git-spend sum --last <unsupported> should fail" {
+  run "${git_spend}" sum --last century
+  assert_failure
+}
+
+@test "git-spend sum --last does not accept --since" {
+  run "${git_spend}" sum --last month --since 0.1.0
+  assert_failure
+}
+
 @test "git-spend sum but nothing was found" {
   run "${git_spend}" sum --since "2023-03-25" --until "2023-03-25"
   assert_success
@@ -289,6 +362,1050 @@ TMP_FIXTURE_DIR="/tmp/git-spend-fixture"
   assert_failure
 }
 
+@test "git-spend badge" {
+  run "${git_spend}" badge --window '100 years'
+  assert_success
+  assert_output --partial '<svg xmlns="http://www.w3.org/2000/svg"'
+  assert_output --partial 'aria-label="time-logged: '
+}
+
+@test "git-spend badge --format json" {
+  run "${git_spend}" badge --window '100 years' --format json
+  assert_success
+  assert_output --partial '"schemaVersion": 1'
+  assert_output --partial '"label": "time-logged"'
+}
+
+@test "git-spend badge --out <file>" {
+  run "${git_spend}" badge --window '100 years' --out badge.svg
+  assert_success
+  assert [ -s badge.svg ]
+}
+
+@test "git-spend badge counts the commits like sum" {
+  git init --quiet "${BATS_TEST_TMPDIR}/badge"
+  cd "${BATS_TEST_TMPDIR}/badge"
+  git config core.commentChar ';'
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty -m "feat: a"
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty --cleanup=verbatim \
+    -m $'feat: b\n\n; ------------------------ >8 ------------------------\n/spend 2h'
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty \
+    -m $'feat: c\n\n/spend three hours'
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty -m $'feat: d\n\n/spend 1h'
+
+  run "${git_spend}" badge --window '100 years' --format json
+  assert_success
+  assert_output --partial '"message": "25%"'
+  run env GIT_SPEND_WORD_NUMBERS=en "${git_spend}" badge --window '100 years' --format json
+  assert_success
+  assert_output --partial '"message": "50%"'
+}
+
+@test "git-spend badge --window <wrong> should fail" {
+  run "${git_spend}" badge --window 'since forever'
+  assert_failure
+}
+
+@test "git-spend show HEAD" {
+  run "${git_spend}" show HEAD
+  assert_success
+  assert_output --partial "total: 2 hours"
+}
+
+@test "git-spend show HEAD --format json" {
+  run "${git_spend}" show HEAD --format json
+  assert_success
+  assert_output --partial '"minutes": 120'
+}
+
+@test "git-spend show HEAD --explain" {
+  run "${git_spend}" show HEAD --explain
+  assert_success
+  assert_output --partial 'keyword ['
+  assert_output --partial '] 2h'
+}
+
+@test "git-spend show <wrong> should fail" {
+  run "${git_spend}" show lololololo
+  assert_failure
+}
+
+@test "git-spend sum --format json" {
+  run "${git_spend}" sum --format json
+  assert_success
+  assert_output --partial '"minutes": 2580'
+  assert_output --partial '"warnings": []'
+}
+
+@test "git-spend sum --max-directive warns about absurd directives" {
+  run "${git_spend}" sum --max-directive 1m
+  assert_success
+  assert_output --partial "warning[GT006]: commit"
+  assert_output --partial "1 week 3 hours"
+}
+
+@test "git-spend sum --max-directive --enforce-max excludes absurd directives" {
+  run "${git_spend}" sum --max-directive 1m --enforce-max --minutes
+  assert_success
+  assert_output --partial "excluded from the total: 1 week 3 hours"
+  assert_line "0"
+}
+
+@test "git-spend sum --max-directive --format json has warnings" {
+  run "${git_spend}" sum --max-directive 1m --format json
+  assert_success
+  assert_output --partial '"hash": '
+}
+
+@test "Support for GIT_SPEND_MAX_DIRECTIVE" {
+  export GIT_SPEND_MAX_DIRECTIVE=1m
+  run "${git_spend}" sum
+  assert_success
+  assert_output --partial "warning[GT006]: commit"
+}
+
+@test "git-spend sum --dedupe cherry-pick" {
+  run "${git_spend}" sum --dedupe cherry-pick --verbose
+  assert_success
+  assert_output --partial "collapsed duplicated commits: 0"
+  assert_output --partial "1 week 3 hours"
+}
+
+@test "git-spend sum --dedupe <unsupported> should fail" {
+  run "${git_spend}" sum --dedupe telepathy
+  assert_failure
+}
+
+@test "git-spend sum --group-by author" {
+  run "${git_spend}" sum --group-by author
+  assert_success
+  assert_line --regexp "^Goutte +1 week 3 hours$"
+  assert_line --regexp "^total +1 week 3 hours$"
+}
+
+@test "git-spend sum --group-by author --unit hours" {
+  run "${git_spend}" sum --group-by author --unit hours
+  assert_success
+  assert_line --regexp "^Goutte +43.0$"
+  assert_line --regexp "^total +43.0 hours \(1 week 3 hours\)$"
+}
+
+@test "git-spend sum --group-by author --with-span --format csv" {
+  run "${git_spend}" sum --group-by author --with-span --format csv --minutes
+  assert_success
+  assert_line "author,time spent,first,last,span (days)"
+  assert_line --regexp "^Goutte,2580,2023-[0-9-]+,2023-[0-9-]+,[0-9]+$"
+}
+
+@test "git-spend sum --group-by author --with-span --format json" {
+  run "${git_spend}" sum --group-by author --with-span --format json
+  assert_success
+  assert_output --partial '"key": "Goutte"'
+  assert_output --partial '"span_days": '
+}
+
+@test "git-spend sum --with-span requires --group-by" {
+  run "${git_spend}" sum --with-span
+  assert_failure
+}
+
+@test "git-spend sum --min-granularity only warns" {
+  run "${git_spend}" sum --min-granularity 7h
+  assert_success
+  assert_output --partial "is not a multiple of the minimum granularity of 7 hours"
+  assert_output --partial "1 week 3 hours"
+}
+
+@test "git-spend sum --min-granularity --strict fails" {
+  run "${git_spend}" sum --min-granularity 7h --strict
+  assert_failure
+}
+
+@test "git-spend lint-message --min-granularity" {
+  run bash -c "printf 'feat: a\n\n/spend 30m\n' | $git_spend lint-message --min-granularity 15m"
+  assert_success
+  run bash -c "printf 'feat: a\n\n/spend 20m\n' | $git_spend lint-message --min-granularity 15m"
+  assert_failure
+  assert_output --partial "20 minutes is not a multiple of the minimum granularity of 15 minutes"
+}
+
+@test "git-spend lint-message <file>" {
+  printf 'feat: a\n\n/spend 5m\n' > COMMIT_MSG_TEST
+  run "${git_spend}" lint-message --min-granularity 15m COMMIT_MSG_TEST
+  assert_failure
+  run "${git_spend}" lint-message COMMIT_MSG_TEST
+  assert_success
+}
+
+@test "git-spend sum --target <dir> --target <dir> with disagreeing configs" {
+  for repo in seven eight ; do
+    git init --quiet "${BATS_TEST_TMPDIR}/${repo}"
+    git -C "${BATS_TEST_TMPDIR}/${repo}" -c user.name=Alice -c user.email=alice@example.com \
+      commit --quiet --allow-empty -m $'feat: work\n\n/spend 1d'
+  done
+  echo "hours_per_day: 7" > "${BATS_TEST_TMPDIR}/seven/.git-spend.yaml"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/seven" --minutes
+  assert_success
+  assert_output "420"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/seven" --target "${BATS_TEST_TMPDIR}/eight" --minutes
+  assert_success
+  assert_output --partial "disagree on hours_per_day: 8 ≠ 7"
+  assert_line --index 1 "900"
+}
+
+@test "git-spend sum environment wins over repository config" {
+  git init --quiet "${BATS_TEST_TMPDIR}/seven"
+  git -C "${BATS_TEST_TMPDIR}/seven" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: work\n\n/spend 1d'
+  echo "hours_per_day: 7" > "${BATS_TEST_TMPDIR}/seven/.git-spend.yaml"
+
+  export GIT_SPEND_HOURS_PER_DAY=6
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/seven" --minutes
+  assert_success
+  assert_output "360"
+}
+
+@test "git-spend sum reads the config of the home directory" {
+  git init --quiet "${BATS_TEST_TMPDIR}/repo"
+  git -C "${BATS_TEST_TMPDIR}/repo" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: work\n\n/spend 1d'
+  mkdir -p "${BATS_TEST_TMPDIR}/home"
+  echo "hours_per_day: 4" > "${BATS_TEST_TMPDIR}/home/.git-spend.yaml"
+
+  run env HOME="${BATS_TEST_TMPDIR}/home" "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/repo" --minutes
+  assert_success
+  assert_output "240"
+
+  # The config of the repository wins over the one of the home directory
+  echo "hours_per_day: 7" > "${BATS_TEST_TMPDIR}/repo/.git-spend.yaml"
+  run env HOME="${BATS_TEST_TMPDIR}/home" "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/repo" --minutes
+  assert_success
+  assert_output "420"
+}
+
+@test "git-spend sum in a repository without commits" {
+  git init --quiet "${BATS_TEST_TMPDIR}/empty"
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/empty"
+  assert_success
+  assert_output "No commits to scan: the repository has no commits yet."
+}
+
+@test "git-spend sum --format json in a repository without commits" {
+  git init --quiet "${BATS_TEST_TMPDIR}/empty"
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/empty" --format json
+  assert_success
+  assert_output --partial '"minutes": 0'
+}
+
+@test "git-spend sum --fail-if-empty in a repository without commits should fail" {
+  git init --quiet "${BATS_TEST_TMPDIR}/empty"
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/empty" --fail-if-empty
+  assert_failure
+  assert_output "No commits to scan: the repository has no commits yet."
+}
+
+@test "git-spend sum --group-by issue --forge github" {
+  git init --quiet "${BATS_TEST_TMPDIR}/issues"
+  git -C "${BATS_TEST_TMPDIR}/issues" remote add origin git@github.com:owner/repo.git
+  git -C "${BATS_TEST_TMPDIR}/issues" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'fix #12\n\n/spend 1h'
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/issues" --group-by issue --verbose
+  assert_success
+  assert_output --partial "forge: github (detected from the origin remote)"
+  assert_output --partial "#12"
+}
+
+@test "git-spend sum --group-by directory --split" {
+  repository="${BATS_TEST_TMPDIR}/split"
+  git init --quiet "${repository}"
+  mkdir "${repository}/api" "${repository}/web"
+  for i in $(seq 1 40); do echo "x" > "${repository}/api/file${i}.go"; done
+  echo "x" > "${repository}/web/app.js"
+  git -C "${repository}" add --all
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet -m $'feat: everything\n\n/spend 41m'
+  run "${git_spend}" sum --target "${repository}" --group-by directory --split lines --no-header
+  assert_success
+  assert_line --regexp "^api +40 minutes$"
+  assert_line --regexp "^web +1 minute$"
+  run "${git_spend}" sum --target "${repository}" --group-by directory --split even --no-header
+  assert_success
+  assert_line --regexp "^api +21 minutes$"
+  assert_line --regexp "^web +20 minutes$"
+  assert_line --regexp "^total +41 minutes$"
+}
+
+@test "git-spend sum --split without a grouping by directory or file should fail" {
+  run "${git_spend}" sum --split even
+  assert_failure
+}
+
+@test "git-spend sum --forge <unsupported> should fail" {
+  run "${git_spend}" sum --forge sourceforge
+  assert_failure
+}
+
+@test "git-spend snapshot write and verify" {
+  run "${git_spend}" snapshot write "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_success
+  run "${git_spend}" snapshot verify "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_success
+  assert_output --partial "No drift"
+}
+
+@test "git-spend snapshot verify detects rewritten history" {
+  run "${git_spend}" snapshot write "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_success
+  git -c user.name=Goutte -c user.email=antoine@goutenoir.com \
+    commit --quiet --amend --allow-empty --date="2001-01-01" -m $'rewritten\n\n/spend 9h'
+  run "${git_spend}" snapshot verify "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_failure
+  assert_output --partial "disappeared: commit"
+  refute_line --regexp "^appeared: commit"
+}
+
+@test "git-spend snapshot verify tells new work from history that appeared" {
+  run "${git_spend}" snapshot write "${BATS_TEST_TMPDIR}/snapshot.json" --since HEAD~2
+  assert_success
+  run grep "HEAD~2" "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_failure
+  # New work, even backdated, is not a drift
+  git -c user.name=Goutte -c user.email=antoine@goutenoir.com \
+    commit --quiet --allow-empty --date="2001-01-01" -m $'backdated\n\n/spend 9h'
+  run "${git_spend}" snapshot verify "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_success
+  # Time added to a commit of the snapshot, like in a note, is a drift
+  git -c user.name=Goutte -c user.email=antoine@goutenoir.com notes add --force -m "/spend 3h" HEAD~1
+  run "${git_spend}" snapshot verify "${BATS_TEST_TMPDIR}/snapshot.json"
+  assert_failure
+}
+
+@test "git-spend snapshot write ignores the diff below the scissors of core.commentChar" {
+  git init --quiet "${BATS_TEST_TMPDIR}/commented"
+  cd "${BATS_TEST_TMPDIR}/commented"
+  git config core.commentChar ';'
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty --cleanup=verbatim \
+    -m $'feat: a\n\n/spend 1h\n; ------------------------ >8 ------------------------\n/spend 2h'
+  run "${git_spend}" snapshot write "${BATS_TEST_TMPDIR}/commented.json"
+  assert_success
+  run grep '"minutes": 180' "${BATS_TEST_TMPDIR}/commented.json"
+  assert_failure
+  run grep '"minutes": 60' "${BATS_TEST_TMPDIR}/commented.json"
+  assert_success
+}
+
+@test "git-spend sum --compact" {
+  run "${git_spend}" sum --compact
+  assert_success
+  assert_output "1w 3h"
+}
+
+@test "git-spend sum --max-width" {
+  run "${git_spend}" sum --max-width 8
+  assert_success
+  assert_line --index 0 "1 week"
+  assert_line --index 1 "3 hours"
+}
+
+@test "git-spend lint-message hints about a commented out directive" {
+  git config core.commentChar ';'
+  run bash -c "printf 'feat: a\n\n; /spend 30m\n; Please enter the commit message\n' | $git_spend lint-message"
+  assert_success
+  assert_output "hint: you left the /spend line commented out: ; /spend 30m"
+}
+
+@test "git-spend lint-message ignores the diff below the scissors" {
+  run bash -c "printf 'feat: a\n\n/spend 30m\n# ------------------------ >8 ------------------------\n /spend 20m\n' | $git_spend lint-message --min-granularity 15m"
+  assert_success
+}
+
+@test "git-spend sum --log-runs, runs list and runs diff" {
+  run "${git_spend}" sum --log-runs
+  assert_success
+  run "${git_spend}" sum --log-runs --author Goutte --since HEAD~1
+  assert_success
+  run "${git_spend}" runs list
+  assert_success
+  assert_line --index 0 --partial "1 week 3 hours"
+  assert_line --index 1 --partial "sum --log-runs --author Goutte --since HEAD~1"
+  run "${git_spend}" runs diff 1 2
+  assert_success
+  assert_output --partial "total: 1 week 3 hours → 2 hours"
+  assert_output --partial "since:  → HEAD~1"
+}
+
+@test "git-spend wip" {
+  git init --quiet --bare "${BATS_TEST_TMPDIR}/upstream"
+  git clone --quiet "${BATS_TEST_TMPDIR}/upstream" "${BATS_TEST_TMPDIR}/wip" 2>/dev/null
+  cd "${BATS_TEST_TMPDIR}/wip"
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty -m "init"
+  git push --quiet origin HEAD 2>/dev/null
+  run "${git_spend}" wip
+  assert_success
+  assert_output "No uncommitted, stashed or unpushed time spent."
+
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty -m $'feat\n\n/spend 2h'
+  echo "draft" > draft.txt && git add draft.txt
+  git -c user.name=Alice -c user.email=alice@example.com stash push --quiet -m $'wip\n\n/spend 30m'
+  run "${git_spend}" wip
+  assert_success
+  assert_output --partial "stashed: stash@{0} spends 30 minutes"
+  assert_output --partial "spends 2 hours in 1 commit not on origin/"
+
+  echo "more" > more.txt && git add more.txt
+  git -c user.name=Alice -c user.email=alice@example.com stash push --quiet -m "/spend 1h"
+  run "${git_spend}" wip
+  assert_success
+  assert_output --partial "stashed: stash@{0} spends 1 hour"
+
+  git checkout --quiet -b local
+  git -c user.name=Alice -c user.email=alice@example.com commit --quiet --allow-empty -m $'local\n\n/spend 3h'
+  run "${git_spend}" wip
+  assert_success
+  assert_output --partial "unpushed: branch local spends 5 hours in 2 commits on no remote branch"
+}
+
+@test "git-spend lint-message --policy single-directive" {
+  run bash -c "printf 'feat: a\n\nbody\n/spend 30m\n' | $git_spend lint-message --policy single-directive"
+  assert_success
+  run bash -c "printf 'feat: a\n\n/spend 30m\n/spend 1h\n' | $git_spend lint-message --policy single-directive"
+  assert_failure
+  assert_output --partial "[GT002]"
+  run bash -c "printf 'feat: a\n\n/spend 30m\nbody\n' | $git_spend lint-message --policy single-directive"
+  assert_failure
+  assert_output --partial "[GT003]"
+}
+
+@test "git-spend sum --check-policy" {
+  run "${git_spend}" sum --policy single-directive
+  assert_success
+  refute_output --partial "GT00"
+  export GIT_SPEND_POLICY=single-directive
+  run "${git_spend}" sum --check-policy
+  assert_success
+  assert_output --partial "1 week 3 hours"
+}
+
+@test "git-spend sum --group-by author states the resolved range in its header" {
+  run "${git_spend}" sum --group-by author --since 786a3064
+  assert_success
+  assert_line --regexp "^.+ at 786a3064 \(786a306[0-9a-f]*\)\.\.HEAD \([0-9a-f]+\) · from the beginning to now · [0-9.]+ hours/day, [0-9.]+ days/week · git-spend .+$"
+}
+
+@test "git-spend sum --group-by author states the resolved dates in its header" {
+  run "${git_spend}" sum --group-by author --since 2023-01-01 --until 2023-12-31
+  assert_success
+  assert_output --partial "from 2023-01-01T00:00:00+01:00 to 2023-12-31T00:00:00+01:00"
+}
+
+@test "git-spend sum --verbose states the schedule in its header" {
+  export GIT_SPEND_HOURS_IN_ONE_DAY=7
+  run "${git_spend}" sum --verbose
+  assert_success
+  assert_output --partial "7 hours/day, 5 days/week"
+}
+
+@test "git-spend sum --group-by author --no-header" {
+  run "${git_spend}" sum --group-by author --no-header
+  assert_success
+  assert_line --index 0 --regexp "^author +time spent$"
+}
+
+@test "git-spend sum --format json has meta" {
+  run "${git_spend}" sum --format json --since 2023-01-01
+  assert_success
+  assert_output --partial '"meta": {'
+  assert_output --partial '"since": "2023-01-01T00:00:00+01:00"'
+  assert_output --partial '"hours_per_day": '
+}
+
+@test "git-spend sum --stdin-commits" {
+  run bash -c "git log --format=%H | $git_spend sum --stdin-commits"
+  assert_success
+  assert_output --partial "unknown"
+  assert_line "1 week 3 hours"
+}
+
+@test "git-spend sum --stdin-commits fails on unknown commits" {
+  run bash -c "echo 0000000deadbeef | $git_spend sum --stdin-commits"
+  assert_failure
+  assert_output --partial "unknown commit: 0000000deadbeef"
+}
+
+@test "git-spend sum --stdin-commits --ignore-missing" {
+  run bash -c "(git log --format=%H; echo 0000000deadbeef) | $git_spend sum --stdin-commits --ignore-missing"
+  assert_success
+  assert_line "1 week 3 hours"
+}
+
+@test "git-spend sum --stdin-commits --expect" {
+  run bash -c "git log -3 --format=%h | $git_spend sum --stdin-commits --expect 2"
+  assert_failure
+  assert_output --partial "Expected 2 commits, but 3 resolved."
+}
+
+@test "git-spend sum applies the corrections file" {
+  git init --quiet "${BATS_TEST_TMPDIR}/fix"
+  git -C "${BATS_TEST_TMPDIR}/fix" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: work\n\n/spend 3d'
+  hash=$(git -C "${BATS_TEST_TMPDIR}/fix" rev-parse --short HEAD)
+  printf '%s: 1h\n0000000: +1h\n' "${hash}" > "${BATS_TEST_TMPDIR}/fix/.git-spend-corrections.yaml"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/fix" --verbose
+  assert_success
+  assert_output --partial "corrected ${hash}: 3 days → 1 hour"
+  assert_output --partial "warning[GT008]: there is a correction of the commit 0000000"
+  assert_line "1 hour"
+
+  printf 'a: 0\n' > "${BATS_TEST_TMPDIR}/fix/.git-spend-corrections.yaml"
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/fix"
+  assert_failure
+  assert_output --partial "needs at least 7 hexadecimal characters"
+}
+
+@test "git-spend sum applies the corrections of the repository config" {
+  git init --quiet "${BATS_TEST_TMPDIR}/fix"
+  git -C "${BATS_TEST_TMPDIR}/fix" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: work\n\n/spend 3h'
+  hash=$(git -C "${BATS_TEST_TMPDIR}/fix" rev-parse HEAD)
+  printf 'corrections:\n  %s: "-1h"\n' "${hash}" > "${BATS_TEST_TMPDIR}/fix/.git-spend.yaml"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/fix" --minutes
+  assert_success
+  assert_output "120"
+}
+
+@test "git-spend sum --target <dir> --target <dir> --group-by repo" {
+  for repo in api front ; do
+    git init --quiet "${BATS_TEST_TMPDIR}/${repo}"
+    git -C "${BATS_TEST_TMPDIR}/${repo}" -c user.name=Alice -c user.email=alice@example.com \
+      commit --quiet --allow-empty -m $'feat: work\n\n/spend 1h'
+  done
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/api" --target "${BATS_TEST_TMPDIR}/front" --group-by repo --no-header
+  assert_success
+  assert_line --regexp "^api +1 hour$"
+  assert_line --regexp "^front +1 hour$"
+  assert_line --regexp "^total +2 hours$"
+}
+
+@test "git-spend sum --group-by repo of repositories of the same name" {
+  for repo in a/api b/api ; do
+    git init --quiet "${BATS_TEST_TMPDIR}/${repo}"
+    git -C "${BATS_TEST_TMPDIR}/${repo}" -c user.name=Alice -c user.email=alice@example.com \
+      commit --quiet --allow-empty -m $'feat: work\n\n/spend 1h'
+  done
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/a/api" --target "${BATS_TEST_TMPDIR}/b/api" --group-by repo --no-header
+  assert_success
+  assert_line --regexp "/a/api +1 hour$"
+  assert_line --regexp "/b/api +1 hour$"
+  assert_line --regexp "^total +2 hours$"
+}
+
+@test "git-spend fmt --minutes" {
+  run "${git_spend}" fmt --minutes 2550
+  assert_success
+  assert_output "1 week 2 hours 30 minutes"
+}
+
+@test "git-spend fmt --seconds --compact" {
+  run "${git_spend}" fmt --seconds 5400 --compact
+  assert_success
+  assert_output "1h 30m"
+}
+
+@test "git-spend fmt reads minutes from stdin" {
+  run bash -c "echo 2550 | ${git_spend} fmt"
+  assert_success
+  assert_output "1 week 2 hours 30 minutes"
+}
+
+@test "git-spend fmt --parse --to minutes" {
+  run "${git_spend}" fmt --parse '2h 30m' --to minutes
+  assert_success
+  assert_output "150"
+}
+
+@test "git-spend fmt --parse <wrong> should fail" {
+  run "${git_spend}" fmt --parse 'a while'
+  assert_failure
+}
+
+@test "git-spend fmt rejects durations that cannot be counted" {
+  for minutes in NaN Inf -Inf -1 1e30 ; do
+    run bash -c "echo ${minutes} | ${git_spend} fmt"
+    assert_failure
+  done
+  run "${git_spend}" fmt --minutes 1e30
+  assert_failure
+  assert_output --partial "Durations cannot be longer than"
+  run "${git_spend}" fmt --parse '99999999999999999999999h'
+  assert_failure
+}
+
+@test "git-spend sum without git in the PATH" {
+  run env PATH="${BATS_TEST_TMPDIR}" "${git_spend}" sum
+  assert_failure 69
+  assert_output --partial "which was not found in the PATH"
+}
+
+@test "git-spend sum --stdin without git in the PATH" {
+  run bash -c "echo '/spend 1h' | env PATH='${BATS_TEST_TMPDIR}' ${git_spend} sum --stdin"
+  assert_success
+  assert_output "1 hour"
+}
+
+@test "git-spend sum --counts" {
+  git init --quiet "${BATS_TEST_TMPDIR}/counts"
+  git -C "${BATS_TEST_TMPDIR}/counts" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: one\n\n/spend 1h'
+  git -C "${BATS_TEST_TMPDIR}/counts" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: two\n\n/spend a while'
+  git -C "${BATS_TEST_TMPDIR}/counts" -c user.name=Bob -c user.email=bob@example.com \
+    commit --quiet --allow-empty -m $'feat: three\n\n/spend 2h'
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/counts" --author Alice --counts
+  assert_success
+  assert_line "scanned 3 commits: 1 matched, 1 (other authors: 1) skipped, 1 unparsable directives"
+  assert_line "1 hour"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/counts" --format json
+  assert_success
+  assert_output --partial '"scanned": 3'
+  assert_output --partial '"matched": 2'
+
+  run "${git_spend}" log --target "${BATS_TEST_TMPDIR}/counts" --author Alice --counts
+  assert_success
+  assert_line "scanned 3 commits: 1 matched, 1 (other authors: 1) skipped, 1 unparsable directives"
+
+  run "${git_spend}" export --sessions --target "${BATS_TEST_TMPDIR}/counts" --author Alice --counts
+  assert_success
+  assert_line "scanned 3 commits: 1 matched, 1 (other authors: 1) skipped, 1 unparsable directives"
+
+  run "${git_spend}" export --sessions --target "${BATS_TEST_TMPDIR}/counts" --format json
+  assert_success
+  assert_output --partial '"scanned": 3'
+}
+
+@test "git-spend sum --log-runs from concurrent invocations" {
+  git init --quiet "${BATS_TEST_TMPDIR}/concurrent"
+  git -C "${BATS_TEST_TMPDIR}/concurrent" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: one\n\n/spend 1h'
+
+  for i in 1 2 3 4 5 6 7 8; do
+    "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/concurrent" --log-runs > /dev/null &
+  done
+  wait
+
+  run "${git_spend}" runs list --target "${BATS_TEST_TMPDIR}/concurrent"
+  assert_success
+  assert_equal "${#lines[@]}" 8
+}
+
+@test "git-spend sum --word-numbers" {
+  git init --quiet "${BATS_TEST_TMPDIR}/words"
+  git -C "${BATS_TEST_TMPDIR}/words" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: one\n\n/spend an hour and a half'
+  git -C "${BATS_TEST_TMPDIR}/words" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: two\n\n/spend half a day'
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/words"
+  assert_success
+  refute_output "5 hours 30 minutes"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/words" --word-numbers
+  assert_success
+  assert_output "5 hours 30 minutes"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/words" --word-numbers=fr
+  assert_failure
+}
+
+@test "git-spend sum --range-policy" {
+  git init --quiet "${BATS_TEST_TMPDIR}/ranges"
+  git -C "${BATS_TEST_TMPDIR}/ranges" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: hedging\n\n/spend 1-2h'
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/ranges"
+  assert_success
+  assert_output "1 hour 30 minutes"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/ranges" --range-policy max --verbose
+  assert_success
+  assert_line "2 hours"
+  assert_line --partial "1h-2h → 2 hours (max)"
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/ranges" --check-policy --policy no-range --strict
+  assert_failure
+  assert_output --partial "[GT004]"
+}
+
+@test "git-spend with ambiguous_m" {
+  git init --quiet "${BATS_TEST_TMPDIR}/ambiguous"
+  git -C "${BATS_TEST_TMPDIR}/ambiguous" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: months\n\n/spend 1m'
+
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/ambiguous"
+  assert_success
+  assert_output "1 minute"
+
+  export GIT_SPEND_AMBIGUOUS_M=months
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/ambiguous"
+  assert_success
+  assert_output "1 month"
+
+  run "${git_spend}" show HEAD --target "${BATS_TEST_TMPDIR}/ambiguous" --explain
+  assert_success
+  assert_output --partial "the unit m was read as months (ambiguous_m: months)"
+
+  export GIT_SPEND_AMBIGUOUS_M=reject
+  run "${git_spend}" sum --target "${BATS_TEST_TMPDIR}/ambiguous" --strict
+  assert_failure
+  assert_output --partial "[GT005]"
+
+  run "${git_spend}" lint-message <<< "/spend 1min"
+  assert_success
+}
+
+@test "git-spend sum --deny --allow" {
+  run "${git_spend}" diagnostics list
+  assert_success
+  assert_line --partial "GT006"
+
+  run "${git_spend}" sum --max-directive 1m --deny GT006
+  assert_failure
+  assert_output --partial "error[GT006]: commit"
+
+  run "${git_spend}" sum --max-directive 1m --allow GT006
+  assert_success
+  refute_output --partial "GT006"
+
+  run "${git_spend}" sum --max-directive 1m --format json
+  assert_success
+  assert_output --partial '"diagnostics": ['
+  assert_output --partial '"severity": "warning"'
+
+  run "${git_spend}" sum --deny GT999
+  assert_failure
+
+  run "${git_spend}" sum --max-directive 1m --enforce-max --allow GT006 --deny GT016
+  assert_failure
+  assert_output --partial "error[GT016]: excluded from the total"
+
+  run bash -c "printf 'feat: a\n\n/spend 20m\n' | $git_spend lint-message --min-granularity 15m"
+  assert_failure
+  assert_output --partial "error[GT001]"
+  run bash -c "printf 'feat: a\n\n/spend 20m\n' | $git_spend lint-message --min-granularity 15m --allow GT001"
+  assert_success
+  refute_output --partial "GT001"
+
+  run "${git_spend}" export --sessions --deny GT999
+  assert_failure
+}
+
+@test "git-spend export --sessions" {
+  git init --quiet "${BATS_TEST_TMPDIR}/sessions"
+  GIT_COMMITTER_DATE="2024-03-25T10:00:00+00:00" GIT_AUTHOR_DATE="2024-03-25T10:00:00+00:00" \
+    git -C "${BATS_TEST_TMPDIR}/sessions" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: first\n\n/spend 30m'
+  GIT_COMMITTER_DATE="2024-03-25T11:00:00+00:00" GIT_AUTHOR_DATE="2024-03-25T11:00:00+00:00" \
+    git -C "${BATS_TEST_TMPDIR}/sessions" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: second\n\n/spend 2h'
+
+  run "${git_spend}" export --sessions --target "${BATS_TEST_TMPDIR}/sessions"
+  assert_success
+  assert_line --index 0 --partial "synthetic"
+  assert_line "Alice,2024-03-25,2024-03-25T08:30:00Z,2024-03-25T09:00:00Z,30,$(git -C "${BATS_TEST_TMPDIR}/sessions" rev-parse HEAD~1),feat: first,false"
+
+  run "${git_spend}" export --sessions --target "${BATS_TEST_TMPDIR}/sessions" --format json
+  assert_success
+  assert_output --partial '"synthetic": true'
+
+  run "${git_spend}" export --target "${BATS_TEST_TMPDIR}/sessions"
+  assert_failure
+}
+
+@test "git-spend sum of commits in ISO-8859-1 and Shift-JIS" {
+  repo="${BATS_TEST_TMPDIR}/encodings"
+  git init --quiet "${repo}"
+  printf 'feat: caf\xe9\n\n/spend 1h caf\xe9 cr\xe8me\n' > "${BATS_TEST_TMPDIR}/latin1.txt"
+  git -C "${repo}" -c i18n.commitEncoding=ISO-8859-1 -c user.name="$(printf 'Ren\xe9')" -c user.email=rene@example.com \
+    commit --quiet --allow-empty -F "${BATS_TEST_TMPDIR}/latin1.txt"
+  printf 'feat: \x93\xfa\x96\x7b\n\n/spend 30m \x93\xfa\x96\x7b\x8c\xea\n' > "${BATS_TEST_TMPDIR}/sjis.txt"
+  git -C "${repo}" -c i18n.commitEncoding=Shift_JIS -c user.name="$(printf '\x8e\x52\x93\x63')" -c user.email=yamada@example.com \
+    commit --quiet --allow-empty -F "${BATS_TEST_TMPDIR}/sjis.txt"
+  # A legacy commit in ISO-8859-1 that does not declare its encoding, which git cannot re-encode
+  legacy=$(printf 'tree %s\nparent %s\nauthor Fran\xe7ois <francois@example.com> 1711360800 +0000\ncommitter Fran\xe7ois <francois@example.com> 1711360800 +0000\n\nfeat: r\xe9sum\xe9\n\n/spend 45m r\xe9sum\xe9\n' \
+    "$(git -C "${repo}" rev-parse HEAD^{tree})" "$(git -C "${repo}" rev-parse HEAD)" | git -C "${repo}" hash-object -t commit -w --stdin)
+  git -C "${repo}" update-ref HEAD "${legacy}"
+
+  run "${git_spend}" sum --target "${repo}" --group-by author --no-header
+  assert_success
+  assert_line --regexp "^René +1 hour$"
+  assert_line --regexp "^山田 +30 minutes$"
+  assert_line --regexp "^Fran�ois +45 minutes$"
+  assert_output --partial "warning[GT014]: commit ${legacy:0:7} is not valid UTF-8"
+
+  run "${git_spend}" show HEAD~1 --target "${repo}"
+  assert_success
+  assert_output --partial "note: 日本語"
+}
+
+@test "git-spend doctor" {
+  repository="${BATS_TEST_TMPDIR}/doctor"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: typo\n\n/spned 1h'
+  run "${git_spend}" doctor --target "${repository}"
+  assert_failure 1
+  assert_line --partial "pass  git: git "
+  assert_line --partial "warn  recent commits: 1 suspicious directives in the last 1 commits"
+  printf '#!/bin/sh\ngit spend lint-message "$1"\n' > "${repository}/.git/hooks/commit-msg"
+  run "${git_spend}" doctor --target "${repository}"
+  assert_failure 2
+  assert_line --partial "is not executable, so git skips it"
+}
+
+@test "git-spend sum --team-file" {
+  repository="${BATS_TEST_TMPDIR}/team"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: one\n\n/spend 1h'
+  git -C "${repository}" -c user.name="Alice D" -c user.email=alice@old.example.com \
+    commit --quiet --allow-empty -m $'feat: two\n\n/spend 2h'
+  git -C "${repository}" -c user.name=Carl -c user.email=carl@example.com \
+    commit --quiet --allow-empty -m $'feat: three\n\n/spend 4h'
+  echo "Alice <alice@example.com> <alice@old.example.com>" > "${repository}/.mailmap"
+  printf '# Team\n- alice@example.com\n' > "${BATS_TEST_TMPDIR}/team.txt"
+  run "${git_spend}" sum --target "${repository}" --team-file "${BATS_TEST_TMPDIR}/team.txt"
+  assert_success
+  assert_output "3 hours"
+  run "${git_spend}" sum --target "${repository}" --team-file "${BATS_TEST_TMPDIR}/team.txt" --not-in-team
+  assert_success
+  assert_output "4 hours"
+  run "${git_spend}" sum --target "${repository}" --team-file "${BATS_TEST_TMPDIR}/team.txt" --print-team
+  assert_success
+  assert_output "alice@example.com: alice@example.com, Alice, alice@old.example.com"
+}
+
+@test "git-spend annotate" {
+  repository="${BATS_TEST_TMPDIR}/annotate"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: one\n\n/spend 2h\n/spend 30m'
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'chore: nothing'
+  run "${git_spend}" annotate --target "${repository}" --dry-run
+  assert_success
+  assert_output --partial "would annotate"
+  run git -C "${repository}" notes --ref git-spend list
+  assert_output ""
+  run "${git_spend}" annotate --target "${repository}"
+  assert_success
+  assert_output --partial "1 notes written in refs/notes/git-spend, 0 already up to date."
+  run git -C "${repository}" notes --ref git-spend show HEAD~1
+  assert_line --index 1 "minutes: 150"
+  assert_line "directive: /spend 30m"
+  run "${git_spend}" annotate --target "${repository}"
+  assert_output --partial "0 notes written in refs/notes/git-spend, 1 already up to date."
+  run "${git_spend}" annotate --target "${repository}" --remove
+  assert_output --partial "1 notes removed from refs/notes/git-spend."
+}
+
+@test "git-spend log --format gitlab-notes" {
+  repository="${BATS_TEST_TMPDIR}/log"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty --date=2024-03-04T10:00:00 -m $'feat: one\n\n/spend 1.5h\n/spend 2d 2024-03-01'
+  run "${git_spend}" log --target "${repository}" --format gitlab-notes
+  assert_success
+  assert_line --index 0 "added 1h 30m of time spent at 2024-03-04"
+  assert_line --index 1 "added 2d of time spent at 2024-03-01"
+}
+
+@test "git-spend export --chunk resumes with --after" {
+  repository="${BATS_TEST_TMPDIR}/chunk"
+  git init --quiet "${repository}"
+  for day in 1 2 3 4 5; do
+    git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+      commit --quiet --allow-empty --date="2024-03-0${day}T1${day}:00:00" -m $"feat: ${day}"$'\n\n'"/spend ${day}h"
+  done
+  "${git_spend}" export --sessions --target "${repository}" > "${BATS_TEST_TMPDIR}/full.csv"
+  # Interrupted after the first chunk: two lines of headers and two sessions
+  "${git_spend}" export --sessions --target "${repository}" --chunk 2 \
+    2> "${BATS_TEST_TMPDIR}/cursors" | head -n 4 > "${BATS_TEST_TMPDIR}/resumed.csv"
+  cursor=$(head -n 1 "${BATS_TEST_TMPDIR}/cursors" | awk '{ print $NF }')
+  run "${git_spend}" export --sessions --target "${repository}" --chunk 2 --after "${cursor}"
+  assert_success
+  "${git_spend}" export --sessions --target "${repository}" --chunk 2 --after "${cursor}" \
+    2> /dev/null >> "${BATS_TEST_TMPDIR}/resumed.csv"
+  run cmp "${BATS_TEST_TMPDIR}/full.csv" "${BATS_TEST_TMPDIR}/resumed.csv"
+  assert_success
+  run "${git_spend}" export --sessions --target "${repository}" --after "${cursor:0:7}" --format json
+  assert_failure
+}
+
+@test "git-spend works from an empty directory, with its embedded assets" {
+  mkdir -p "${BATS_TEST_TMPDIR}/bin" "${BATS_TEST_TMPDIR}/empty"
+  cp "${git_spend}" "${BATS_TEST_TMPDIR}/bin/git-spend"
+  repository="${TMP_FIXTURE_DIR}"
+  cd "${BATS_TEST_TMPDIR}/empty" || exit
+  run env -i PATH="${PATH}" HOME="${BATS_TEST_TMPDIR}/empty" "${BATS_TEST_TMPDIR}/bin/git-spend" assets list
+  assert_success
+  assert_line --partial "locale/strings.fr.toml"
+  assert_line --partial "gitime/badge/badge.svg.tmpl"
+  run env -i PATH="${PATH}" HOME="${BATS_TEST_TMPDIR}/empty" LANGUAGE=fr "${BATS_TEST_TMPDIR}/bin/git-spend"
+  assert_success
+  assert_output --partial 'Gérer les directives /spend inscrites dans les messages de commit'
+  run env -i PATH="${PATH}" HOME="${BATS_TEST_TMPDIR}/empty" "${BATS_TEST_TMPDIR}/bin/git-spend" \
+    badge --target "${repository}" --window '100 years'
+  assert_success
+  assert_output --partial '<svg xmlns="http://www.w3.org/2000/svg"'
+}
+
+@test "git-spend log --order" {
+  repository="${BATS_TEST_TMPDIR}/order"
+  git init --quiet "${repository}"
+  in_repository() {
+    git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com "$@"
+  }
+  in_repository commit --quiet --allow-empty --date=2024-03-01T10:00:00 -m "chore: init"
+  in_repository checkout --quiet -b side
+  in_repository commit --quiet --allow-empty --date=2024-03-05T10:00:00 -m $'feat: two\n\n/spend 2h'
+  in_repository checkout --quiet -
+  GIT_COMMITTER_DATE=2030-01-01T10:00:00 \
+    in_repository commit --quiet --allow-empty --date=2024-03-04T10:00:00 -m $'feat: one\n\n/spend 1h'
+  in_repository merge --quiet --no-ff -m "merge side" side
+  run "${git_spend}" log --target "${repository}" --order commit-date
+  assert_success
+  assert_line --index 0 --partial "1 hour"
+  run "${git_spend}" log --target "${repository}"
+  assert_success
+  assert_line --index 0 --partial "2 hours"
+  run "${git_spend}" log --target "${repository}" --order newest
+  assert_failure
+  assert_output --partial "unsupported order newest"
+}
+
+@test "git-spend sum --flag-weekend-spend --exclude-flagged" {
+  repository="${BATS_TEST_TMPDIR}/weekend"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty --date=2024-03-01T10:00:00 -m $'feat: friday\n\n/spend 2h'
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty --date=2024-03-02T10:00:00 -m $'feat: saturday\n\n/spend 3h\n/spend 1h 2024-12-25'
+  echo "2024-12-25: Christmas" > "${BATS_TEST_TMPDIR}/holidays.yml"
+  run "${git_spend}" sum --target "${repository}" --flag-weekend-spend --flag-dates-from "${BATS_TEST_TMPDIR}/holidays.yml"
+  assert_success
+  assert_line --index 0 "6 hours"
+  assert_output --partial "Christmas"
+  assert_output --partial "2 directives flagged, for 4 hours"
+  run "${git_spend}" sum --target "${repository}" --flag-weekend-spend --exclude-flagged --minutes
+  assert_success
+  assert_line --index 0 "180"
+  assert_output --partial "Non-billable"
+  run "${git_spend}" sum --target "${repository}" --exclude-flagged
+  assert_failure
+}
+
+@test "git-spend sum --group-by label" {
+  repository="${BATS_TEST_TMPDIR}/labels"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'fix: pager\n\n/spend 2h [oncall] paged at 3am\n/spend 30m'
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'fix: database\n\n/spend 1h [OnCall]\n/spend 1h [emergency]'
+  run "${git_spend}" sum --target "${repository}" --group-by label --minutes
+  assert_success
+  assert_output --partial "180"
+  assert_output --partial "emergency"
+  printf 'rates:\n  default: 80\n  oncall: 120\n' > "${repository}/.git-spend.yaml"
+  run "${git_spend}" sum --target "${repository}" --minutes
+  assert_success
+  assert_output --partial "GT015"
+}
+
+@test "git-spend daemon --json-rpc" {
+  session="${BATS_TEST_TMPDIR}/session.jsonl"
+  cat > "${session}" <<'EOF'
+{"jsonrpc":"2.0","id":1,"method":"parseMessage","params":{"message":"fix: pager\n\n/spend 1h30 [oncall] paged"}}
+{"jsonrpc":"2.0","id":2,"method":"lintMessage","params":{"message":"/spend 1h\n/spend 2h"}}
+{"jsonrpc":"2.0","id":3,"method":"sumRange","params":{"since":"HEAD~1"}}
+{"jsonrpc":"2.0","id":4,"method":"sumRange","params":{"since":"no-such-ref"}}
+this is not json
+{"jsonrpc":"2.0","id":5,"method":"lintMessage"}
+{"jsonrpc":"2.0","id":6,"method":"shutdown"}
+{"jsonrpc":"2.0","id":7,"method":"parseMessage","params":{"message":"too late"}}
+EOF
+  run bash -c "${git_spend} daemon --json-rpc --policy single-directive < ${session}"
+  assert_success
+  assert_line --index 0 --partial '"id":1,"result":{"directives":[{"line":"/spend 1h30 [oncall] paged"'
+  assert_line --index 0 --partial '"label":"oncall"'
+  assert_line --index 1 --partial '"id":2,"result":{"diagnostics":[{"code":"GT002"'
+  assert_line --index 2 --partial '"id":3,"result":{"meta":'
+  assert_line --index 2 --partial '"total":{"minutes":120'
+  assert_line --index 3 --partial '"id":4,"error":{"code":-32000'
+  assert_line --index 4 --partial '"id":null,"error":{"code":-32700'
+  assert_line --index 5 --partial '"id":5,"error":{"code":-32602'
+  assert_line --index 6 '{"jsonrpc":"2.0","id":6,"result":null}'
+  assert_equal "${#lines[@]}" 7
+}
+
+@test "git-spend identities migrate" {
+  repository="${BATS_TEST_TMPDIR}/identities"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name="J. Doe" -c user.email=jd@oldcorp.com \
+    commit --quiet --allow-empty -m $'feat: a\n\n/spend 2h'
+  git -C "${repository}" -c user.name="Bob" -c user.email=bob@oldcorp.com \
+    commit --quiet --allow-empty -m $'feat: b\n\n/spend 1h'
+  echo "Jane Doe <jane.doe@newcorp.com> J. Doe <jd@oldcorp.com>" > "${repository}/.mailmap"
+  run "${git_spend}" snapshot write --target "${repository}" "${BATS_TEST_TMPDIR}/state/march.json"
+  assert_success
+  run "${git_spend}" sum --target "${repository}" --author alice@oldcorp.com --log-runs
+  assert_success
+  cp "${repository}/.git/git-spend-runs.jsonl" "${BATS_TEST_TMPDIR}/state/"
+  run "${git_spend}" identities migrate --target "${repository}" --from @oldcorp.com --to @newcorp.com \
+    --state-dir "${BATS_TEST_TMPDIR}/state" --dry-run
+  assert_success
+  assert_output --partial "- alice@oldcorp.com"
+  assert_output --partial "+ Jane Doe"
+  assert_output --partial "+ jane.doe@newcorp.com"
+  assert_output --partial "+ bob@newcorp.com"
+  assert_output --partial "4 records would be rewritten, in 2 files"
+  run grep "Jane Doe" "${BATS_TEST_TMPDIR}/state/march.json"
+  assert_failure
+  run "${git_spend}" identities migrate --target "${repository}" --from @oldcorp.com --to @newcorp.com \
+    --state-dir "${BATS_TEST_TMPDIR}/state"
+  assert_success
+  assert_output --partial "4 records rewritten, in 2 files"
+  run grep "alice@newcorp.com" "${BATS_TEST_TMPDIR}/state/git-spend-runs.jsonl"
+  assert_success
+  run grep "bob@newcorp.com" "${BATS_TEST_TMPDIR}/state/march.json"
+  assert_success
+  run "${git_spend}" snapshot verify --target "${repository}" "${BATS_TEST_TMPDIR}/state/march.json"
+  assert_success
+}
+
+@test "git-spend sum --show-raw" {
+  repository="${BATS_TEST_TMPDIR}/overflow"
+  git init --quiet "${repository}"
+  git -C "${repository}" -c user.name=Alice -c user.email=alice@example.com \
+    commit --quiet --allow-empty -m $'feat: minutes\n\n/spend 600m\n/spend 1747m'
+  run "${git_spend}" sum --target "${repository}"
+  assert_success
+  assert_output "4 days 7 hours 7 minutes"
+  run "${git_spend}" sum --target "${repository}" --show-raw
+  assert_success
+  assert_output "2347 minutes"
+  run "${git_spend}" log --target "${repository}"
+  assert_success
+  assert_line --index 0 --partial "600 minutes"
+  assert_line --index 1 --partial "3 days 5 hours 7 minutes"
+}
+
 @test "Support for GIT_SPEND_MINUTES_IN_ONE_HOUR" {
   export GIT_SPEND_MINUTES_IN_ONE_HOUR=10
   run "${git_spend}" sum --since HEAD~1 --minutes